@@ -9,12 +9,14 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/fatih/color"
 	"github.com/hashicorp/logutils"
 	flags "github.com/jessevdk/go-flags"
 	"github.com/terraform-linters/tflint/formatter"
+	"github.com/terraform-linters/tflint/plugin"
 	"github.com/terraform-linters/tflint/terraform"
 	"github.com/terraform-linters/tflint/tflint"
 )
@@ -38,6 +40,17 @@ type CLI struct {
 	config    *tflint.Config
 	loader    *terraform.Loader
 	formatter *formatter.Formatter
+
+	// plugins launched for a module, which may be reused for subsequent modules.
+	// pluginKey identifies the plugins and the config applied to them.
+	plugin           *plugin.Plugin
+	pluginKey        string
+	pluginMu         sync.Mutex
+	shutdownHandlers sync.Once
+
+	// policies compiled for each policy directory, which are reused for subsequent modules.
+	// Directories are identified by their absolute paths.
+	policies map[string]*tflint.Policies
 }
 
 // NewCLI returns new CLI initialized by input streams
@@ -56,7 +69,7 @@ func NewCLI(outStream io.Writer, errStream io.Writer) (*CLI, error) {
 func (cli *CLI) Run(args []string) int {
 	var opts Options
 	parser := flags.NewParser(&opts, flags.HelpFlag)
-	parser.Usage = "--chdir=DIR/--recursive [OPTIONS]"
+	parser.Usage = "--chdir=DIR/--recursive [OPTIONS]\n  " + pluginsUsage
 	parser.UnknownOptionHandler = unknownOptionHandler
 	// Parse commandline flag
 	args, err := parser.ParseArgs(args)
@@ -92,6 +105,13 @@ func (cli *CLI) Run(args []string) int {
 		cli.formatter.Print(tflint.Issues{}, fmt.Errorf("Failed to parse CLI options; %w", err), map[string][]byte{})
 		return ExitCodeError
 	}
+	if len(args) > 1 && args[1] == "plugins" {
+		return cli.plugins(opts, args[2:])
+	}
+	if opts.DryRun || opts.AllowSharedPluginDir {
+		cli.formatter.Print(tflint.Issues{}, fmt.Errorf("Cannot use --dry-run or --allow-shared-plugin-dir without tflint plugins remove"), map[string][]byte{})
+		return ExitCodeError
+	}
 	if len(args) > 1 {
 		cli.formatter.Print(tflint.Issues{}, fmt.Errorf("Command line arguments support was dropped in v0.47. Use --chdir or --filter instead."), map[string][]byte{})
 		return ExitCodeError
@@ -100,6 +120,23 @@ func (cli *CLI) Run(args []string) int {
 		cli.formatter.Print(tflint.Issues{}, fmt.Errorf("Max workers should be greater than 0"), map[string][]byte{})
 		return ExitCodeError
 	}
+	if opts.Upgrade && !opts.Init {
+		cli.formatter.Print(tflint.Issues{}, fmt.Errorf("Cannot use --upgrade without --init"), map[string][]byte{})
+		return ExitCodeError
+	}
+	if opts.Listen != "" && !opts.Langserver {
+		cli.formatter.Print(tflint.Issues{}, fmt.Errorf("Cannot use --listen without --langserver"), map[string][]byte{})
+		return ExitCodeError
+	}
+	if opts.PluginLogDir != "" {
+		// Resolve the directory before changing the working directory
+		dir, err := filepath.Abs(opts.PluginLogDir)
+		if err != nil {
+			cli.formatter.Print(tflint.Issues{}, fmt.Errorf("Failed to resolve plugin log directory; %w", err), map[string][]byte{})
+			return ExitCodeError
+		}
+		opts.PluginLogDir = dir
+	}
 
 	switch {
 	case opts.Version:
@@ -110,6 +147,8 @@ func (cli *CLI) Run(args []string) int {
 		return cli.startLanguageServer(opts)
 	case opts.ActAsBundledPlugin:
 		return cli.actAsBundledPlugin()
+	case opts.ActAsWorker:
+		return cli.actAsWorker(opts)
 	default:
 		if opts.Recursive {
 			return cli.inspectParallel(opts)
@@ -3,6 +3,7 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"log"
 	"os"
 
 	"github.com/fatih/color"
@@ -34,6 +35,11 @@ func (cli *CLI) init(opts Options) int {
 				}
 			}
 
+			lock, err := plugin.LoadLock(cfg)
+			if err != nil {
+				return fmt.Errorf("Failed to load %s; %w", plugin.LockFileName, err)
+			}
+
 			for _, pluginCfg := range cfg.Plugins {
 				installCfg := plugin.NewInstallConfig(cfg, pluginCfg)
 
@@ -42,7 +48,32 @@ func (cli *CLI) init(opts Options) int {
 					continue
 				}
 
-				_, err := plugin.FindPluginPath(installCfg)
+				// Version constraints are resolved to the locked version unless upgrading
+				if opts.Upgrade {
+					if err := installCfg.ResolveVersion(); err != nil {
+						return fmt.Errorf("Failed to upgrade a plugin; %w", err)
+					}
+				} else {
+					lock.Resolve(installCfg)
+				}
+
+				path, err := plugin.FindPluginPath(installCfg)
+				if err == nil {
+					// Reinstall the plugin if the installed binary cannot be verified by the lock file,
+					// so that the checksums of the verified release are recorded.
+					if _, locked := lock.Plugins[pluginCfg.Name]; !locked {
+						err = os.ErrNotExist
+					} else if verifyErr := lock.Verify(installCfg, path); verifyErr != nil {
+						log.Printf("[DEBUG] Reinstall the plugin; %s", verifyErr)
+						err = os.ErrNotExist
+					} else if cfg.PluginVerification == tflint.PluginVerificationRequired {
+						// Reinstall the plugin to verify it, for example if a signing key was configured after installation
+						if verifyErr := lock.RequireVerified(installCfg); verifyErr != nil {
+							log.Printf("[DEBUG] Reinstall the plugin; %s", verifyErr)
+							err = os.ErrNotExist
+						}
+					}
+				}
 				if os.IsNotExist(err) {
 					if opts.Recursive {
 						fmt.Fprintf(cli.outStream, "Installing \"%s\" plugin in %s...\n", pluginCfg.Name, wd)
@@ -50,7 +81,7 @@ func (cli *CLI) init(opts Options) int {
 						fmt.Fprintf(cli.outStream, "Installing \"%s\" plugin...\n", pluginCfg.Name)
 					}
 
-					_, err = installCfg.Install()
+					_, err = installCfg.Install(lock)
 					if err != nil {
 						if errors.Is(err, plugin.ErrPluginNotVerified) {
 							_, _ = color.New(color.FgYellow).Fprintln(cli.outStream, `No signing key configured. Set "signing_key" to verify that the release is signed by the plugin developer`)
@@ -61,7 +92,7 @@ func (cli *CLI) init(opts Options) int {
 					}
 
 					installed = true
-					fmt.Fprintf(cli.outStream, "Installed \"%s\" (source: %s, version: %s)\n", pluginCfg.Name, pluginCfg.Source, pluginCfg.Version)
+					fmt.Fprintf(cli.outStream, "Installed \"%s\" (source: %s, version: %s)\n", pluginCfg.Name, pluginCfg.Source, installCfg.ResolvedVersion())
 				}
 
 				if err != nil {
@@ -73,6 +104,11 @@ func (cli *CLI) init(opts Options) int {
 				}
 			}
 
+			lock.Prune(cfg)
+			if err := lock.Write(); err != nil {
+				return fmt.Errorf("Failed to write %s; %w", lock.Path(), err)
+			}
+
 			return nil
 		})
 		if err != nil {
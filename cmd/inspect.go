@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/hcl/v2"
@@ -23,6 +27,11 @@ func (cli *CLI) inspect(opts Options) int {
 	issues := tflint.Issues{}
 	changes := map[string][]byte{}
 
+	// Workers reuse plugins for subsequent directories and clean them up at the end
+	if !opts.ActAsWorker {
+		defer cli.cleanPlugins()
+	}
+
 	err := cli.withinChangedDir(opts.Chdir, func() error {
 		filterFiles := []string{}
 		for _, pattern := range opts.Filter {
@@ -112,18 +121,20 @@ func (cli *CLI) inspectModule(opts Options, dir string, filterFiles []string) (t
 		return issues, changes, err
 	}
 
-	// Launch plugin processes
-	rulesetPlugin, err := launchPlugins(cli.config, opts.Fix)
-	if rulesetPlugin != nil {
-		defer rulesetPlugin.Clean()
-		go cli.registerShutdownHandler(func() {
-			rulesetPlugin.Clean()
-			os.Exit(ExitCodeError)
-		})
+	// Load policies
+	policies, err := cli.loadPolicies(cli.config.PolicyDir)
+	if err != nil {
+		return issues, changes, err
 	}
+
+	// Launch plugin processes
+	rulesetPlugin, err := cli.launchPlugins(opts.Fix)
 	if err != nil {
 		return issues, changes, err
 	}
+	if err := validateRules(cli.config, rulesetPlugin, policies); err != nil {
+		return issues, changes, err
+	}
 
 	// Check preconditions
 	sdkVersions := map[string]*version.Version{}
@@ -183,6 +194,16 @@ By setting TFLINT_LOG=trace, you can confirm the changes made by the autofix and
 			close(ch)
 		}
 
+		// Run custom rules declared in the config file and policies
+		for _, runner := range append(moduleRunners, rootRunner) {
+			if err := runner.RunCustomRules(); err != nil {
+				return issues, changes, err
+			}
+			if err := runner.RunPolicies(policies); err != nil {
+				return issues, changes, err
+			}
+		}
+
 		changesInAttempt := map[string][]byte{}
 		for _, runner := range append(moduleRunners, rootRunner) {
 			for _, issue := range runner.LookupIssues(filterFiles...) {
@@ -254,6 +275,133 @@ func (cli *CLI) setupRunners(opts Options, dir string) (*tflint.Runner, []*tflin
 	return runner, moduleRunners, nil
 }
 
+// launchPlugins launches plugins and applies the config to them.
+// Plugins launched for the previous module are reused if the plugins and the config
+// applied to them are the same. This allows recursive inspection workers to launch
+// plugins only once for many directories.
+func (cli *CLI) launchPlugins(fix bool) (*plugin.Plugin, error) {
+	key, err := pluginCacheKey(cli.config, fix)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to initialize plugins; %w", err)
+	}
+
+	cli.pluginMu.Lock()
+	defer cli.pluginMu.Unlock()
+
+	if cli.plugin != nil && cli.pluginKey == key {
+		log.Print("[DEBUG] Reuse plugins launched for the previous module")
+		return cli.plugin, nil
+	}
+	if cli.plugin != nil {
+		cli.plugin.Clean()
+		cli.plugin = nil
+	}
+
+	rulesetPlugin, err := launchPlugins(cli.config, fix)
+	if err != nil {
+		if rulesetPlugin != nil {
+			rulesetPlugin.Clean()
+		}
+		return nil, err
+	}
+	cli.plugin, cli.pluginKey = rulesetPlugin, key
+
+	cli.shutdownHandlers.Do(func() {
+		go cli.registerShutdownHandler(func() {
+			cli.cleanPlugins()
+			os.Exit(ExitCodeError)
+		})
+	})
+
+	return rulesetPlugin, nil
+}
+
+// loadPolicies loads and compiles the policies in the directory.
+// Policies compiled for the previous modules are reused, so recursive inspection
+// workers compile the same policy directory only once.
+func (cli *CLI) loadPolicies(dir string) (*tflint.Policies, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load policies; %w", err)
+	}
+	if policies, exists := cli.policies[absDir]; exists {
+		log.Print("[DEBUG] Reuse policies compiled for the previous module")
+		return policies, nil
+	}
+
+	policies, err := tflint.LoadPolicies(dir)
+	if err != nil {
+		return nil, err
+	}
+	if cli.policies == nil {
+		cli.policies = map[string]*tflint.Policies{}
+	}
+	cli.policies[absDir] = policies
+	return policies, nil
+}
+
+// cleanPlugins stops plugins launched by launchPlugins.
+func (cli *CLI) cleanPlugins() {
+	cli.pluginMu.Lock()
+	defer cli.pluginMu.Unlock()
+
+	if cli.plugin != nil {
+		cli.plugin.Clean()
+		cli.plugin = nil
+	}
+}
+
+// pluginCacheKey returns a key that identifies plugins and the config applied to them.
+// Config files and plugin binaries can depend on the current directory,
+// so they are identified by their absolute paths.
+func pluginCacheKey(config *tflint.Config, fix bool) (string, error) {
+	h := sha256.New()
+
+	pluginConf := config.ToPluginConfig()
+	pluginConf.Fix = fix
+	if err := json.NewEncoder(h).Encode(pluginConf); err != nil {
+		return "", err
+	}
+
+	sources := config.Sources()
+	for _, path := range slices.Sorted(maps.Keys(sources)) {
+		// Non-existent paths are pseudo files like the bundled plugin config
+		abs := path
+		if _, err := os.Stat(path); err == nil {
+			if abs, err = filepath.Abs(path); err != nil {
+				return "", err
+			}
+		}
+		fmt.Fprintf(h, "%s\x00%s\x00%d\x00", path, abs, len(sources[path]))
+		h.Write(sources[path])
+	}
+
+	lock, err := plugin.LoadLock(config)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range slices.Sorted(maps.Keys(config.Plugins)) {
+		pluginCfg := config.Plugins[name]
+		installCfg := plugin.NewInstallConfig(config, pluginCfg)
+		lock.Resolve(installCfg)
+		path, err := plugin.FindPluginPath(installCfg)
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		if path != "" {
+			if path, err = filepath.Abs(path); err != nil {
+				return "", err
+			}
+		}
+		fmt.Fprintf(h, "%s\x00%t\x00%s\x00", name, pluginCfg.Enabled, path)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func launchPlugins(config *tflint.Config, fix bool) (*plugin.Plugin, error) {
 	// Lookup plugins
 	rulesetPlugin, err := plugin.Discovery(config)
@@ -261,7 +409,6 @@ func launchPlugins(config *tflint.Config, fix bool) (*plugin.Plugin, error) {
 		return nil, fmt.Errorf("Failed to initialize plugins; %w", err)
 	}
 
-	rulesets := []tflint.RuleSet{}
 	pluginConf := config.ToPluginConfig()
 	pluginConf.Fix = fix
 
@@ -299,16 +446,26 @@ func launchPlugins(config *tflint.Config, fix bool) (*plugin.Plugin, error) {
 		if err != nil {
 			return rulesetPlugin, fmt.Errorf(`Failed to apply config to "%s" plugin; %w`, name, err)
 		}
+	}
+
+	return rulesetPlugin, nil
+}
 
+// validateRules validates rule configs against rules provided by plugins and policies.
+// Policies can be different for each module, so this is performed even if plugins are reused.
+func validateRules(config *tflint.Config, rulesetPlugin *plugin.Plugin, policies *tflint.Policies) error {
+	rulesets := []tflint.RuleSet{}
+	for _, ruleset := range rulesetPlugin.RuleSets {
 		rulesets = append(rulesets, ruleset)
 	}
+	if policies != nil {
+		rulesets = append(rulesets, policies)
+	}
 
-	// Validate config for plugins
 	if err := config.ValidateRules(rulesets...); err != nil {
-		return rulesetPlugin, fmt.Errorf("Failed to check rule config; %w", err)
+		return fmt.Errorf("Failed to check rule config; %w", err)
 	}
-
-	return rulesetPlugin, nil
+	return nil
 }
 
 func writeChanges(changes map[string][]byte) error {
@@ -11,6 +11,7 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -104,7 +105,9 @@ func (cli *CLI) inspectParallel(opts Options) int {
 }
 
 // Spawn workers to run in parallel for each directory.
-// A worker is a process that runs itself as a child process.
+// A worker is a process that runs itself as a child process. Each worker inspects
+// directories passed through stdin one by one, so plugins are launched once per worker
+// rather than once per directory.
 // The number of parallelism is controlled by --max-workers flag. The default is the number of CPUs.
 func spawnWorkers(ctx context.Context, workingDirs []string, opts Options) (<-chan worker, error) {
 	self, err := os.Executable()
@@ -118,20 +121,26 @@ func spawnWorkers(ctx context.Context, workingDirs []string, opts Options) (<-ch
 			maxWorkers = c
 		}
 	}
+	maxWorkers = min(maxWorkers, len(workingDirs))
+
+	dirs := make(chan string, len(workingDirs))
+	for _, wd := range workingDirs {
+		dirs <- wd
+	}
+	close(dirs)
 
 	ch := make(chan worker)
-	semaphore := make(chan struct{}, maxWorkers)
 
 	go func() {
 		defer close(ch)
 
 		var wg sync.WaitGroup
-		for _, wd := range workingDirs {
+		for range maxWorkers {
 			wg.Add(1)
-			go func(wd string) {
+			go func() {
 				defer wg.Done()
-				spawnWorker(ctx, self, wd, opts, ch, semaphore)
-			}(wd)
+				runWorker(ctx, self, opts, dirs, ch)
+			}()
 		}
 		wg.Wait()
 	}()
@@ -139,35 +148,121 @@ func spawnWorkers(ctx context.Context, workingDirs []string, opts Options) (<-ch
 	return ch, nil
 }
 
-// Spawn a worker process for the given directory.
-// When the process is complete, send the results to the given channel.
+// Run a worker process and pass directories to it until there are no more.
+// When the inspection of each directory is complete, send the results to the given channel.
+// If the process exits unexpectedly, a new process is started for the remaining directories.
 // If the context is canceled, the started process will be interrupted.
-func spawnWorker(ctx context.Context, executable string, workingDir string, opts Options, ch chan<- worker, semaphore chan struct{}) {
-	// Blocks from exceeding the maximum number of workers
-	select {
-	case semaphore <- struct{}{}:
-		defer func() {
-			<-semaphore
-		}()
-	case <-ctx.Done():
-		log.Printf("[DEBUG] Worker in %s is canceled\n", workingDir)
-		ch <- worker{dir: workingDir, stdout: new(bytes.Buffer), stderr: new(bytes.Buffer), err: ctx.Err()}
-		return
-	}
-
-	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
-	cmd := exec.CommandContext(ctx, executable, opts.toWorkerCommands(workingDir)...)
-	cmd.Stdout, cmd.Stderr = stdout, stderr
+func runWorker(ctx context.Context, executable string, opts Options, dirs <-chan string, ch chan<- worker) {
+	var proc *workerProcess
+	defer func() {
+		if proc != nil {
+			proc.close()
+		}
+	}()
+
+	for dir := range dirs {
+		if ctx.Err() != nil {
+			log.Printf("[DEBUG] Worker in %s is canceled\n", dir)
+			ch <- worker{dir: dir, stdout: new(bytes.Buffer), stderr: new(bytes.Buffer), err: ctx.Err()}
+			continue
+		}
+
+		if proc == nil {
+			var err error
+			proc, err = startWorkerProcess(ctx, executable, opts)
+			if err != nil {
+				ch <- worker{dir: dir, stdout: new(bytes.Buffer), stderr: new(bytes.Buffer), err: err}
+				continue
+			}
+		}
+
+		result, err := proc.inspect(dir)
+		if err != nil {
+			// The process is no longer available, so wait for it to exit and report the exit status instead.
+			if waitErr := proc.close(); waitErr != nil {
+				err = waitErr
+			}
+			proc = nil
+		}
+		if ctx.Err() != nil {
+			// If the context is canceled, return the context error instead of the command error.
+			err = ctx.Err()
+		}
+		if err != nil {
+			ch <- worker{dir: dir, stdout: new(bytes.Buffer), stderr: new(bytes.Buffer), err: err}
+			continue
+		}
+
+		ch <- result.toWorker()
+	}
+}
+
+// workerProcess is a worker process that inspects directories sent through stdin.
+type workerProcess struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	decoder *json.Decoder
+}
+
+// workerResult is the result of each directory returned from a worker process.
+// Stdout and stderr are what the worker would have output when inspecting the directory alone.
+type workerResult struct {
+	Dir    string `json:"dir"`
+	Stdout string `json:"stdout"`
+	Stderr string `json:"stderr"`
+	Status int    `json:"status"`
+}
+
+func startWorkerProcess(ctx context.Context, executable string, opts Options) (*workerProcess, error) {
+	cmd := exec.CommandContext(ctx, executable, opts.toWorkerCommands()...)
+	// Logs from workers and plugins are not tied to a directory
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
 	cmd.Cancel = func() error {
-		log.Printf("[DEBUG] Worker in %s is terminated\n", workingDir)
+		log.Printf("[DEBUG] Worker %d is terminated\n", cmd.Process.Pid)
 		return cmd.Process.Signal(os.Interrupt)
 	}
 	cmd.WaitDelay = 3 * time.Second
-	err := cmd.Run()
-	if ctx.Err() != nil {
-		// If the context is canceled, return the context error instead of the command error.
-		err = ctx.Err()
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
 	}
+	return &workerProcess{cmd: cmd, stdin: stdin, decoder: json.NewDecoder(stdout)}, nil
+}
 
-	ch <- worker{dir: workingDir, stdout: stdout, stderr: stderr, err: err}
+func (p *workerProcess) inspect(dir string) (*workerResult, error) {
+	if _, err := fmt.Fprintln(p.stdin, dir); err != nil {
+		return nil, err
+	}
+
+	var result workerResult
+	if err := p.decoder.Decode(&result); err != nil {
+		return nil, err
+	}
+	if result.Dir != dir {
+		return nil, fmt.Errorf("unexpected result for %s from the worker", result.Dir)
+	}
+	return &result, nil
+}
+
+// close closes stdin to tell the worker that there are no more directories,
+// and waits for the process to exit.
+func (p *workerProcess) close() error {
+	p.stdin.Close()
+	return p.cmd.Wait()
+}
+
+func (r *workerResult) toWorker() worker {
+	var err error
+	if r.Status != ExitCodeOK {
+		err = fmt.Errorf("exit status %d", r.Status)
+	}
+	return worker{dir: r.Dir, stdout: strings.NewReader(r.Stdout), stderr: strings.NewReader(r.Stderr), err: err}
 }
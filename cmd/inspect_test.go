@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCLI_loadPolicies(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	for _, policyDir := range []string{"a", "b"} {
+		if err := os.Mkdir(policyDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		policy := "package tflint\n\ndeny_" + policyDir + " contains issue if {\n\tfalse\n\tissue := {}\n}\n"
+		if err := os.WriteFile(filepath.Join(policyDir, "main.rego"), []byte(policy), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cli, err := NewCLI(os.Stdout, os.Stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := cli.loadPolicies("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The same directory is compiled only once, even if the path is different
+	got, err := cli.loadPolicies(filepath.Join(dir, "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != a {
+		t.Error("policies in the same directory should be reused")
+	}
+
+	b, err := cli.loadPolicies("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names, _ := b.RuleNames(); len(names) != 1 || names[0] != "deny_b" {
+		t.Errorf("unexpected rules: %v", names)
+	}
+
+	empty, err := cli.loadPolicies("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty != nil {
+		t.Errorf("policies should be nil, but got %#v", empty)
+	}
+}
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"os"
 
 	"github.com/sourcegraph/jsonrpc2"
@@ -25,17 +27,44 @@ func (cli *CLI) startLanguageServer(opts Options) int {
 
 	log.Println("Starting language server...")
 
-	handler, plugin, err := langserver.NewHandler(configPath, cliConfig)
+	server, err := langserver.NewServer(configPath, cliConfig)
 	if err != nil {
 		log.Printf("Failed to start language server: %s", err)
 		return ExitCodeError
 	}
-	if plugin != nil {
-		defer plugin.Clean()
-	}
+	defer server.Clean()
 
 	ch := registerShutdownCh()
 
+	if opts.Listen != "" {
+		listener, err := net.Listen("tcp", opts.Listen)
+		if err != nil {
+			log.Printf("Failed to listen on %s: %s", opts.Listen, err)
+			return ExitCodeError
+		}
+		defer listener.Close()
+		log.Printf("Listening on %s", listener.Addr())
+
+		done := make(chan error, 1)
+		go func() {
+			done <- serveLanguageServer(listener, server)
+		}()
+
+		select {
+		case sig := <-ch:
+			log.Printf("Received %s, shutting down...\n", sig)
+		case err := <-done:
+			log.Printf("Failed to accept connections: %s", err)
+			return ExitCodeError
+		}
+		return ExitCodeOK
+	}
+
+	handler, err := server.NewHandler()
+	if err != nil {
+		log.Printf("Failed to start language server: %s", err)
+		return ExitCodeError
+	}
 	conn := jsonrpc2.NewConn(
 		context.Background(),
 		jsonrpc2.NewBufferedStream(langserver.NewConn(os.Stdin, os.Stdout), jsonrpc2.VSCodeObjectCodec{}),
@@ -51,3 +80,34 @@ func (cli *CLI) startLanguageServer(opts Options) int {
 
 	return ExitCodeOK
 }
+
+// serveLanguageServer accepts connections until the listener is closed.
+// Each connection has its own session, and plugins are shared between sessions.
+func serveLanguageServer(listener net.Listener, server *langserver.Server) error {
+	for {
+		netConn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		log.Printf("Accepted connection from %s", netConn.RemoteAddr())
+
+		handler, err := server.NewHandler()
+		if err != nil {
+			log.Printf("Failed to start session: %s", err)
+			netConn.Close()
+			continue
+		}
+		conn := jsonrpc2.NewConn(
+			context.Background(),
+			jsonrpc2.NewBufferedStream(netConn, jsonrpc2.VSCodeObjectCodec{}),
+			handler,
+		)
+		go func() {
+			<-conn.DisconnectNotify()
+			log.Printf("Closed connection from %s", netConn.RemoteAddr())
+		}()
+	}
+}
@@ -13,7 +13,9 @@ import (
 type Options struct {
 	Version                bool     `short:"v" long:"version" description:"Print TFLint version"`
 	Init                   bool     `long:"init" description:"Install plugins"`
+	Upgrade                bool     `long:"upgrade" description:"Upgrade plugins within version constraints"`
 	Langserver             bool     `long:"langserver" description:"Start language server"`
+	Listen                 string   `long:"listen" description:"Listen on the address instead of stdio in the language server mode" value-name:"HOST:PORT"`
 	Format                 string   `short:"f" long:"format" description:"Output format" choice:"default" choice:"json" choice:"checkstyle" choice:"junit" choice:"compact" choice:"sarif"`
 	Config                 string   `short:"c" long:"config" description:"Config file name (default: .tflint.hcl)" value-name:"FILE"`
 	IgnoreModules          []string `long:"ignore-module" description:"Ignore module sources" value-name:"SOURCE"`
@@ -21,6 +23,7 @@ type Options struct {
 	DisableRules           []string `long:"disable-rule" description:"Disable rules from the command line" value-name:"RULE_NAME"`
 	Only                   []string `long:"only" description:"Enable only this rule, disabling all other defaults. Can be specified multiple times" value-name:"RULE_NAME"`
 	EnablePlugins          []string `long:"enable-plugin" description:"Enable plugins from the command line" value-name:"PLUGIN_NAME"`
+	Presets                []string `long:"preset" description:"Enable rules in the preset defined in the config file. Can be specified multiple times" value-name:"PRESET_NAME"`
 	Varfiles               []string `long:"var-file" description:"Terraform variable file name" value-name:"FILE"`
 	Variables              []string `long:"var" description:"Set a Terraform variable" value-name:"'foo=bar'"`
 	CallModuleType         *string  `long:"call-module-type" description:"Types of module to call (default: local)" choice:"all" choice:"local" choice:"none"`
@@ -34,6 +37,9 @@ type Options struct {
 	Fix                    bool     `long:"fix" description:"Fix issues automatically"`
 	NoParallelRunners      bool     `long:"no-parallel-runners" description:"Disable per-runner parallelism"`
 	MaxWorkers             *int     `long:"max-workers" description:"Set maximum number of workers in recursive inspection (default: number of CPUs)" value-name:"N"`
+	PluginLogDir           string   `long:"plugin-log-dir" description:"Write the stderr of each plugin to a log file in the directory" value-name:"DIR"`
+	DryRun                 bool     `long:"dry-run" description:"Print plugins to be removed by \"tflint plugins remove\" without removing them"`
+	AllowSharedPluginDir   bool     `long:"allow-shared-plugin-dir" description:"Allow \"tflint plugins remove\" to remove plugins in a plugin directory outside the working directory"`
 	ActAsBundledPlugin     bool     `long:"act-as-bundled-plugin" hidden:"true"`
 	ActAsWorker            bool     `long:"act-as-worker" hidden:"true"`
 }
@@ -84,6 +90,8 @@ func (opts *Options) toConfig() *tflint.Config {
 	log.Printf("[DEBUG]   DisableRules: %s", strings.Join(opts.DisableRules, ", "))
 	log.Printf("[DEBUG]   Only: %s", strings.Join(opts.Only, ", "))
 	log.Printf("[DEBUG]   EnablePlugins: %s", strings.Join(opts.EnablePlugins, ", "))
+	log.Printf("[DEBUG]   Presets: %s", strings.Join(opts.Presets, ", "))
+	log.Printf("[DEBUG]   PluginLogDir: %s", opts.PluginLogDir)
 	log.Printf("[DEBUG]   IgnoreModules:")
 	for name, ignore := range ignoreModules {
 		log.Printf("[DEBUG]     %s: %t", name, ignore)
@@ -137,20 +145,23 @@ func (opts *Options) toConfig() *tflint.Config {
 		IgnoreModules: ignoreModules,
 		Rules:         rules,
 		Plugins:       plugins,
+
+		EnabledPresets: opts.Presets,
+
+		PluginLogDir: opts.PluginLogDir,
 	}
 }
 
 // Return commands to be executed by worker processes in recursive inspection.
 // All possible CLI flags are delegated, but some flags are ignored because
 // the coordinator process that starts the workers is responsible.
-func (opts *Options) toWorkerCommands(workingDir string) []string {
+func (opts *Options) toWorkerCommands() []string {
 	commands := []string{
 		"--act-as-worker",
-		"--chdir=" + workingDir,
 		"--force", // Exit status is always ignored
 	}
 
-	// opts.Version, opts.Init, and opts.Langserver are not supported
+	// opts.Version, opts.Init, opts.Upgrade, opts.Langserver, and opts.Listen are not supported
 
 	// opt.Format is ignored because workers always output serialized issues
 
@@ -172,6 +183,9 @@ func (opts *Options) toWorkerCommands(workingDir string) []string {
 	for _, plugin := range opts.EnablePlugins {
 		commands = append(commands, fmt.Sprintf("--enable-plugin=%s", plugin))
 	}
+	for _, preset := range opts.Presets {
+		commands = append(commands, fmt.Sprintf("--preset=%s", preset))
+	}
 	for _, varfile := range opts.Varfiles {
 		commands = append(commands, fmt.Sprintf("--var-file=%s", varfile))
 	}
@@ -182,7 +196,7 @@ func (opts *Options) toWorkerCommands(workingDir string) []string {
 		commands = append(commands, fmt.Sprintf("--call-module-type=%s", *opts.CallModuleType))
 	}
 
-	// opts.Chdir should be ignored because it is given by the coordinator
+	// opts.Chdir should be ignored because directories are given by the coordinator through stdin
 
 	// opts.Recursive is not supported
 
@@ -203,6 +217,12 @@ func (opts *Options) toWorkerCommands(workingDir string) []string {
 
 	// opts.MaxWorkers is ignored because the coordinator is responsible for parallelism
 
+	if opts.PluginLogDir != "" {
+		commands = append(commands, fmt.Sprintf("--plugin-log-dir=%s", opts.PluginLogDir))
+	}
+
+	// opts.DryRun and opts.AllowSharedPluginDir are only for tflint plugins
+
 	// opts.ActAsBundledPlugin and opts.ActAsWorker are not supported
 
 	return commands
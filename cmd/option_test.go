@@ -219,6 +219,21 @@ func Test_toConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:    "--preset",
+			Command: "./tflint --preset security --preset naming",
+			Expected: &tflint.Config{
+				CallModuleType:    terraform.CallLocalModule,
+				Force:             false,
+				IgnoreModules:     map[string]bool{},
+				Varfiles:          []string{},
+				Variables:         []string{},
+				DisabledByDefault: false,
+				Rules:             map[string]*tflint.RuleConfig{},
+				Plugins:           map[string]*tflint.PluginConfig{},
+				EnabledPresets:    []string{"security", "naming"},
+			},
+		},
 		{
 			Name:    "--format",
 			Command: "./tflint --format compact",
@@ -235,6 +250,21 @@ func Test_toConfig(t *testing.T) {
 				Plugins:           map[string]*tflint.PluginConfig{},
 			},
 		},
+		{
+			Name:    "--plugin-log-dir",
+			Command: "./tflint --plugin-log-dir logs",
+			Expected: &tflint.Config{
+				CallModuleType:    terraform.CallLocalModule,
+				Force:             false,
+				IgnoreModules:     map[string]bool{},
+				Varfiles:          []string{},
+				Variables:         []string{},
+				DisabledByDefault: false,
+				Rules:             map[string]*tflint.RuleConfig{},
+				Plugins:           map[string]*tflint.PluginConfig{},
+				PluginLogDir:      "logs",
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -262,23 +292,23 @@ func Test_toConfig(t *testing.T) {
 
 func Test_toWorkerCommands(t *testing.T) {
 	tests := []struct {
-		name       string
-		in         []string
-		workingDir string
-		want       []string
+		name string
+		in   []string
+		want []string
 	}{
 		{
-			name:       "no args",
-			in:         []string{},
-			workingDir: "subdir",
-			want:       []string{"--act-as-worker", "--chdir=subdir", "--force"},
+			name: "no args",
+			in:   []string{},
+			want: []string{"--act-as-worker", "--force"},
 		},
 		{
 			name: "all",
 			in: []string{
 				"--version",
 				"--init",
+				"--upgrade",
 				"--langserver",
+				"--listen=127.0.0.1:4389",
 				"--format=json",
 				"--config=tflint.hcl",
 				"--ignore-module=module1",
@@ -291,6 +321,8 @@ func Test_toWorkerCommands(t *testing.T) {
 				"--only=rule6",
 				"--enable-plugin=plugin1",
 				"--enable-plugin=plugin2",
+				"--preset=preset1",
+				"--preset=preset2",
 				"--var-file=example1.tfvars",
 				"--var-file=example2.tfvars",
 				"--var=foo=bar",
@@ -307,14 +339,18 @@ func Test_toWorkerCommands(t *testing.T) {
 				"--fix",
 				"--no-parallel-runners",
 				"--max-workers=2",
+				"--plugin-log-dir=logs",
+				"--dry-run",
+				"--allow-shared-plugin-dir",
 				"--act-as-bundled-plugin",
 				"--act-as-worker",
 			},
-			workingDir: "subdir",
 			want: []string{
 				// "--version",
 				// "--init",
+				// "--upgrade",
 				// "--langserver",
+				// "--listen=127.0.0.1:4389",
 				// "--format=json",
 				"--config=tflint.hcl",
 				"--ignore-module=module1",
@@ -327,12 +363,14 @@ func Test_toWorkerCommands(t *testing.T) {
 				"--only=rule6",
 				"--enable-plugin=plugin1",
 				"--enable-plugin=plugin2",
+				"--preset=preset1",
+				"--preset=preset2",
 				"--var-file=example1.tfvars",
 				"--var-file=example2.tfvars",
 				"--var=foo=bar",
 				"--var=bar=baz",
 				"--call-module-type=all",
-				"--chdir=subdir", // "--chdir=dir",
+				// "--chdir=dir",
 				// "--recursive",
 				"--filter=main1.tf",
 				"--filter=main2.tf",
@@ -343,6 +381,9 @@ func Test_toWorkerCommands(t *testing.T) {
 				"--fix",
 				"--no-parallel-runners",
 				// "--max-workers=2",
+				"--plugin-log-dir=logs",
+				// "--dry-run",
+				// "--allow-shared-plugin-dir",
 				// "--act-as-bundled-plugin",
 				"--act-as-worker",
 			},
@@ -358,7 +399,7 @@ func Test_toWorkerCommands(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			got := in.toWorkerCommands(test.workingDir)
+			got := in.toWorkerCommands()
 
 			opt := cmpopts.SortSlices(func(a, b string) bool { return a < b })
 			if diff := cmp.Diff(test.want, got, opt); diff != "" {
@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/afero"
+	"github.com/terraform-linters/tflint/plugin"
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+const pluginsUsage = "tflint plugins list|outdated|remove|verify [OPTIONS]"
+
+// plugins runs subcommands to manage installed plugins.
+func (cli *CLI) plugins(opts Options, args []string) int {
+	if len(args) != 1 {
+		cli.formatter.Print(tflint.Issues{}, fmt.Errorf("Usage: %s", pluginsUsage), map[string][]byte{})
+		return ExitCodeError
+	}
+	if opts.Recursive {
+		cli.formatter.Print(tflint.Issues{}, fmt.Errorf("Cannot use --recursive with tflint plugins"), map[string][]byte{})
+		return ExitCodeError
+	}
+
+	if (opts.DryRun || opts.AllowSharedPluginDir) && args[0] != "remove" {
+		cli.formatter.Print(tflint.Issues{}, fmt.Errorf("Cannot use --dry-run or --allow-shared-plugin-dir without tflint plugins remove"), map[string][]byte{})
+		return ExitCodeError
+	}
+
+	var subcommand func(*tflint.Config) error
+	switch args[0] {
+	case "list":
+		subcommand = cli.listPlugins
+	case "outdated":
+		subcommand = cli.printOutdatedPlugins
+	case "remove":
+		subcommand = func(cfg *tflint.Config) error {
+			return cli.removeUnusedPlugins(cfg, opts.DryRun, opts.AllowSharedPluginDir)
+		}
+	case "verify":
+		subcommand = cli.verifyPlugins
+	default:
+		cli.formatter.Print(tflint.Issues{}, fmt.Errorf(`Unknown subcommand "%s". Usage: %s`, args[0], pluginsUsage), map[string][]byte{})
+		return ExitCodeError
+	}
+
+	err := cli.withinChangedDir(opts.Chdir, func() error {
+		cfg, err := tflint.LoadConfig(afero.Afero{Fs: afero.NewOsFs()}, opts.Config)
+		if err != nil {
+			return fmt.Errorf("Failed to load TFLint config; %w", err)
+		}
+		cfg.Merge(opts.toConfig())
+
+		return subcommand(cfg)
+	})
+	if err != nil {
+		cli.formatter.Print(tflint.Issues{}, err, map[string][]byte{})
+		return ExitCodeError
+	}
+	return ExitCodeOK
+}
+
+// listPlugins prints plugins installed in the plugin directory.
+func (cli *CLI) listPlugins(cfg *tflint.Config) error {
+	installed, err := plugin.ListInstalledPlugins(cfg)
+	if err != nil {
+		return fmt.Errorf("Failed to list installed plugins; %w", err)
+	}
+	if len(installed) == 0 {
+		fmt.Fprint(cli.outStream, "No plugins installed\n")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(cli.outStream, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tSOURCE\tPATH")
+	for _, p := range installed {
+		version, source := p.Version, p.Source
+		if p.ManuallyInstalled() {
+			version, source = "-", "-"
+		}
+		// Prefer the source in the config, as registry URLs cannot be restored from the path
+		for _, installCfg := range installConfigs(cfg) {
+			if p.Matches(installCfg) {
+				source = installCfg.Source
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Name, version, source, p.Path)
+	}
+	return w.Flush()
+}
+
+// printOutdatedPlugins prints plugins in the config whose installed version is not the latest release.
+// "Wanted" is the newest version allowed by the config, and "Latest" is the newest release.
+func (cli *CLI) printOutdatedPlugins(cfg *tflint.Config) error {
+	lock, err := plugin.LoadLock(cfg)
+	if err != nil {
+		return fmt.Errorf("Failed to load %s; %w", plugin.LockFileName, err)
+	}
+
+	w := tabwriter.NewWriter(cli.outStream, 0, 0, 2, ' ', 0)
+	outdated := false
+	for _, installCfg := range installConfigs(cfg) {
+		current := "-"
+		lock.Resolve(installCfg)
+		if installCfg.ResolvedVersion() != "" {
+			if _, err := plugin.FindPluginPath(installCfg); err == nil {
+				current = installCfg.ResolvedVersion()
+			}
+		}
+
+		latest, err := installCfg.LatestVersion()
+		if err != nil {
+			return fmt.Errorf(`Failed to get the latest version of "%s" plugin; %w`, installCfg.Name, err)
+		}
+		if current == latest {
+			continue
+		}
+
+		wantedCfg := plugin.NewInstallConfig(cfg, installCfg.PluginConfig)
+		if err := wantedCfg.ResolveVersion(); err != nil {
+			return fmt.Errorf(`Failed to resolve the version of "%s" plugin; %w`, installCfg.Name, err)
+		}
+
+		if !outdated {
+			fmt.Fprintln(w, "NAME\tCURRENT\tWANTED\tLATEST\tSOURCE")
+			outdated = true
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", installCfg.Name, current, wantedCfg.ResolvedVersion(), latest, installCfg.Source)
+	}
+	if !outdated {
+		fmt.Fprint(cli.outStream, "All plugins are up to date\n")
+		return nil
+	}
+	return w.Flush()
+}
+
+// removeUnusedPlugins removes installed versions of plugins in the config that are not used.
+// Plugins that are not in the config are kept. The default plugin directory is shared
+// with other projects, which may use the other versions, so plugins are removed only if
+// the plugin directory is in the working directory, unless allowShared is true.
+// If dryRun is true, the plugins to be removed are only printed.
+func (cli *CLI) removeUnusedPlugins(cfg *tflint.Config, dryRun bool, allowShared bool) error {
+	lock, err := plugin.LoadLock(cfg)
+	if err != nil {
+		return fmt.Errorf("Failed to load %s; %w", plugin.LockFileName, err)
+	}
+	installed, err := plugin.ListInstalledPlugins(cfg)
+	if err != nil {
+		return fmt.Errorf("Failed to list installed plugins; %w", err)
+	}
+	pluginDir, err := plugin.PluginDir(cfg)
+	if err != nil {
+		return fmt.Errorf("Failed to get the plugin directory; %w", err)
+	}
+	shared, err := isSharedDir(pluginDir)
+	if err != nil {
+		return fmt.Errorf("Failed to resolve the plugin directory; %w", err)
+	}
+
+	unused := []*plugin.InstalledPlugin{}
+	sources := map[*plugin.InstalledPlugin]string{}
+	for _, installCfg := range installConfigs(cfg) {
+		// If the version constraint cannot be resolved by the lock file, the used version is unknown
+		lock.Resolve(installCfg)
+		if installCfg.ResolvedVersion() == "" {
+			continue
+		}
+
+		for _, p := range installed {
+			if !p.Matches(installCfg) || p.Version == installCfg.ResolvedVersion() {
+				continue
+			}
+			unused = append(unused, p)
+			sources[p] = installCfg.Source
+		}
+	}
+	if len(unused) == 0 {
+		fmt.Fprint(cli.outStream, "No unused plugins\n")
+		return nil
+	}
+
+	if dryRun || (shared && !allowShared) {
+		for _, p := range unused {
+			fmt.Fprintf(cli.outStream, "Would remove \"%s\" (source: %s, version: %s)\n", p.Name, sources[p], p.Version)
+		}
+		if !dryRun {
+			fmt.Fprintf(cli.outStream, "Nothing was removed because the plugin directory %s is outside the working directory and may be shared with other projects. Use --allow-shared-plugin-dir to remove them\n", pluginDir)
+		}
+		return nil
+	}
+
+	for _, p := range unused {
+		if err := os.Remove(p.Path); err != nil {
+			return fmt.Errorf(`Failed to remove "%s" plugin; %w`, p.Name, err)
+		}
+		// Remove the version directory if empty
+		_ = os.Remove(filepath.Dir(p.Path))
+
+		fmt.Fprintf(cli.outStream, "Removed \"%s\" (source: %s, version: %s)\n", p.Name, sources[p], p.Version)
+	}
+	return nil
+}
+
+// isSharedDir returns true if the directory is outside the working directory.
+// Symlinks are resolved, as the working directory is returned with resolved symlinks on some platforms.
+func isSharedDir(dir string) (bool, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return false, err
+	}
+	if wd, err = filepath.EvalSymlinks(wd); err != nil {
+		return false, err
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return false, err
+	}
+	if abs, err = filepath.EvalSymlinks(abs); err != nil {
+		return false, err
+	}
+	rel, err := filepath.Rel(wd, abs)
+	if err != nil {
+		return true, nil
+	}
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)), nil
+}
+
+// verifyPlugins checks that installed plugins match the checksums recorded in the lock file.
+func (cli *CLI) verifyPlugins(cfg *tflint.Config) error {
+	lock, err := plugin.LoadLock(cfg)
+	if err != nil {
+		return fmt.Errorf("Failed to load %s; %w", plugin.LockFileName, err)
+	}
+
+	failures := 0
+	for _, installCfg := range installConfigs(cfg) {
+		if _, locked := lock.Plugins[installCfg.Name]; !locked {
+			fmt.Fprintf(cli.outStream, "Plugin \"%s\" is not recorded in %s. Run \"tflint --init\" to record it\n", installCfg.Name, lock.Path())
+			failures++
+			continue
+		}
+		lock.Resolve(installCfg)
+
+		path, err := plugin.FindPluginPath(installCfg)
+		if err != nil {
+			fmt.Fprintf(cli.outStream, "Plugin \"%s\" is not installed. Run \"tflint --init\" to install it\n", installCfg.Name)
+			failures++
+			continue
+		}
+		if err := lock.Verify(installCfg, path); err != nil {
+			fmt.Fprintln(cli.outStream, err)
+			failures++
+			continue
+		}
+		if cfg.PluginVerification == tflint.PluginVerificationRequired {
+			if err := lock.RequireVerified(installCfg); err != nil {
+				fmt.Fprintln(cli.outStream, err)
+				failures++
+				continue
+			}
+		}
+		fmt.Fprintf(cli.outStream, "Verified \"%s\" (source: %s, version: %s)\n", installCfg.Name, installCfg.Source, installCfg.ResolvedVersion())
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d plugin(s) failed verification", failures)
+	}
+	return nil
+}
+
+// installConfigs returns install configs of automatically installed plugins in the config, sorted by name.
+func installConfigs(cfg *tflint.Config) []*plugin.InstallConfig {
+	ret := []*plugin.InstallConfig{}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Plugins)) {
+		installCfg := plugin.NewInstallConfig(cfg, cfg.Plugins[name])
+		if installCfg.ManuallyInstalled() {
+			continue
+		}
+		ret = append(ret, installCfg)
+	}
+	return ret
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/terraform-linters/tflint/plugin"
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+func TestPlugins(t *testing.T) {
+	dir := t.TempDir()
+	pluginDir := filepath.Join(dir, "plugins")
+	mirror := filepath.Join(dir, "mirror")
+
+	config := fmt.Sprintf(`
+config {
+  plugin_dir    = "%s"
+  plugin_mirror = "%s"
+}
+
+plugin "foo" {
+  enabled = true
+  source  = "github.com/org/tflint-ruleset-foo"
+  version = "~> 0.2.0"
+}`, filepath.ToSlash(pluginDir), filepath.ToSlash(mirror))
+	if err := os.WriteFile(filepath.Join(dir, ".tflint.hcl"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	binaryPath := func(version string) string {
+		ext := ""
+		if runtime.GOOS == "windows" {
+			ext = ".exe"
+		}
+		return filepath.Join(pluginDir, "github.com", "org", "tflint-ruleset-foo", version, "tflint-ruleset-foo"+ext)
+	}
+	for _, version := range []string{"0.1.0", "0.2.0"} {
+		if err := os.MkdirAll(filepath.Dir(binaryPath(version)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(binaryPath(version), []byte(version), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, version := range []string{"0.2.0", "0.3.0"} {
+		if err := os.MkdirAll(filepath.Join(mirror, "github.com", "org", "tflint-ruleset-foo", version), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := tflint.LoadConfig(afero.Afero{Fs: afero.NewOsFs()}, filepath.Join(dir, ".tflint.hcl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock, err := plugin.LoadLock(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.Plugins["foo"] = &plugin.LockedPlugin{
+		Name:         "foo",
+		Source:       "github.com/org/tflint-ruleset-foo",
+		Version:      "0.2.0",
+		BinaryHashes: map[string]string{fmt.Sprintf("%s_%s", runtime.GOOS, runtime.GOARCH): fmt.Sprintf("%x", sha256.Sum256([]byte("0.2.0")))},
+	}
+	if err := lock.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (int, string, string) {
+		outStream, errStream := new(bytes.Buffer), new(bytes.Buffer)
+		cli, err := NewCLI(outStream, errStream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		status := cli.Run(append([]string{"./tflint", "--chdir", dir}, args...))
+		return status, outStream.String(), errStream.String()
+	}
+
+	status, stdout, stderr := run("plugins", "list")
+	if status != ExitCodeOK {
+		t.Fatalf("list failed: status=%d, stderr=%s", status, stderr)
+	}
+	for _, version := range []string{"0.1.0", "0.2.0"} {
+		if !strings.Contains(stdout, fmt.Sprintf("foo   %s    github.com/org/tflint-ruleset-foo  %s", version, binaryPath(version))) {
+			t.Fatalf("Expected to list %s, but did not: stdout=%s", version, stdout)
+		}
+	}
+
+	status, stdout, stderr = run("plugins", "outdated")
+	if status != ExitCodeOK {
+		t.Fatalf("outdated failed: status=%d, stderr=%s", status, stderr)
+	}
+	if !strings.Contains(stdout, "foo   0.2.0    0.2.0   0.3.0   github.com/org/tflint-ruleset-foo") {
+		t.Fatalf("Expected to print an outdated plugin, but did not: stdout=%s", stdout)
+	}
+
+	status, stdout, stderr = run("plugins", "verify")
+	if status != ExitCodeOK {
+		t.Fatalf("verify failed: status=%d, stdout=%s, stderr=%s", status, stdout, stderr)
+	}
+	if !strings.Contains(stdout, `Verified "foo" (source: github.com/org/tflint-ruleset-foo, version: 0.2.0)`) {
+		t.Fatalf("Expected to verify the plugin, but did not: stdout=%s", stdout)
+	}
+
+	status, stdout, stderr = run("plugins", "remove", "--dry-run")
+	if status != ExitCodeOK {
+		t.Fatalf("remove --dry-run failed: status=%d, stderr=%s", status, stderr)
+	}
+	if !strings.Contains(stdout, `Would remove "foo" (source: github.com/org/tflint-ruleset-foo, version: 0.1.0)`) {
+		t.Fatalf("Expected to print the unused version, but did not: stdout=%s", stdout)
+	}
+	if _, err := os.Stat(binaryPath("0.1.0")); err != nil {
+		t.Fatalf("The unused version should be kept in dry run, but got %s", err)
+	}
+
+	status, stdout, stderr = run("plugins", "remove")
+	if status != ExitCodeOK {
+		t.Fatalf("remove failed: status=%d, stderr=%s", status, stderr)
+	}
+	if !strings.Contains(stdout, `Removed "foo" (source: github.com/org/tflint-ruleset-foo, version: 0.1.0)`) {
+		t.Fatalf("Expected to remove the unused version, but did not: stdout=%s", stdout)
+	}
+	if _, err := os.Stat(filepath.Dir(binaryPath("0.1.0"))); !os.IsNotExist(err) {
+		t.Fatalf("The unused version should be removed, but got %v", err)
+	}
+	if _, err := os.Stat(binaryPath("0.2.0")); err != nil {
+		t.Fatalf("The used version should be kept, but got %s", err)
+	}
+
+	if err := os.WriteFile(binaryPath("0.2.0"), []byte("modified"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	status, stdout, stderr = run("plugins", "verify")
+	if status != ExitCodeError {
+		t.Fatalf("verify should fail, but got status=%d", status)
+	}
+	if !strings.Contains(stdout, `Plugin "foo" does not match the checksum`) || !strings.Contains(stderr, "1 plugin(s) failed verification") {
+		t.Fatalf("Expected to report the mismatch, but did not: stdout=%s, stderr=%s", stdout, stderr)
+	}
+
+	status, _, stderr = run("plugins", "unknown")
+	if status != ExitCodeError || !strings.Contains(stderr, `Unknown subcommand "unknown". Usage: tflint plugins list|outdated|remove|verify [OPTIONS]`) {
+		t.Fatalf("Expected an unknown subcommand error, but got status=%d, stderr=%s", status, stderr)
+	}
+}
+
+func TestPlugins_removeShared(t *testing.T) {
+	dir := t.TempDir()
+	// The plugin directory is outside the project, like ~/.tflint.d/plugins
+	pluginDir := filepath.Join(dir, "plugins")
+	project := filepath.Join(dir, "project")
+
+	config := fmt.Sprintf(`
+config {
+  plugin_dir = "%s"
+}
+
+plugin "foo" {
+  enabled = true
+  source  = "github.com/org/tflint-ruleset-foo"
+  version = "0.2.0"
+}`, filepath.ToSlash(pluginDir))
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, ".tflint.hcl"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	binaryPath := func(version string) string {
+		ext := ""
+		if runtime.GOOS == "windows" {
+			ext = ".exe"
+		}
+		return filepath.Join(pluginDir, "github.com", "org", "tflint-ruleset-foo", version, "tflint-ruleset-foo"+ext)
+	}
+	for _, version := range []string{"0.1.0", "0.2.0"} {
+		if err := os.MkdirAll(filepath.Dir(binaryPath(version)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(binaryPath(version), []byte(version), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run := func(args ...string) (int, string, string) {
+		outStream, errStream := new(bytes.Buffer), new(bytes.Buffer)
+		cli, err := NewCLI(outStream, errStream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		status := cli.Run(append([]string{"./tflint", "--chdir", project}, args...))
+		return status, outStream.String(), errStream.String()
+	}
+
+	// Another project may use 0.1.0, so it is not removed by default
+	status, stdout, stderr := run("plugins", "remove")
+	if status != ExitCodeOK {
+		t.Fatalf("remove failed: status=%d, stderr=%s", status, stderr)
+	}
+	if !strings.Contains(stdout, `Would remove "foo" (source: github.com/org/tflint-ruleset-foo, version: 0.1.0)`) || !strings.Contains(stdout, "Use --allow-shared-plugin-dir to remove them") {
+		t.Fatalf("Expected to print the unused version, but did not: stdout=%s", stdout)
+	}
+	if _, err := os.Stat(binaryPath("0.1.0")); err != nil {
+		t.Fatalf("The plugin in the shared directory should be kept, but got %s", err)
+	}
+
+	status, stdout, stderr = run("plugins", "remove", "--allow-shared-plugin-dir")
+	if status != ExitCodeOK {
+		t.Fatalf("remove failed: status=%d, stderr=%s", status, stderr)
+	}
+	if !strings.Contains(stdout, `Removed "foo" (source: github.com/org/tflint-ruleset-foo, version: 0.1.0)`) {
+		t.Fatalf("Expected to remove the unused version, but did not: stdout=%s", stdout)
+	}
+	if _, err := os.Stat(binaryPath("0.1.0")); !os.IsNotExist(err) {
+		t.Fatalf("The unused version should be removed, but got %v", err)
+	}
+
+	status, _, stderr = run("plugins", "list", "--dry-run")
+	if status != ExitCodeError || !strings.Contains(stderr, "Cannot use --dry-run or --allow-shared-plugin-dir without tflint plugins remove") {
+		t.Fatalf("Expected an error, but got status=%d, stderr=%s", status, stderr)
+	}
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// actAsWorker inspects directories given by the coordinator through stdin line by line,
+// and writes the results to stdout as JSON lines. Plugins launched for a directory
+// are reused for subsequent directories as long as the config is the same.
+func (cli *CLI) actAsWorker(opts Options) int {
+	defer cli.cleanPlugins()
+
+	outStream, errStream := cli.outStream, cli.errStream
+	encoder := json.NewEncoder(outStream)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		dirOpts := opts
+		dirOpts.Chdir = scanner.Text()
+
+		// Reset fields for each module, and capture outputs for the directory
+		stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+		cli.outStream, cli.errStream = stdout, stderr
+		cli.formatter.Stdout, cli.formatter.Stderr = stdout, stderr
+		cli.formatter.Format = opts.Format
+		cli.sources = map[string][]byte{}
+		cli.config, cli.loader = nil, nil
+
+		status := cli.inspect(dirOpts)
+
+		cli.outStream, cli.errStream = outStream, errStream
+		result := workerResult{Dir: dirOpts.Chdir, Stdout: stdout.String(), Stderr: stderr.String(), Status: status}
+		if err := encoder.Encode(result); err != nil {
+			fmt.Fprint(cli.errStream, err)
+			return ExitCodeError
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprint(cli.errStream, err)
+		return ExitCodeError
+	}
+
+	return ExitCodeOK
+}
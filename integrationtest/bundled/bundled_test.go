@@ -41,6 +41,11 @@ func TestIntegration(t *testing.T) {
 			command: "tflint --format json --force",
 			dir:     "with_config",
 		},
+		{
+			name:    "with JSON config",
+			command: "tflint --format json --force",
+			dir:     "with_json_config",
+		},
 		{
 			name:    "disabled_by_default",
 			command: "tflint --format json --force",
@@ -135,6 +135,11 @@ func TestIntegration(t *testing.T) {
 			Command: "./tflint --format json",
 			Dir:     "rule-config",
 		},
+		{
+			Name:    "rule config in JSON",
+			Command: "./tflint --format json",
+			Dir:     "rule-config-json",
+		},
 		{
 			Name:    "disabled rules",
 			Command: "./tflint --format json",
@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -10,8 +11,8 @@ import (
 
 func Test_initialize(t *testing.T) {
 	withinFixtureDir(t, "workdir", func(dir string) {
-		stdin, stdout, plugin := startServer(t, dir+"/.tflint.hcl")
-		defer plugin.Clean()
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
 
 		go func() {
 			fmt.Fprint(stdin, initializeRequest())
@@ -36,5 +37,150 @@ func initializeRequest() string {
 }
 
 func initializeResponse() string {
-	return toJSONRPC2(`{"id":0,"result":{"capabilities":{"textDocumentSync":{"openClose":true,"change":1}}},"jsonrpc":"2.0"}`)
+	return toJSONRPC2(`{"id":0,"result":{"capabilities":{"textDocumentSync":{"openClose":true,"change":2,"save":{"includeText":false}},"hoverProvider":true,"completionProvider":{},"executeCommandProvider":{"commands":["tflint.fixAll"]},"codeActionProvider":{"resolveProvider":true},"diagnosticProvider":{"interFileDependencies":true,"workspaceDiagnostics":true},"workspace":{"workspaceFolders":{"supported":true,"changeNotifications":true}}}},"jsonrpc":"2.0"}`)
+}
+
+func Test_initialize_progress(t *testing.T) {
+	withinFixtureDir(t, "workspace_folders", func(dir string) {
+		uri := pathToURI(dir + "/a/main.tf")
+
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
+
+		initialize := `{"id":0,"method":"initialize","params":{"workDoneToken":"init","capabilities":{"textDocument":{"diagnostic":{}}}},"jsonrpc":"2.0"}`
+		diagnostic := fmt.Sprintf(`{"id":1,"method":"textDocument/diagnostic","params":{"workDoneToken":2,"textDocument":{"uri":"%s"}},"jsonrpc":"2.0"}`, uri)
+
+		go func() {
+			fmt.Fprint(stdin, toJSONRPC2(initialize))
+			fmt.Fprint(stdin, toJSONRPC2(diagnostic))
+			fmt.Fprint(stdin, shutdownRequest())
+			fmt.Fprint(stdin, exitRequest())
+		}()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(stdout); err != nil {
+			t.Fatal(err)
+		}
+
+		// Progress is reported with the tokens passed by the client
+		expected := progressNotification(`"init"`, `{"kind":"begin","title":"TFLint","message":"Starting plugins"}`) +
+			progressNotification(`"init"`, `{"kind":"end"}`) +
+			initializeResponse() +
+			progressNotification(`2`, fmt.Sprintf(`{"kind":"begin","title":"TFLint","message":"Inspecting %s/a"}`, dir)) +
+			progressNotification(`2`, `{"kind":"end"}`) +
+			toJSONRPC2(fmt.Sprintf(`{"id":1,"result":{"kind":"full","resultId":"d9cc9910928824b9","items":[%s]},"jsonrpc":"2.0"}`, instanceTypeDiagnostic("t1.2xlarge", t))) +
+			emptyResponse()
+		if !cmp.Equal(expected, buf.String()) {
+			t.Fatalf("Diff: %s", cmp.Diff(expected, buf.String()))
+		}
+	})
+}
+
+func Test_initialize_pluginFailure(t *testing.T) {
+	withinTempDir(t, func(dir string) {
+		config := `
+plugin "missing" {
+  enabled = true
+  version = "0.1.0"
+  source  = "github.com/terraform-linters/tflint-ruleset-missing"
+}`
+		if err := os.WriteFile(dir+"/.tflint.hcl", []byte(config), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
+
+		go func() {
+			fmt.Fprint(stdin, initializeRequest())
+			fmt.Fprint(stdin, shutdownRequest())
+			fmt.Fprint(stdin, exitRequest())
+		}()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(stdout); err != nil {
+			t.Fatal(err)
+		}
+
+		// The server keeps running after showing the failure
+		expected := toJSONRPC2(`{"jsonrpc":"2.0","method":"window/showMessage","params":{"type":1,"message":"Failed to start plugins; Plugin \"missing\" not found. Did you run \"tflint --init\"?"}}`) +
+			initializeResponse() +
+			emptyResponse()
+		if !cmp.Equal(expected, buf.String()) {
+			t.Fatalf("Diff: %s", cmp.Diff(expected, buf.String()))
+		}
+	})
+}
+
+func Test_initialize_pluginRules(t *testing.T) {
+	withinFixtureDir(t, "plugin_rules", func(dir string) {
+		src, err := os.ReadFile(dir + "/main.tf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		uri := pathToURI(dir + "/main.tf")
+
+		// Rules of plugins are validated after plugins are started
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
+
+		go func() {
+			fmt.Fprint(stdin, initializeRequest())
+			fmt.Fprint(stdin, didOpenRequest(uri, string(src), t))
+			fmt.Fprint(stdin, shutdownRequest())
+			fmt.Fprint(stdin, exitRequest())
+		}()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(stdout); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := initializeResponse() + didOpenResponse(uri, t) + emptyResponse()
+		if !cmp.Equal(expected, buf.String()) {
+			t.Fatalf("Diff: %s", cmp.Diff(expected, buf.String()))
+		}
+	})
+}
+
+func Test_initialize_unknownRule(t *testing.T) {
+	withinTempDir(t, func(dir string) {
+		config := `
+plugin "testing" {
+  enabled = true
+}
+
+rule "unknown_rule" {
+  enabled = true
+}`
+		if err := os.WriteFile(dir+"/.tflint.hcl", []byte(config), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
+
+		go func() {
+			fmt.Fprint(stdin, initializeRequest())
+			fmt.Fprint(stdin, shutdownRequest())
+			fmt.Fprint(stdin, exitRequest())
+		}()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(stdout); err != nil {
+			t.Fatal(err)
+		}
+
+		// The server keeps running after showing the invalid rule
+		expected := toJSONRPC2(`{"jsonrpc":"2.0","method":"window/showMessage","params":{"type":1,"message":"Invalid config; Rule not found: unknown_rule"}}`) +
+			initializeResponse() +
+			emptyResponse()
+		if !cmp.Equal(expected, buf.String()) {
+			t.Fatalf("Diff: %s", cmp.Diff(expected, buf.String()))
+		}
+	})
+}
+
+func progressNotification(token string, value string) string {
+	return toJSONRPC2(fmt.Sprintf(`{"jsonrpc":"2.0","method":"$/progress","params":{"token":%s,"value":%s}}`, token, value))
 }
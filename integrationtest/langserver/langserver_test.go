@@ -14,7 +14,6 @@ import (
 	lsp "github.com/sourcegraph/go-lsp"
 	"github.com/sourcegraph/jsonrpc2"
 	"github.com/terraform-linters/tflint/langserver"
-	"github.com/terraform-linters/tflint/plugin"
 	"github.com/terraform-linters/tflint/tflint"
 )
 
@@ -41,8 +40,12 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
-func startServer(t *testing.T, configPath string) (io.Writer, io.Reader, *plugin.Plugin) {
-	handler, plugin, err := langserver.NewHandler(configPath, tflint.EmptyConfig())
+func startServer(t *testing.T, configPath string) (io.Writer, io.Reader, *langserver.Server) {
+	server, err := langserver.NewServer(configPath, tflint.EmptyConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, err := server.NewHandler()
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -58,7 +61,7 @@ func startServer(t *testing.T, configPath string) (io.Writer, io.Reader, *plugin
 		connOpt...,
 	)
 
-	return stdinWriter, stdoutReader, plugin
+	return stdinWriter, stdoutReader, server
 }
 
 func pathToURI(path string) lsp.DocumentURI {
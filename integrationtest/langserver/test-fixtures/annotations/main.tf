@@ -0,0 +1,8 @@
+resource "aws_instance" "foo" {
+  # tflint-ignore: aws_instance_example_type
+  instance_type = "t1.2xlarge"
+}
+
+resource "aws_instance" "bar" {
+  instance_type = "t2.micro" # tflint-ignore: aws_inst
+}
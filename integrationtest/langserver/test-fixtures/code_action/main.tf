@@ -0,0 +1,5 @@
+resource "aws_instance" "foo" {
+  instance_type = "t1.2xlarge"
+}
+
+// autofixed
@@ -0,0 +1,7 @@
+plugin "testing" {
+  enabled = true
+}
+
+rule "aws_instance_example_type" {
+  enabled = true
+}
@@ -0,0 +1,11 @@
+resource "aws_instance" "foo" {
+  instance_type = file("instance_type")
+}
+
+resource "aws_instance" "bar" {
+  instance_type = basename(abspath("."))
+}
+
+resource "aws_instance" "baz" {
+  instance_type = terraform.workspace
+}
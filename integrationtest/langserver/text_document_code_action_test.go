@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	lsp "github.com/sourcegraph/go-lsp"
+)
+
+type codeAction struct {
+	Title       string             `json:"title"`
+	Kind        lsp.CodeActionKind `json:"kind,omitempty"`
+	Diagnostics []lsp.Diagnostic   `json:"diagnostics,omitempty"`
+	IsPreferred bool               `json:"isPreferred,omitempty"`
+	Edit        *lsp.WorkspaceEdit `json:"edit,omitempty"`
+	Data        *codeActionData    `json:"data,omitempty"`
+}
+
+type codeActionData struct {
+	URI        lsp.DocumentURI `json:"uri"`
+	Diagnostic lsp.Diagnostic  `json:"diagnostic"`
+}
+
+func Test_textDocumentCodeAction(t *testing.T) {
+	withinFixtureDir(t, "code_action", func(dir string) {
+		src, err := os.ReadFile(dir + "/main.tf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		uri := pathToURI(dir + "/main.tf")
+
+		instanceTypeDiag := lsp.Diagnostic{
+			Message:  `instance type is t1.2xlarge`,
+			Severity: lsp.Error,
+			Code:     "aws_instance_example_type",
+			Source:   "tflint",
+			Range: lsp.Range{
+				Start: lsp.Position{Line: 1, Character: 18},
+				End:   lsp.Position{Line: 1, Character: 30},
+			},
+		}
+		commentDiag := lsp.Diagnostic{
+			Message:  `Use "# autofixed" instead of "// autofixed"`,
+			Severity: lsp.Error,
+			Code:     "terraform_autofix_comment",
+			Source:   "tflint",
+			Range: lsp.Range{
+				Start: lsp.Position{Line: 4, Character: 0},
+				End:   lsp.Position{Line: 5, Character: 0},
+			},
+		}
+
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
+
+		go func() {
+			fmt.Fprint(stdin, initializeRequest())
+			fmt.Fprint(stdin, didOpenRequest(uri, string(src), t))
+			fmt.Fprint(stdin, codeActionRequest(uri, []lsp.Diagnostic{instanceTypeDiag, commentDiag}, t))
+			fmt.Fprint(stdin, shutdownRequest())
+			fmt.Fprint(stdin, exitRequest())
+		}()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(stdout); err != nil {
+			t.Fatal(err)
+		}
+
+		didOpenResponse, err := json.Marshal(jsonrpcMessage{
+			Method: "textDocument/publishDiagnostics",
+			Params: lsp.PublishDiagnosticsParams{
+				URI:         uri,
+				Diagnostics: []lsp.Diagnostic{instanceTypeDiag, commentDiag},
+			},
+			JSONRPC: "2.0",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		insert := func(line, character int, text string) *lsp.WorkspaceEdit {
+			pos := lsp.Position{Line: line, Character: character}
+			return &lsp.WorkspaceEdit{
+				Changes: map[string][]lsp.TextEdit{
+					string(uri): {{Range: lsp.Range{Start: pos, End: pos}, NewText: text}},
+				},
+			}
+		}
+		codeActionResponse, err := json.Marshal(struct {
+			ID      int          `json:"id"`
+			Result  []codeAction `json:"result"`
+			JSONRPC string       `json:"jsonrpc"`
+		}{
+			ID: 1,
+			Result: []codeAction{
+				{
+					Title:       `Ignore "aws_instance_example_type" on this line`,
+					Kind:        lsp.CAKQuickFix,
+					Diagnostics: []lsp.Diagnostic{instanceTypeDiag},
+					Edit:        insert(1, 0, "  # tflint-ignore: aws_instance_example_type\n"),
+				},
+				{
+					Title:       `Ignore "aws_instance_example_type" in this file`,
+					Kind:        lsp.CAKQuickFix,
+					Diagnostics: []lsp.Diagnostic{instanceTypeDiag},
+					Edit:        insert(0, 0, "# tflint-ignore-file: aws_instance_example_type\n"),
+				},
+				{
+					Title:       `Fix "terraform_autofix_comment"`,
+					Kind:        lsp.CAKQuickFix,
+					Diagnostics: []lsp.Diagnostic{commentDiag},
+					IsPreferred: true,
+					Edit: &lsp.WorkspaceEdit{
+						Changes: map[string][]lsp.TextEdit{
+							string(uri): {
+								{
+									Range: lsp.Range{
+										Start: lsp.Position{Line: 4, Character: 0},
+										End:   lsp.Position{Line: 4, Character: 2},
+									},
+									NewText: "#",
+								},
+							},
+						},
+					},
+				},
+				{
+					Title:       `Ignore "terraform_autofix_comment" on this line`,
+					Kind:        lsp.CAKQuickFix,
+					Diagnostics: []lsp.Diagnostic{commentDiag},
+					Edit:        insert(4, 0, "# tflint-ignore: terraform_autofix_comment\n"),
+				},
+				{
+					Title:       `Ignore "terraform_autofix_comment" in this file`,
+					Kind:        lsp.CAKQuickFix,
+					Diagnostics: []lsp.Diagnostic{commentDiag},
+					Edit:        insert(0, 0, "# tflint-ignore-file: terraform_autofix_comment\n"),
+				},
+			},
+			JSONRPC: "2.0",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := initializeResponse() + toJSONRPC2(string(didOpenResponse)) + toJSONRPC2(string(codeActionResponse)) + emptyResponse()
+		if !cmp.Equal(expected, buf.String()) {
+			t.Fatalf("Diff: %s", cmp.Diff(expected, buf.String()))
+		}
+	})
+}
+
+func Test_codeActionResolve(t *testing.T) {
+	withinFixtureDir(t, "code_action", func(dir string) {
+		src, err := os.ReadFile(dir + "/main.tf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		uri := pathToURI(dir + "/main.tf")
+
+		commentDiag := lsp.Diagnostic{
+			Message:  `Use "# autofixed" instead of "// autofixed"`,
+			Severity: lsp.Error,
+			Code:     "terraform_autofix_comment",
+			Source:   "tflint",
+			Range: lsp.Range{
+				Start: lsp.Position{Line: 4, Character: 0},
+				End:   lsp.Position{Line: 5, Character: 0},
+			},
+		}
+		fixAction := codeAction{
+			Title:       `Fix "terraform_autofix_comment"`,
+			Kind:        lsp.CAKQuickFix,
+			Diagnostics: []lsp.Diagnostic{commentDiag},
+			IsPreferred: true,
+			Data:        &codeActionData{URI: uri, Diagnostic: commentDiag},
+		}
+		resolveReq, err := json.Marshal(jsonrpcMessage{
+			ID:      2,
+			Method:  "codeAction/resolve",
+			Params:  fixAction,
+			JSONRPC: "2.0",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
+
+		go func() {
+			fmt.Fprint(stdin, toJSONRPC2(`{"id":0,"method":"initialize","params":{"capabilities":{"textDocument":{"codeAction":{"resolveSupport":{"properties":["edit"]}}}}},"jsonrpc":"2.0"}`))
+			fmt.Fprint(stdin, didOpenRequest(uri, string(src), t))
+			fmt.Fprint(stdin, codeActionRequest(uri, []lsp.Diagnostic{commentDiag}, t))
+			fmt.Fprint(stdin, toJSONRPC2(string(resolveReq)))
+			fmt.Fprint(stdin, shutdownRequest())
+			fmt.Fprint(stdin, exitRequest())
+		}()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(stdout); err != nil {
+			t.Fatal(err)
+		}
+
+		// The fix is not computed until the code action is resolved
+		unresolved, err := json.Marshal(fixAction)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Contains(buf.Bytes(), unresolved) {
+			t.Fatalf("Unresolved fix action is not found: %s", buf.String())
+		}
+
+		fixAction.Edit = &lsp.WorkspaceEdit{
+			Changes: map[string][]lsp.TextEdit{
+				string(uri): {
+					{
+						Range: lsp.Range{
+							Start: lsp.Position{Line: 4, Character: 0},
+							End:   lsp.Position{Line: 4, Character: 2},
+						},
+						NewText: "#",
+					},
+				},
+			},
+		}
+		resolveResponse, err := json.Marshal(struct {
+			ID      int        `json:"id"`
+			Result  codeAction `json:"result"`
+			JSONRPC string     `json:"jsonrpc"`
+		}{ID: 2, Result: fixAction, JSONRPC: "2.0"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte(toJSONRPC2(string(resolveResponse))+emptyResponse())) {
+			t.Fatalf("Resolved fix action is not found: %s", buf.String())
+		}
+	})
+}
+
+func codeActionRequest(uri lsp.DocumentURI, diags []lsp.Diagnostic, t *testing.T) string {
+	req, err := json.Marshal(jsonrpcMessage{
+		ID:     1,
+		Method: "textDocument/codeAction",
+		Params: lsp.CodeActionParams{
+			TextDocument: lsp.TextDocumentIdentifier{URI: uri},
+			Range:        diags[0].Range,
+			Context:      lsp.CodeActionContext{Diagnostics: diags},
+		},
+		JSONRPC: "2.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return toJSONRPC2(string(req))
+}
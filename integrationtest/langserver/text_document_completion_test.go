@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	lsp "github.com/sourcegraph/go-lsp"
+)
+
+func Test_textDocumentCompletion(t *testing.T) {
+	withinFixtureDir(t, "annotations", func(dir string) {
+		src, err := os.ReadFile(dir + "/main.tf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		uri := pathToURI(dir + "/main.tf")
+
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
+
+		go func() {
+			fmt.Fprint(stdin, initializeRequest())
+			fmt.Fprint(stdin, didOpenRequest(uri, string(src), t))
+			// Rule name in the annotation
+			fmt.Fprint(stdin, completionRequest(1, uri, lsp.Position{Line: 6, Character: 54}, t))
+			// Outside of annotations
+			fmt.Fprint(stdin, completionRequest(2, uri, lsp.Position{Line: 6, Character: 10}, t))
+			fmt.Fprint(stdin, shutdownRequest())
+			fmt.Fprint(stdin, exitRequest())
+		}()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(stdout); err != nil {
+			t.Fatal(err)
+		}
+
+		rng := lsp.Range{Start: lsp.Position{Line: 6, Character: 46}, End: lsp.Position{Line: 6, Character: 54}}
+		items := []lsp.CompletionItem{
+			{Label: "all", Kind: lsp.CIKKeyword, Detail: "All rules", TextEdit: &lsp.TextEdit{Range: rng, NewText: "all"}},
+		}
+		for _, name := range []string{
+			"aws_autoscaling_group_cty_eval_example",
+			"aws_cloudformation_stack_error",
+			"aws_db_instance_with_default_config_example",
+			"aws_iam_policy_example",
+			"aws_iam_role_example",
+			"aws_instance_autofix_conflict",
+			"aws_instance_example_type",
+			"aws_instance_map_eval_example",
+			"aws_route53_record_eval_on_root_ctx_example",
+			"aws_s3_bucket_example_lifecycle_rule",
+			"aws_s3_bucket_with_config_example",
+			"locals_just_attributes_example",
+			"terraform_autofix_comment",
+			"terraform_autofix_remove_local",
+			"terraform_required_providers",
+			"testing_assertions_example",
+		} {
+			items = append(items, lsp.CompletionItem{Label: name, Kind: lsp.CIKValue, Detail: "testing", TextEdit: &lsp.TextEdit{Range: rng, NewText: name}})
+		}
+
+		expected := initializeResponse() +
+			instanceTypeResponse(uri, "t2.micro", lsp.Position{Line: 6, Character: 18}, t) +
+			completionResponse(1, items, t) +
+			completionResponse(2, []lsp.CompletionItem{}, t) +
+			emptyResponse()
+		if !cmp.Equal(expected, buf.String()) {
+			t.Fatalf("Diff: %s", cmp.Diff(expected, buf.String()))
+		}
+	})
+}
+
+func completionRequest(id int, uri lsp.DocumentURI, pos lsp.Position, t *testing.T) string {
+	req, err := json.Marshal(jsonrpcMessage{
+		ID:     id,
+		Method: "textDocument/completion",
+		Params: lsp.CompletionParams{
+			TextDocumentPositionParams: lsp.TextDocumentPositionParams{
+				TextDocument: lsp.TextDocumentIdentifier{URI: uri},
+				Position:     pos,
+			},
+		},
+		JSONRPC: "2.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return toJSONRPC2(string(req))
+}
+
+func completionResponse(id int, items []lsp.CompletionItem, t *testing.T) string {
+	res, err := json.Marshal(struct {
+		ID      int                `json:"id"`
+		Result  lsp.CompletionList `json:"result"`
+		JSONRPC string             `json:"jsonrpc"`
+	}{
+		ID:      id,
+		Result:  lsp.CompletionList{Items: items},
+		JSONRPC: "2.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return toJSONRPC2(string(res))
+}
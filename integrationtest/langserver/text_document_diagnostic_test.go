@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	lsp "github.com/sourcegraph/go-lsp"
+)
+
+func Test_textDocumentDiagnostic(t *testing.T) {
+	withinFixtureDir(t, "workspace_folders", func(dir string) {
+		src, err := os.ReadFile(dir + "/a/main.tf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		uri := pathToURI(dir + "/a/main.tf")
+
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
+
+		initialize := fmt.Sprintf(
+			`{"id":0,"method":"initialize","params":{"workspaceFolders":[{"uri":"%s","name":"root"}],"capabilities":{"textDocument":{"diagnostic":{}}}},"jsonrpc":"2.0"}`,
+			pathToURI(dir),
+		)
+
+		go func() {
+			fmt.Fprint(stdin, toJSONRPC2(initialize))
+			// Diagnostics are not pushed in pull mode
+			fmt.Fprint(stdin, didOpenRequest(uri, string(src), t))
+			fmt.Fprint(stdin, documentDiagnosticRequest(1, uri, "", t))
+			fmt.Fprint(stdin, documentDiagnosticRequest(2, uri, "d9cc9910928824b9", t))
+			fmt.Fprint(stdin, shutdownRequest())
+			fmt.Fprint(stdin, exitRequest())
+		}()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(stdout); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := initializeResponse() +
+			toJSONRPC2(fmt.Sprintf(`{"id":1,"result":{"kind":"full","resultId":"d9cc9910928824b9","items":[%s]},"jsonrpc":"2.0"}`, instanceTypeDiagnostic("t1.2xlarge", t))) +
+			toJSONRPC2(`{"id":2,"result":{"kind":"unchanged","resultId":"d9cc9910928824b9"},"jsonrpc":"2.0"}`) +
+			emptyResponse()
+		if !cmp.Equal(expected, buf.String()) {
+			t.Fatalf("Diff: %s", cmp.Diff(expected, buf.String()))
+		}
+	})
+}
+
+func Test_workspaceDiagnostic(t *testing.T) {
+	withinFixtureDir(t, "workspace_folders", func(dir string) {
+		uriA := pathToURI(dir + "/a/main.tf")
+		uriB := pathToURI(dir + "/b/main.tf")
+
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
+
+		initialize := fmt.Sprintf(
+			`{"id":0,"method":"initialize","params":{"workspaceFolders":[{"uri":"%s","name":"root"}],"capabilities":{"textDocument":{"diagnostic":{}}}},"jsonrpc":"2.0"}`,
+			pathToURI(dir),
+		)
+		// Files are reported without opening them
+		request := fmt.Sprintf(
+			`{"id":1,"method":"workspace/diagnostic","params":{"previousResultIds":[{"uri":"%s","value":"d9cc9910928824b9"}]},"jsonrpc":"2.0"}`,
+			uriA,
+		)
+
+		go func() {
+			fmt.Fprint(stdin, toJSONRPC2(initialize))
+			fmt.Fprint(stdin, toJSONRPC2(request))
+			fmt.Fprint(stdin, shutdownRequest())
+			fmt.Fprint(stdin, exitRequest())
+		}()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(stdout); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := initializeResponse() +
+			toJSONRPC2(fmt.Sprintf(
+				`{"id":1,"result":{"items":[{"kind":"unchanged","resultId":"d9cc9910928824b9","uri":"%s","version":null},{"kind":"full","resultId":"a657295c5d37cefc","items":[%s],"uri":"%s","version":null}]},"jsonrpc":"2.0"}`,
+				uriA,
+				instanceTypeDiagnostic("t2.micro", t),
+				uriB,
+			)) +
+			emptyResponse()
+		if !cmp.Equal(expected, buf.String()) {
+			t.Fatalf("Diff: %s", cmp.Diff(expected, buf.String()))
+		}
+	})
+}
+
+func Test_workspaceDiagnostic_brokenModule(t *testing.T) {
+	withinTempDir(t, func(dir string) {
+		config := `
+plugin "testing" {
+  enabled = true
+}`
+		files := map[string]string{
+			".tflint.hcl": config,
+			"a/main.tf": `resource "aws_instance" "foo" {
+    instance_type = "t1.2xlarge"
+}`,
+			"broken/main.tf": `resource "aws_instance" "foo" {`,
+		}
+		for name, content := range files {
+			if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+		}
+		uriA := pathToURI(dir + "/a/main.tf")
+
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
+
+		initialize := fmt.Sprintf(
+			`{"id":0,"method":"initialize","params":{"workspaceFolders":[{"uri":"%s","name":"root"}],"capabilities":{"textDocument":{"diagnostic":{}}}},"jsonrpc":"2.0"}`,
+			pathToURI(dir),
+		)
+		request := `{"id":1,"method":"workspace/diagnostic","params":{"previousResultIds":[]},"jsonrpc":"2.0"}`
+
+		go func() {
+			fmt.Fprint(stdin, toJSONRPC2(initialize))
+			fmt.Fprint(stdin, toJSONRPC2(request))
+			fmt.Fprint(stdin, shutdownRequest())
+			fmt.Fprint(stdin, exitRequest())
+		}()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(stdout); err != nil {
+			t.Fatal(err)
+		}
+
+		// The broken module is skipped, and other modules are reported
+		expected := initializeResponse() +
+			toJSONRPC2(fmt.Sprintf(
+				`{"id":1,"result":{"items":[{"kind":"full","resultId":"d9cc9910928824b9","items":[%s],"uri":"%s","version":null}]},"jsonrpc":"2.0"}`,
+				instanceTypeDiagnostic("t1.2xlarge", t),
+				uriA,
+			)) +
+			emptyResponse()
+		if !cmp.Equal(expected, buf.String()) {
+			t.Fatalf("Diff: %s", cmp.Diff(expected, buf.String()))
+		}
+	})
+}
+
+func documentDiagnosticRequest(id int, uri lsp.DocumentURI, previousResultID string, t *testing.T) string {
+	req, err := json.Marshal(jsonrpcMessage{
+		ID:     id,
+		Method: "textDocument/diagnostic",
+		Params: map[string]any{
+			"textDocument":     lsp.TextDocumentIdentifier{URI: uri},
+			"previousResultId": previousResultID,
+		},
+		JSONRPC: "2.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return toJSONRPC2(string(req))
+}
+
+func instanceTypeDiagnostic(instanceType string, t *testing.T) string {
+	diag, err := json.Marshal(lsp.Diagnostic{
+		Message:  fmt.Sprintf("instance type is %s", instanceType),
+		Severity: lsp.Error,
+		Code:     "aws_instance_example_type",
+		Source:   "tflint",
+		Range: lsp.Range{
+			Start: lsp.Position{Line: 1, Character: 20},
+			End:   lsp.Position{Line: 1, Character: 22 + len(instanceType)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(diag)
+}
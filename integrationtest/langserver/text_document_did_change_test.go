@@ -19,8 +19,8 @@ func Test_textDocumentDidChange(t *testing.T) {
 		}
 		uri := pathToURI(dir + "/main.tf")
 
-		stdin, stdout, plugin := startServer(t, dir+"/.tflint.hcl")
-		defer plugin.Clean()
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
 
 		req, err := json.Marshal(jsonrpcMessage{
 			ID:     0,
@@ -91,3 +91,87 @@ func noDiagnosticsResponse(uri lsp.DocumentURI, t *testing.T) string {
 
 	return toJSONRPC2(string(didChangeResponse))
 }
+
+func Test_textDocumentDidChange_incremental(t *testing.T) {
+	withinFixtureDir(t, "workdir", func(dir string) {
+		src, err := os.ReadFile(dir + "/main.tf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		uri := pathToURI(dir + "/main.tf")
+
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
+
+		didChange := func(version int, rng lsp.Range, text string) string {
+			req, err := json.Marshal(jsonrpcMessage{
+				Method: "textDocument/didChange",
+				Params: lsp.DidChangeTextDocumentParams{
+					TextDocument: lsp.VersionedTextDocumentIdentifier{
+						TextDocumentIdentifier: lsp.TextDocumentIdentifier{
+							URI: uri,
+						},
+						Version: version,
+					},
+					ContentChanges: []lsp.TextDocumentContentChangeEvent{
+						{Range: &rng, Text: text},
+					},
+				},
+				JSONRPC: "2.0",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return toJSONRPC2(string(req))
+		}
+
+		go func() {
+			// Debounce long enough so that only one inspection is run on shutdown
+			fmt.Fprint(stdin, toJSONRPC2(`{"id":0,"method":"initialize","params":{"initializationOptions":{"debounceMs":10000}},"jsonrpc":"2.0"}`))
+			fmt.Fprint(stdin, didOpenRequest(uri, string(src), t))
+			fmt.Fprint(stdin, didChange(2, lsp.Range{
+				Start: lsp.Position{Line: 1, Character: 21},
+				End:   lsp.Position{Line: 1, Character: 31},
+			}, "t2.micro"))
+			fmt.Fprint(stdin, didChange(3, lsp.Range{
+				Start: lsp.Position{Line: 1, Character: 24},
+				End:   lsp.Position{Line: 1, Character: 29},
+			}, "large"))
+			fmt.Fprint(stdin, shutdownRequest())
+			fmt.Fprint(stdin, exitRequest())
+		}()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(stdout); err != nil {
+			t.Fatal(err)
+		}
+
+		didChangeResponse, err := json.Marshal(jsonrpcMessage{
+			Method: "textDocument/publishDiagnostics",
+			Params: lsp.PublishDiagnosticsParams{
+				URI: uri,
+				Diagnostics: []lsp.Diagnostic{
+					{
+						Message:  `instance type is t2.large`,
+						Severity: lsp.Error,
+						Code:     "aws_instance_example_type",
+						Source:   "tflint",
+						Range: lsp.Range{
+							Start: lsp.Position{Line: 1, Character: 20},
+							End:   lsp.Position{Line: 1, Character: 30},
+						},
+					},
+				},
+			},
+			JSONRPC: "2.0",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := initializeResponse() + didOpenResponse(uri, t) + toJSONRPC2(string(didChangeResponse)) + emptyResponse()
+		if !cmp.Equal(expected, buf.String()) {
+			t.Fatalf("Diff: %s", cmp.Diff(expected, buf.String()))
+		}
+	})
+}
@@ -19,8 +19,8 @@ func Test_textDocumentDidOpen(t *testing.T) {
 		}
 		uri := pathToURI(dir + "/main.tf")
 
-		stdin, stdout, plugin := startServer(t, dir+"/.tflint.hcl")
-		defer plugin.Clean()
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
 
 		go func() {
 			fmt.Fprint(stdin, initializeRequest())
@@ -71,6 +71,8 @@ func didOpenResponse(uri lsp.DocumentURI, t *testing.T) string {
 				{
 					Message:  `instance type is t1.2xlarge`,
 					Severity: lsp.Error,
+					Code:     "aws_instance_example_type",
+					Source:   "tflint",
 					Range: lsp.Range{
 						Start: lsp.Position{Line: 1, Character: 20},
 						End:   lsp.Position{Line: 1, Character: 32},
@@ -95,8 +97,8 @@ func Test_textDocumentDidOpen_pathFunctions(t *testing.T) {
 		}
 		uri := pathToURI(dir + "/main.tf")
 
-		stdin, stdout, plugin := startServer(t, dir+"/.tflint.hcl")
-		defer plugin.Clean()
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
 
 		go func() {
 			fmt.Fprint(stdin, initializeRequest())
@@ -118,6 +120,8 @@ func Test_textDocumentDidOpen_pathFunctions(t *testing.T) {
 					{
 						Message:  `instance type is t1.2xlarge`,
 						Severity: lsp.Error,
+						Code:     "aws_instance_example_type",
+						Source:   "tflint",
 						Range: lsp.Range{
 							Start: lsp.Position{Line: 1, Character: 20},
 							End:   lsp.Position{Line: 1, Character: 53},
@@ -137,3 +141,63 @@ func Test_textDocumentDidOpen_pathFunctions(t *testing.T) {
 		}
 	})
 }
+
+func Test_textDocumentDidOpen_relativePaths(t *testing.T) {
+	withinFixtureDir(t, "relative_paths", func(dir string) {
+		src, err := os.ReadFile(dir + "/module/main.tf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		uri := pathToURI(dir + "/module/main.tf")
+
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
+
+		go func() {
+			fmt.Fprint(stdin, initializeRequest())
+			fmt.Fprint(stdin, didOpenRequest(uri, string(src), t))
+			fmt.Fprint(stdin, shutdownRequest())
+			fmt.Fprint(stdin, exitRequest())
+		}()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(stdout); err != nil {
+			t.Fatal(err)
+		}
+
+		// Relative paths in functions and the workspace are resolved from the module directory,
+		// not the working directory of the server
+		diag := func(instanceType string, line int, start int, end int) lsp.Diagnostic {
+			return lsp.Diagnostic{
+				Message:  fmt.Sprintf("instance type is %s", instanceType),
+				Severity: lsp.Error,
+				Code:     "aws_instance_example_type",
+				Source:   "tflint",
+				Range: lsp.Range{
+					Start: lsp.Position{Line: line, Character: start},
+					End:   lsp.Position{Line: line, Character: end},
+				},
+			}
+		}
+		didOpenResponse, err := json.Marshal(jsonrpcMessage{
+			Method: "textDocument/publishDiagnostics",
+			Params: lsp.PublishDiagnosticsParams{
+				URI: uri,
+				Diagnostics: []lsp.Diagnostic{
+					diag("t1.2xlarge", 1, 18, 39),
+					diag("module", 5, 18, 40),
+					diag("staging", 9, 18, 37),
+				},
+			},
+			JSONRPC: "2.0",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := initializeResponse() + toJSONRPC2(string(didOpenResponse)) + emptyResponse()
+		if !cmp.Equal(expected, buf.String()) {
+			t.Fatalf("Diff: %s", cmp.Diff(expected, buf.String()))
+		}
+	})
+}
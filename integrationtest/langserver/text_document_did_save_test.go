@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	lsp "github.com/sourcegraph/go-lsp"
+)
+
+func Test_textDocumentDidSave_config(t *testing.T) {
+	withinTempDir(t, func(dir string) {
+		content := `resource "aws_instance" "foo" {
+    instance_type = "t1.2xlarge"
+}`
+
+		config := `
+plugin "testing" {
+    enabled = true
+}`
+
+		invalidConfig := `plugin "testing" {
+  enabled = true
+  unknown = "foo"
+}
+
+rule "aws_instance_unknown" {
+  enabled = true
+}`
+
+		changedConfig := `
+plugin "testing" {
+    enabled = true
+}
+
+rule "aws_instance_example_type" {
+    enabled = false
+}`
+
+		if err := os.WriteFile(dir+"/main.tf", []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(dir+"/.tflint.hcl", []byte(config), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		uri := pathToURI(dir + "/main.tf")
+		configURI := pathToURI(dir + "/.tflint.hcl")
+
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
+
+		didChange, err := json.Marshal(jsonrpcMessage{
+			Method: "textDocument/didChange",
+			Params: lsp.DidChangeTextDocumentParams{
+				TextDocument: lsp.VersionedTextDocumentIdentifier{
+					TextDocumentIdentifier: lsp.TextDocumentIdentifier{URI: configURI},
+					Version:                2,
+				},
+				ContentChanges: []lsp.TextDocumentContentChangeEvent{{Text: changedConfig}},
+			},
+			JSONRPC: "2.0",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		didSave, err := json.Marshal(jsonrpcMessage{
+			Method: "textDocument/didSave",
+			Params: lsp.DidSaveTextDocumentParams{
+				TextDocument: lsp.TextDocumentIdentifier{URI: configURI},
+			},
+			JSONRPC: "2.0",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		go func() {
+			fmt.Fprint(stdin, initializeRequest())
+			fmt.Fprint(stdin, didOpenRequest(uri, content, t))
+			fmt.Fprint(stdin, didOpenRequest(configURI, invalidConfig, t))
+			fmt.Fprint(stdin, toJSONRPC2(string(didChange)))
+			_ = os.WriteFile(dir+"/.tflint.hcl", []byte(changedConfig), os.ModePerm)
+			fmt.Fprint(stdin, toJSONRPC2(string(didSave)))
+			fmt.Fprint(stdin, shutdownRequest())
+			fmt.Fprint(stdin, exitRequest())
+		}()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(stdout); err != nil {
+			t.Fatal(err)
+		}
+
+		invalidConfigResponse, err := json.Marshal(jsonrpcMessage{
+			Method: "textDocument/publishDiagnostics",
+			Params: lsp.PublishDiagnosticsParams{
+				URI: configURI,
+				Diagnostics: []lsp.Diagnostic{
+					{
+						Message:  `Unsupported argument; An argument named "unknown" is not expected here.`,
+						Severity: lsp.Error,
+						Source:   "tflint",
+						Range: lsp.Range{
+							Start: lsp.Position{Line: 2, Character: 2},
+							End:   lsp.Position{Line: 2, Character: 9},
+						},
+					},
+					{
+						Message:  `Rule not found: aws_instance_unknown`,
+						Severity: lsp.Error,
+						Source:   "tflint",
+						Range: lsp.Range{
+							Start: lsp.Position{Line: 5, Character: 5},
+							End:   lsp.Position{Line: 5, Character: 27},
+						},
+					},
+				},
+			},
+			JSONRPC: "2.0",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The config is validated on changes, and reloaded on save
+		expected := initializeResponse() +
+			didOpenResponse(uri, t) +
+			toJSONRPC2(string(invalidConfigResponse)) +
+			noDiagnosticsResponse(configURI, t) +
+			noDiagnosticsResponse(configURI, t) +
+			noDiagnosticsResponse(uri, t) +
+			emptyResponse()
+		if !cmp.Equal(expected, buf.String()) {
+			t.Fatalf("Diff: %s", cmp.Diff(expected, buf.String()))
+		}
+	})
+}
+
+func Test_textDocumentDidSave_pluginNotRunning(t *testing.T) {
+	withinTempDir(t, func(dir string) {
+		content := `resource "aws_instance" "foo" {
+    instance_type = "t1.2xlarge"
+}`
+
+		config := `
+plugin "testing" {
+    enabled = true
+}`
+
+		// The rule of the plugin that is not running cannot be validated, but the config is reloaded
+		changedConfig := `
+plugin "testing" {
+    enabled = true
+}
+
+plugin "customrulesettesting" {
+    enabled = true
+}
+
+rule "aws_instance_example_type" {
+    enabled = false
+}
+
+rule "aws_instance_new_plugin_rule" {
+    enabled = true
+}`
+
+		if err := os.WriteFile(dir+"/main.tf", []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(dir+"/.tflint.hcl", []byte(config), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		uri := pathToURI(dir + "/main.tf")
+		configURI := pathToURI(dir + "/.tflint.hcl")
+
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
+
+		didSave, err := json.Marshal(jsonrpcMessage{
+			Method: "textDocument/didSave",
+			Params: lsp.DidSaveTextDocumentParams{
+				TextDocument: lsp.TextDocumentIdentifier{URI: configURI},
+			},
+			JSONRPC: "2.0",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		go func() {
+			fmt.Fprint(stdin, initializeRequest())
+			fmt.Fprint(stdin, didOpenRequest(uri, content, t))
+			_ = os.WriteFile(dir+"/.tflint.hcl", []byte(changedConfig), os.ModePerm)
+			fmt.Fprint(stdin, toJSONRPC2(string(didSave)))
+			fmt.Fprint(stdin, shutdownRequest())
+			fmt.Fprint(stdin, exitRequest())
+		}()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(stdout); err != nil {
+			t.Fatal(err)
+		}
+
+		notRunningResponse, err := json.Marshal(jsonrpcMessage{
+			Method: "textDocument/publishDiagnostics",
+			Params: lsp.PublishDiagnosticsParams{
+				URI: configURI,
+				Diagnostics: []lsp.Diagnostic{
+					{
+						Message:  `Plugin "customrulesettesting" is not running. Restart the language server to enable it`,
+						Severity: lsp.Warning,
+						Source:   "tflint",
+						Range: lsp.Range{
+							Start: lsp.Position{Line: 5, Character: 0},
+							End:   lsp.Position{Line: 5, Character: 29},
+						},
+					},
+				},
+			},
+			JSONRPC: "2.0",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := initializeResponse() +
+			didOpenResponse(uri, t) +
+			toJSONRPC2(string(notRunningResponse)) +
+			noDiagnosticsResponse(uri, t) +
+			emptyResponse()
+		if !cmp.Equal(expected, buf.String()) {
+			t.Fatalf("Diff: %s", cmp.Diff(expected, buf.String()))
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	lsp "github.com/sourcegraph/go-lsp"
+)
+
+type hover struct {
+	Contents struct {
+		Kind  string `json:"kind"`
+		Value string `json:"value"`
+	} `json:"contents"`
+	Range *lsp.Range `json:"range,omitempty"`
+}
+
+func Test_textDocumentHover(t *testing.T) {
+	withinFixtureDir(t, "annotations", func(dir string) {
+		src, err := os.ReadFile(dir + "/main.tf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		uri := pathToURI(dir + "/main.tf")
+
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
+
+		go func() {
+			fmt.Fprint(stdin, initializeRequest())
+			fmt.Fprint(stdin, didOpenRequest(uri, string(src), t))
+			// Rule name in the annotation
+			fmt.Fprint(stdin, hoverRequest(1, uri, lsp.Position{Line: 1, Character: 22}, t))
+			// Diagnostic
+			fmt.Fprint(stdin, hoverRequest(2, uri, lsp.Position{Line: 6, Character: 20}, t))
+			// Nothing
+			fmt.Fprint(stdin, hoverRequest(3, uri, lsp.Position{Line: 0, Character: 0}, t))
+			fmt.Fprint(stdin, shutdownRequest())
+			fmt.Fprint(stdin, exitRequest())
+		}()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(stdout); err != nil {
+			t.Fatal(err)
+		}
+
+		// The severity of the ignored rule is known because the issue is emitted and ignored by the annotation.
+		// Whether the rule is enabled by default is unknown, but the reported issue shows it is enabled.
+		annotationContent := "**aws_instance_example_type**\n\n- Ruleset: testing\n- Severity: Error\n"
+		content := "**aws_instance_example_type**\n\n- Ruleset: testing\n- Severity: Error\n- Enabled: true\n"
+
+		expected := initializeResponse() +
+			instanceTypeResponse(uri, "t2.micro", lsp.Position{Line: 6, Character: 18}, t) +
+			hoverResponse(1, annotationContent, lsp.Range{Start: lsp.Position{Line: 1, Character: 19}, End: lsp.Position{Line: 1, Character: 44}}, t) +
+			hoverResponse(2, content, lsp.Range{Start: lsp.Position{Line: 6, Character: 18}, End: lsp.Position{Line: 6, Character: 28}}, t) +
+			toJSONRPC2(`{"id":3,"result":null,"jsonrpc":"2.0"}`) +
+			emptyResponse()
+		if !cmp.Equal(expected, buf.String()) {
+			t.Fatalf("Diff: %s", cmp.Diff(expected, buf.String()))
+		}
+	})
+}
+
+func hoverRequest(id int, uri lsp.DocumentURI, pos lsp.Position, t *testing.T) string {
+	req, err := json.Marshal(jsonrpcMessage{
+		ID:     id,
+		Method: "textDocument/hover",
+		Params: lsp.TextDocumentPositionParams{
+			TextDocument: lsp.TextDocumentIdentifier{URI: uri},
+			Position:     pos,
+		},
+		JSONRPC: "2.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return toJSONRPC2(string(req))
+}
+
+func hoverResponse(id int, content string, rng lsp.Range, t *testing.T) string {
+	result := hover{Range: &rng}
+	result.Contents.Kind = "markdown"
+	result.Contents.Value = content
+
+	res, err := json.Marshal(struct {
+		ID      int    `json:"id"`
+		Result  hover  `json:"result"`
+		JSONRPC string `json:"jsonrpc"`
+	}{
+		ID:      id,
+		Result:  result,
+		JSONRPC: "2.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return toJSONRPC2(string(res))
+}
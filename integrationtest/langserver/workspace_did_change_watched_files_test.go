@@ -40,8 +40,8 @@ rule "aws_instance_example_type" {
 		}
 		uri := pathToURI(dir + "/main.tf")
 
-		stdin, stdout, plugin := startServer(t, dir+"/.tflint.hcl")
-		defer plugin.Clean()
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
 
 		req, err := json.Marshal(jsonrpcMessage{
 			ID:     0,
@@ -111,8 +111,8 @@ plugin "testing" {
 			t.Fatal(err)
 		}
 
-		stdin, stdout, plugin := startServer(t, dir+"/.tflint.hcl")
-		defer plugin.Clean()
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
 
 		req, err := json.Marshal(jsonrpcMessage{
 			ID:     0,
@@ -156,6 +156,8 @@ plugin "testing" {
 					{
 						Message:  `instance type is t1.2xlarge`,
 						Severity: lsp.Error,
+						Code:     "aws_instance_example_type",
+						Source:   "tflint",
 						Range: lsp.Range{
 							Start: lsp.Position{Line: 2, Character: 20},
 							End:   lsp.Position{Line: 2, Character: 37},
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	lsp "github.com/sourcegraph/go-lsp"
+)
+
+func Test_workspaceDidChangeWorkspaceFolders(t *testing.T) {
+	withinFixtureDir(t, "workspace_folders", func(dir string) {
+		srcA, err := os.ReadFile(dir + "/a/main.tf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		srcB, err := os.ReadFile(dir + "/b/main.tf")
+		if err != nil {
+			t.Fatal(err)
+		}
+		uriA := pathToURI(dir + "/a/main.tf")
+		uriB := pathToURI(dir + "/b/main.tf")
+
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
+
+		initialize := fmt.Sprintf(
+			`{"id":0,"method":"initialize","params":{"workspaceFolders":[{"uri":"%s","name":"a"},{"uri":"%s","name":"b"}]},"jsonrpc":"2.0"}`,
+			pathToURI(dir+"/a"),
+			pathToURI(dir+"/b"),
+		)
+		removeFolder := fmt.Sprintf(
+			`{"method":"workspace/didChangeWorkspaceFolders","params":{"event":{"added":[],"removed":[{"uri":"%s","name":"a"}]}},"jsonrpc":"2.0"}`,
+			pathToURI(dir+"/a"),
+		)
+
+		go func() {
+			fmt.Fprint(stdin, toJSONRPC2(initialize))
+			fmt.Fprint(stdin, didOpenRequest(uriA, string(srcA), t))
+			fmt.Fprint(stdin, didOpenRequest(uriB, string(srcB), t))
+			fmt.Fprint(stdin, toJSONRPC2(removeFolder))
+			fmt.Fprint(stdin, shutdownRequest())
+			fmt.Fprint(stdin, exitRequest())
+		}()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(stdout); err != nil {
+			t.Fatal(err)
+		}
+
+		// Opening a file in folder b must not clear diagnostics of folder a
+		expected := initializeResponse() +
+			didOpenResponse(uriA, t) +
+			instanceTypeResponse(uriB, "t2.micro", lsp.Position{Line: 1, Character: 20}, t) +
+			noDiagnosticsResponse(uriA, t) +
+			emptyResponse()
+		if !cmp.Equal(expected, buf.String()) {
+			t.Fatalf("Diff: %s", cmp.Diff(expected, buf.String()))
+		}
+	})
+}
+
+func instanceTypeResponse(uri lsp.DocumentURI, instanceType string, start lsp.Position, t *testing.T) string {
+	res, err := json.Marshal(jsonrpcMessage{
+		Method: "textDocument/publishDiagnostics",
+		Params: lsp.PublishDiagnosticsParams{
+			URI: uri,
+			Diagnostics: []lsp.Diagnostic{
+				{
+					Message:  fmt.Sprintf("instance type is %s", instanceType),
+					Severity: lsp.Error,
+					Code:     "aws_instance_example_type",
+					Source:   "tflint",
+					Range: lsp.Range{
+						Start: start,
+						End:   lsp.Position{Line: start.Line, Character: start.Character + len(instanceType) + 2},
+					},
+				},
+			},
+		},
+		JSONRPC: "2.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return toJSONRPC2(string(res))
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	lsp "github.com/sourcegraph/go-lsp"
+)
+
+func Test_workspaceExecuteCommand_fixAll(t *testing.T) {
+	withinFixtureDir(t, "code_action", func(dir string) {
+		uri := pathToURI(dir + "/main.tf")
+
+		stdin, stdout, server := startServer(t, dir+"/.tflint.hcl")
+		defer server.Clean()
+
+		// Files on disk are fixed without opening them
+		go func() {
+			fmt.Fprint(stdin, initializeRequest())
+			fmt.Fprint(stdin, executeCommandRequest(1, "tflint.fixAll", []any{uri}, t))
+			fmt.Fprint(stdin, executeCommandRequest(2, "tflint.unknown", []any{}, t))
+			fmt.Fprint(stdin, shutdownRequest())
+			fmt.Fprint(stdin, exitRequest())
+		}()
+
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(stdout); err != nil {
+			t.Fatal(err)
+		}
+
+		fixAllResponse, err := json.Marshal(struct {
+			ID      int               `json:"id"`
+			Result  lsp.WorkspaceEdit `json:"result"`
+			JSONRPC string            `json:"jsonrpc"`
+		}{
+			ID: 1,
+			Result: lsp.WorkspaceEdit{
+				Changes: map[string][]lsp.TextEdit{
+					string(uri): {
+						{
+							Range: lsp.Range{
+								Start: lsp.Position{Line: 4, Character: 0},
+								End:   lsp.Position{Line: 4, Character: 2},
+							},
+							NewText: "#",
+						},
+					},
+				},
+			},
+			JSONRPC: "2.0",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := initializeResponse() +
+			toJSONRPC2(string(fixAllResponse)) +
+			toJSONRPC2(`{"id":2,"error":{"code":-32602,"message":"unsupported command: tflint.unknown"},"jsonrpc":"2.0"}`) +
+			emptyResponse()
+		if !cmp.Equal(expected, buf.String()) {
+			t.Fatalf("Diff: %s", cmp.Diff(expected, buf.String()))
+		}
+	})
+}
+
+func executeCommandRequest(id int, command string, arguments []any, t *testing.T) string {
+	req, err := json.Marshal(jsonrpcMessage{
+		ID:     id,
+		Method: "workspace/executeCommand",
+		Params: lsp.ExecuteCommandParams{
+			Command:   command,
+			Arguments: arguments,
+		},
+		JSONRPC: "2.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return toJSONRPC2(string(req))
+}
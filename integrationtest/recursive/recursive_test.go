@@ -28,6 +28,11 @@ func TestIntegration(t *testing.T) {
 			command: "tflint --recursive --format json --force",
 			dir:     "basic",
 		},
+		{
+			name:    "recursive + single worker",
+			command: "tflint --recursive --max-workers=1 --format json --force",
+			dir:     "basic",
+		},
 		{
 			name:    "recursive + filter",
 			command: "tflint --recursive --filter=main.tf --format json --force",
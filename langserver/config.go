@@ -0,0 +1,220 @@
+package langserver
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	lsp "github.com/sourcegraph/go-lsp"
+	"github.com/spf13/afero"
+	"github.com/terraform-linters/tflint/plugin"
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+// configBlockSchema is a schema to find blocks in the config file.
+// Only block headers are needed to report diagnostics with ranges.
+var configBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "rule", LabelNames: []string{"name"}},
+		{Type: "plugin", LabelNames: []string{"name"}},
+		{Type: "custom_rule", LabelNames: []string{"name"}},
+		{Type: "override", LabelNames: []string{"pattern"}},
+		{Type: "preset", LabelNames: []string{"name"}},
+	},
+}
+
+var overrideBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "rule", LabelNames: []string{"name"}},
+	},
+}
+
+// configErrorPattern matches errors about a block in the config file, such as `plugin "foo": ...`.
+var configErrorPattern = regexp.MustCompile(`^(rule|plugin|custom_rule|override|preset) "([^"]+)"`)
+
+// isConfigFile returns true if the path is a TFLint config file.
+// If a config path is passed to the server, the file resolved from workspace folders
+// is also treated as a config file.
+func (h *handler) isConfigFile(path string) bool {
+	if tflint.IsDefaultConfigFile(path) {
+		return true
+	}
+	if h.configPath == "" {
+		return false
+	}
+	if filepath.IsAbs(h.configPath) {
+		return filepath.Clean(h.configPath) == path
+	}
+
+	root := h.workspaceFor(path).root
+	if root == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return false
+		}
+		root = wd
+	}
+	return filepath.Join(root, h.configPath) == path
+}
+
+// validateConfig loads the config file and returns diagnostics for it.
+// In addition to errors on loading, it reports unknown rules, invalid plugin configs,
+// and plugins that are not installed or not running.
+func (h *handler) validateConfig(path string) (map[string][]diagnostic, error) {
+	ret := map[string][]diagnostic{path: {}}
+
+	src, err := afero.ReadFile(h.fs, path)
+	if err != nil {
+		return ret, fmt.Errorf("Failed to read %s: %w", path, err)
+	}
+	parser := hclparse.NewParser()
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(path, ".json") {
+		file, diags = parser.ParseJSON(src, path)
+	} else {
+		file, diags = parser.ParseHCL(src, path)
+	}
+	if diags.HasErrors() {
+		ret[path] = toConfigDiagnostics(diags)
+		return ret, nil
+	}
+	// Errors are ignored because they are reported on loading
+	content, _, _ := file.Body.PartialContent(configBlockSchema)
+
+	cfg, err := tflint.LoadConfig(afero.Afero{Fs: h.fs}, path)
+	if err != nil {
+		var diags hcl.Diagnostics
+		if errors.As(err, &diags) {
+			ret[path] = toConfigDiagnostics(diags)
+		} else {
+			ret[path] = []diagnostic{{
+				Range:    configErrorRange(content, err),
+				Severity: lsp.Error,
+				Source:   "tflint",
+				Message:  err.Error(),
+			}}
+		}
+		return ret, nil
+	}
+
+	// The plugin directory is resolved from the working directory
+	log.Printf("Changing directory: %s", filepath.Dir(path))
+	if err := os.Chdir(filepath.Dir(path)); err != nil {
+		return ret, fmt.Errorf("Failed to chdir to %s: %s", filepath.Dir(path), err)
+	}
+
+	allPluginsRunning := true
+	for _, block := range content.Blocks.OfType("plugin") {
+		pluginCfg, exists := cfg.Plugins[block.Labels[0]]
+		if !exists || !pluginCfg.Enabled {
+			continue
+		}
+
+		ruleset, running := h.plugin.RuleSets[pluginCfg.Name]
+		if !running {
+			allPluginsRunning = false
+
+			diag := diagnostic{
+				Range:    toLSPRange(block.DefRange),
+				Severity: lsp.Warning,
+				Source:   "tflint",
+				Message:  fmt.Sprintf(`Plugin "%s" is not running. Restart the language server to enable it`, pluginCfg.Name),
+			}
+			installCfg := plugin.NewInstallConfig(cfg, pluginCfg)
+			if lock, err := plugin.LoadLock(cfg); err == nil {
+				lock.Resolve(installCfg)
+			}
+			if _, err := plugin.FindPluginPath(installCfg); os.IsNotExist(err) {
+				diag.Severity = lsp.Error
+				if installCfg.ManuallyInstalled() {
+					diag.Message = fmt.Sprintf(`Plugin "%s" not found`, pluginCfg.Name)
+				} else {
+					diag.Message = fmt.Sprintf(`Plugin "%s" not found. Did you run "tflint --init"?`, pluginCfg.Name)
+				}
+			}
+			ret[path] = append(ret[path], diag)
+			continue
+		}
+
+		schema, err := ruleset.ConfigSchema()
+		if err != nil {
+			h.restartCrashedPlugin(err)
+			return ret, fmt.Errorf(`Failed to fetch config schema from "%s" plugin; %w`, pluginCfg.Name, err)
+		}
+		if _, diags := pluginCfg.Content(schema); diags.HasErrors() {
+			ret[path] = append(ret[path], toConfigDiagnostics(diags)...)
+		}
+	}
+
+	// If some plugins are not running, their rules are unknown
+	if !allPluginsRunning {
+		return ret, nil
+	}
+	ws := h.workspaceFor(path)
+	rules, err := h.lookupRules(&workspace{root: ws.root, config: cfg, policies: ws.policies})
+	if err != nil {
+		return ret, err
+	}
+	ruleBlocks := content.Blocks.OfType("rule")
+	for _, block := range content.Blocks.OfType("override") {
+		inner, _, _ := block.Body.PartialContent(overrideBlockSchema)
+		ruleBlocks = append(ruleBlocks, inner.Blocks...)
+	}
+	for _, block := range ruleBlocks {
+		if _, exists := rules[block.Labels[0]]; exists {
+			continue
+		}
+		ret[path] = append(ret[path], diagnostic{
+			Range:    toLSPRange(block.LabelRanges[0]),
+			Severity: lsp.Error,
+			Source:   "tflint",
+			Message:  fmt.Sprintf("Rule not found: %s", block.Labels[0]),
+		})
+	}
+
+	return ret, nil
+}
+
+// configErrorRange returns the range of the block that caused the error.
+// If the block cannot be determined, it returns the beginning of the file.
+func configErrorRange(content *hcl.BodyContent, err error) lsp.Range {
+	match := configErrorPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return lsp.Range{}
+	}
+	for _, block := range content.Blocks.OfType(match[1]) {
+		if len(block.Labels) > 0 && block.Labels[0] == match[2] {
+			return toLSPRange(block.DefRange)
+		}
+	}
+	return lsp.Range{}
+}
+
+func toConfigDiagnostics(diags hcl.Diagnostics) []diagnostic {
+	ret := []diagnostic{}
+	for _, diag := range diags {
+		d := diagnostic{
+			Severity: lsp.Error,
+			Source:   "tflint",
+			Message:  diag.Summary,
+		}
+		if diag.Severity == hcl.DiagWarning {
+			d.Severity = lsp.Warning
+		}
+		if diag.Detail != "" {
+			d.Message = fmt.Sprintf("%s; %s", diag.Summary, diag.Detail)
+		}
+		if diag.Subject != nil {
+			d.Range = toLSPRange(*diag.Subject)
+		}
+		ret = append(ret, d)
+	}
+	return ret
+}
@@ -0,0 +1,128 @@
+package langserver
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	lsp "github.com/sourcegraph/go-lsp"
+)
+
+func Test_configErrorRange(t *testing.T) {
+	src := `
+rule "aws_instance_invalid_type" {
+  enabled = true
+}
+
+plugin "aws" {
+  enabled = true
+  source  = "github.com/terraform-linters/tflint-ruleset-aws"
+}`
+
+	tests := []struct {
+		name string
+		err  error
+		want lsp.Range
+	}{
+		{
+			name: "plugin",
+			err:  errors.New(`plugin "aws": "version" attribute cannot be omitted when specifying "source"`),
+			want: lsp.Range{Start: lsp.Position{Line: 5, Character: 0}, End: lsp.Position{Line: 5, Character: 12}},
+		},
+		{
+			name: "rule",
+			err:  errors.New(`rule "aws_instance_invalid_type": "[" is invalid glob pattern`),
+			want: lsp.Range{Start: lsp.Position{Line: 1, Character: 0}, End: lsp.Position{Line: 1, Character: 32}},
+		},
+		{
+			name: "unknown block",
+			err:  errors.New(`plugin "google": "source" is invalid`),
+			want: lsp.Range{},
+		},
+		{
+			name: "not a block error",
+			err:  errors.New(`foo is invalid format`),
+			want: lsp.Range{},
+		},
+	}
+
+	file, diags := hclsyntax.ParseConfig([]byte(src), ".tflint.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	content, _, diags := file.Body.PartialContent(configBlockSchema)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := configErrorRange(content, test.err)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func Test_pluginBlockAt(t *testing.T) {
+	src := `plugin "aws" {
+  enabled = true
+  deep_check = true
+
+  nested {
+    foo = 1
+  }
+}
+
+rule "aws_instance_invalid_type" {
+  enabled = true
+}`
+
+	tests := []struct {
+		name   string
+		cursor string
+		want   string
+	}{
+		{
+			name:   "plugin body",
+			cursor: "deep_",
+			want:   "aws",
+		},
+		{
+			name:   "nested block",
+			cursor: "foo",
+			want:   "",
+		},
+		{
+			name:   "rule body",
+			cursor: "enabled = true\n}",
+			want:   "",
+		},
+		{
+			name:   "plugin label",
+			cursor: `plugin "a`,
+			want:   "",
+		},
+	}
+
+	file, _ := hclsyntax.ParseConfig([]byte(src), ".tflint.hcl", hcl.InitialPos)
+	body := file.Body.(*hclsyntax.Body)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			offset := strings.LastIndex(src, test.cursor) + len(test.cursor)
+
+			got := ""
+			if block := pluginBlockAt(body, offset); block != nil {
+				got = block.Labels[0]
+			}
+			if got != test.want {
+				t.Errorf("expected %q, got %q", test.want, got)
+			}
+		})
+	}
+}
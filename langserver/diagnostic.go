@@ -0,0 +1,76 @@
+package langserver
+
+import (
+	"path/filepath"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	lsp "github.com/sourcegraph/go-lsp"
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+// diagnostic is a Diagnostic in the LSP specification.
+// go-lsp does not support codeDescription and relatedInformation.
+type diagnostic struct {
+	Range              lsp.Range                      `json:"range"`
+	Severity           lsp.DiagnosticSeverity         `json:"severity,omitempty"`
+	Code               string                         `json:"code,omitempty"`
+	CodeDescription    *codeDescription               `json:"codeDescription,omitempty"`
+	Source             string                         `json:"source,omitempty"`
+	Message            string                         `json:"message"`
+	RelatedInformation []diagnosticRelatedInformation `json:"relatedInformation,omitempty"`
+}
+
+type codeDescription struct {
+	Href string `json:"href"`
+}
+
+type diagnosticRelatedInformation struct {
+	Location lsp.Location `json:"location"`
+	Message  string       `json:"message"`
+}
+
+type publishDiagnosticsParams struct {
+	URI         lsp.DocumentURI `json:"uri"`
+	Diagnostics []diagnostic    `json:"diagnostics"`
+}
+
+// toDiagnostic converts the issue into a diagnostic.
+// For issues in called modules, the module arguments and the original location
+// in the module are reported as related information.
+// Paths in the issue are resolved from the module directory.
+func toDiagnostic(dir string, issue *tflint.Issue) diagnostic {
+	diag := diagnostic{
+		Message:  issue.Message,
+		Severity: toLSPSeverity(issue.Rule.Severity()),
+		Range:    toLSPRange(issue.Range),
+		Code:     issue.Rule.Name(),
+		Source:   "tflint",
+	}
+	if link := issue.Rule.Link(); link != "" {
+		diag.CodeDescription = &codeDescription{Href: link}
+	}
+
+	for idx, caller := range issue.Callers {
+		// The first caller is the same as the issue range
+		if caller == issue.Range {
+			continue
+		}
+		message := "Passed to the module here"
+		if idx == len(issue.Callers)-1 {
+			message = "Issue is found here in the called module"
+		}
+		diag.RelatedInformation = append(diag.RelatedInformation, diagnosticRelatedInformation{
+			Location: toLSPLocation(dir, caller),
+			Message:  message,
+		})
+	}
+
+	return diag
+}
+
+func toLSPLocation(dir string, rng hcl.Range) lsp.Location {
+	return lsp.Location{
+		URI:   pathToURI(filepath.Join(dir, rng.Filename)),
+		Range: toLSPRange(rng),
+	}
+}
@@ -0,0 +1,112 @@
+package langserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	hcl "github.com/hashicorp/hcl/v2"
+	lsp "github.com/sourcegraph/go-lsp"
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+func Test_toDiagnostic(t *testing.T) {
+	tests := []struct {
+		name  string
+		issue *tflint.Issue
+		want  diagnostic
+	}{
+		{
+			name: "basic",
+			issue: &tflint.Issue{
+				Rule:    &testRule{},
+				Message: "test message",
+				Range: hcl.Range{
+					Filename: "main.tf",
+					Start:    hcl.Pos{Line: 2, Column: 19},
+					End:      hcl.Pos{Line: 2, Column: 31},
+				},
+			},
+			want: diagnostic{
+				Range: lsp.Range{
+					Start: lsp.Position{Line: 1, Character: 18},
+					End:   lsp.Position{Line: 1, Character: 30},
+				},
+				Severity: lsp.Error,
+				Code:     "test_rule",
+				Source:   "tflint",
+				Message:  "test message",
+			},
+		},
+		{
+			name: "link and callers",
+			issue: &tflint.Issue{
+				Rule:    &testRule{link: "https://example.com"},
+				Message: "test message",
+				Range: hcl.Range{
+					Filename: "main.tf",
+					Start:    hcl.Pos{Line: 2, Column: 19},
+					End:      hcl.Pos{Line: 2, Column: 31},
+				},
+				Callers: []hcl.Range{
+					{
+						Filename: "main.tf",
+						Start:    hcl.Pos{Line: 2, Column: 19},
+						End:      hcl.Pos{Line: 2, Column: 31},
+					},
+					{
+						Filename: "module/main.tf",
+						Start:    hcl.Pos{Line: 5, Column: 11},
+						End:      hcl.Pos{Line: 5, Column: 20},
+					},
+					{
+						Filename: "module/child/main.tf",
+						Start:    hcl.Pos{Line: 3, Column: 19},
+						End:      hcl.Pos{Line: 3, Column: 35},
+					},
+				},
+			},
+			want: diagnostic{
+				Range: lsp.Range{
+					Start: lsp.Position{Line: 1, Character: 18},
+					End:   lsp.Position{Line: 1, Character: 30},
+				},
+				Severity:        lsp.Error,
+				Code:            "test_rule",
+				CodeDescription: &codeDescription{Href: "https://example.com"},
+				Source:          "tflint",
+				Message:         "test message",
+				RelatedInformation: []diagnosticRelatedInformation{
+					{
+						Location: lsp.Location{
+							URI: "file:///root/module/main.tf",
+							Range: lsp.Range{
+								Start: lsp.Position{Line: 4, Character: 10},
+								End:   lsp.Position{Line: 4, Character: 19},
+							},
+						},
+						Message: "Passed to the module here",
+					},
+					{
+						Location: lsp.Location{
+							URI: "file:///root/module/child/main.tf",
+							Range: lsp.Range{
+								Start: lsp.Position{Line: 2, Character: 18},
+								End:   lsp.Position{Line: 2, Character: 34},
+							},
+						},
+						Message: "Issue is found here in the called module",
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := toDiagnostic("/root", test.issue)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package langserver
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// dirFs is a filesystem that resolves relative paths from the given directory.
+// Unlike afero.BasePathFs, it allows access outside the directory, such as
+// parent directories referenced by module sources.
+//
+// The overlay filesystem holds opened files by absolute paths, so this is used
+// to access them with paths relative to the module directory.
+type dirFs struct {
+	fs  afero.Fs
+	dir string
+}
+
+var _ afero.Fs = (*dirFs)(nil)
+
+func newDirFs(fs afero.Fs, dir string) *dirFs {
+	return &dirFs{fs: fs, dir: dir}
+}
+
+func (d *dirFs) path(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(d.dir, name)
+}
+
+func (d *dirFs) Create(name string) (afero.File, error) {
+	return d.fs.Create(d.path(name))
+}
+
+func (d *dirFs) Mkdir(name string, perm os.FileMode) error {
+	return d.fs.Mkdir(d.path(name), perm)
+}
+
+func (d *dirFs) MkdirAll(path string, perm os.FileMode) error {
+	return d.fs.MkdirAll(d.path(path), perm)
+}
+
+func (d *dirFs) Open(name string) (afero.File, error) {
+	return d.fs.Open(d.path(name))
+}
+
+func (d *dirFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	return d.fs.OpenFile(d.path(name), flag, perm)
+}
+
+func (d *dirFs) Remove(name string) error {
+	return d.fs.Remove(d.path(name))
+}
+
+func (d *dirFs) RemoveAll(path string) error {
+	return d.fs.RemoveAll(d.path(path))
+}
+
+func (d *dirFs) Rename(oldname, newname string) error {
+	return d.fs.Rename(d.path(oldname), d.path(newname))
+}
+
+func (d *dirFs) Stat(name string) (os.FileInfo, error) {
+	return d.fs.Stat(d.path(name))
+}
+
+func (d *dirFs) Name() string {
+	return "dirFs"
+}
+
+func (d *dirFs) Chmod(name string, mode os.FileMode) error {
+	return d.fs.Chmod(d.path(name), mode)
+}
+
+func (d *dirFs) Chown(name string, uid, gid int) error {
+	return d.fs.Chown(d.path(name), uid, gid)
+}
+
+func (d *dirFs) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return d.fs.Chtimes(d.path(name), atime, mtime)
+}
@@ -3,13 +3,17 @@ package langserver
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"maps"
 	"net/url"
-	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/hcl/v2"
@@ -25,83 +29,195 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-// NewHandler returns a new JSON-RPC handler
-func NewHandler(configPath string, cliConfig *tflint.Config) (jsonrpc2.Handler, *plugin.Plugin, error) {
-	cfg, err := tflint.LoadConfig(afero.Afero{Fs: afero.NewOsFs()}, configPath)
+// Server is a language server that accepts multiple connections.
+// Plugins are launched once and shared between handlers of the connections.
+type Server struct {
+	configPath        string
+	cliConfig         *tflint.Config
+	config            *tflint.Config
+	plugin            *plugin.Plugin
+	clientSDKVersions map[string]*version.Version
+	// pluginStarted is false until plugins are started successfully.
+	// If plugins fail to start, they are started again on the next initialize.
+	pluginStarted bool
+
+	// mu serializes requests across connections, because they share plugins
+	// and the state of workspaces and their loaders.
+	mu sync.Mutex
+}
+
+// NewServer returns a new language server.
+// Plugins are started when a client sends the initialize request,
+// so that the client can be notified of the progress and failures.
+func NewServer(configPath string, cliConfig *tflint.Config) (*Server, error) {
+	cfg, err := loadConfig(afero.Afero{Fs: afero.NewOsFs()}, configPath, cliConfig)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	if cliConfig.DisabledByDefault {
-		for _, rule := range cfg.Rules {
-			rule.Enabled = false
-		}
+
+	return &Server{
+		configPath:        configPath,
+		cliConfig:         cliConfig,
+		config:            cfg,
+		plugin:            &plugin.Plugin{RuleSets: map[string]*plugin.RuleSet{}},
+		clientSDKVersions: map[string]*version.Version{},
+	}, nil
+}
+
+// NewHandler returns a new JSON-RPC handler for a connection.
+// Each handler has its own documents and workspaces.
+func (s *Server) NewHandler() (jsonrpc2.Handler, error) {
+	h := &handler{
+		server:            s,
+		configPath:        s.configPath,
+		cliConfig:         s.cliConfig,
+		fs:                afero.NewCopyOnWriteFs(afero.NewOsFs(), afero.NewMemMapFs()),
+		plugin:            s.plugin,
+		clientSDKVersions: s.clientSDKVersions,
+		workspaces:        []*workspace{},
+		modules:           map[string]*module{},
+		rules:             map[string]tflint.Rule{},
+		mu:                &s.mu,
+		debounce:          defaultDebounce,
+	}
+	var err error
+	h.defaultWorkspace, err = h.newWorkspaceWithConfig("", s.config)
+	if err != nil {
+		return nil, err
 	}
-	cfg.Merge(cliConfig)
 
-	rulsetPlugin, err := plugin.Discovery(cfg)
+	return newQueueHandler(jsonrpc2.HandlerWithError(h.handle)), nil
+}
+
+// Clean is a helper for ending plugin processes
+func (s *Server) Clean() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.plugin.Clean()
+}
+
+// startPlugins starts plugins enabled in the config if they have not been started yet.
+// The caller must hold the lock of the server.
+func (s *Server) startPlugins() error {
+	if s.pluginStarted {
+		return nil
+	}
+
+	rulsetPlugin, err := plugin.Discovery(s.config)
+	if err != nil {
+		return err
+	}
+
+	clientSDKVersions, err := checkPluginVersions(rulsetPlugin)
 	if err != nil {
-		return nil, nil, err
+		rulsetPlugin.Clean()
+		return err
 	}
 
-	rulesets := []tflint.RuleSet{}
+	s.plugin = rulsetPlugin
+	s.clientSDKVersions = clientSDKVersions
+	s.pluginStarted = true
+	return nil
+}
+
+// checkPluginVersions checks whether plugins are compatible with TFLint,
+// and returns the SDK versions of the plugins.
+func checkPluginVersions(rulsetPlugin *plugin.Plugin) (map[string]*version.Version, error) {
 	clientSDKVersions := map[string]*version.Version{}
 	for name, ruleset := range rulsetPlugin.RuleSets {
 		constraints, err := ruleset.VersionConstraints()
 		if err != nil {
 			if st, ok := status.FromError(err); ok && st.Code() == codes.Unimplemented {
 				// VersionConstraints endpoint is available in tflint-plugin-sdk v0.14+.
-				return nil, nil, fmt.Errorf(`Plugin "%s" SDK version is incompatible. Compatible versions: %s`, name, plugin.SDKVersionConstraints)
+				return nil, fmt.Errorf(`Plugin "%s" SDK version is incompatible. Compatible versions: %s`, name, plugin.SDKVersionConstraints)
 			} else {
-				return nil, nil, fmt.Errorf(`Failed to get TFLint version constraints to "%s" plugin; %w`, name, err)
+				return nil, fmt.Errorf(`Failed to get TFLint version constraints to "%s" plugin; %w`, name, err)
 			}
 		}
 		if !constraints.Check(tflint.Version) {
-			return nil, nil, fmt.Errorf("Failed to satisfy version constraints; tflint-ruleset-%s requires %s, but TFLint version is %s", name, constraints, tflint.Version)
+			return nil, fmt.Errorf("Failed to satisfy version constraints; tflint-ruleset-%s requires %s, but TFLint version is %s", name, constraints, tflint.Version)
 		}
 
 		clientSDKVersions[name], err = ruleset.SDKVersion()
 		if err != nil {
 			if st, ok := status.FromError(err); ok && st.Code() == codes.Unimplemented {
 				// SDKVersion endpoint is available in tflint-plugin-sdk v0.14+.
-				return nil, nil, fmt.Errorf(`Plugin "%s" SDK version is incompatible. Compatible versions: %s`, name, plugin.SDKVersionConstraints)
+				return nil, fmt.Errorf(`Plugin "%s" SDK version is incompatible. Compatible versions: %s`, name, plugin.SDKVersionConstraints)
 			} else {
-				return nil, nil, fmt.Errorf(`Failed to get plugin "%s" SDK version; %w`, name, err)
+				return nil, fmt.Errorf(`Failed to get plugin "%s" SDK version; %w`, name, err)
 			}
 		}
 		if !plugin.SDKVersionConstraints.Check(clientSDKVersions[name]) {
-			return nil, nil, fmt.Errorf(`Plugin "%s" SDK version (%s) is incompatible. Compatible versions: %s`, name, clientSDKVersions[name], plugin.SDKVersionConstraints)
+			return nil, fmt.Errorf(`Plugin "%s" SDK version (%s) is incompatible. Compatible versions: %s`, name, clientSDKVersions[name], plugin.SDKVersionConstraints)
 		}
+	}
+	return clientSDKVersions, nil
+}
 
-		rulesets = append(rulesets, ruleset)
+// loadConfig loads the TFLint config and merges the CLI config into it.
+func loadConfig(fs afero.Afero, configPath string, cliConfig *tflint.Config) (*tflint.Config, error) {
+	cfg, err := tflint.LoadConfig(fs, configPath)
+	if err != nil {
+		return nil, err
 	}
-	if err := cliConfig.ValidateRules(rulesets...); err != nil {
-		return nil, nil, err
+	if cliConfig.DisabledByDefault {
+		for _, rule := range cfg.Rules {
+			rule.Enabled = false
+		}
 	}
-
-	return jsonrpc2.HandlerWithError((&handler{
-		configPath:        configPath,
-		cliConfig:         cliConfig,
-		config:            cfg,
-		fs:                afero.NewCopyOnWriteFs(afero.NewOsFs(), afero.NewMemMapFs()),
-		plugin:            rulsetPlugin,
-		clientSDKVersions: clientSDKVersions,
-		diagsPaths:        []string{},
-	}).handle), rulsetPlugin, nil
+	cfg.Merge(cliConfig)
+	return cfg, nil
 }
 
 type handler struct {
+	server            *Server
 	configPath        string
 	cliConfig         *tflint.Config
-	config            *tflint.Config
 	fs                afero.Fs
-	rootDir           string
 	plugin            *plugin.Plugin
 	clientSDKVersions map[string]*version.Version
 	shutdown          bool
-	diagsPaths        []string
+	defaultWorkspace  *workspace
+	workspaces        []*workspace
+	modules           map[string]*module
+	// rules holds the rules that emitted issues, so their metadata can be shown in hovers.
+	rules map[string]tflint.Rule
+
+	// mu guards the states above against inspections debounced in other goroutines.
+	// It is shared with handlers of other connections on the same server.
+	mu *sync.Mutex
+
+	// pullDiagnostics is true if the client pulls diagnostics by textDocument/diagnostic.
+	// In this mode, diagnostics are not pushed and modules are inspected on demand.
+	pullDiagnostics bool
+	refreshSupport  bool
+	// codeActionResolveSupport is true if the client can resolve edits of code actions lazily.
+	codeActionResolveSupport bool
+	// progressSupport is true if the client supports progress created by the server.
+	progressSupport bool
+	progressCount   int
+
+	debounce time.Duration
+	// inspectMu guards timers and cancel functions of modules.
+	// Modules are also added and removed with this lock.
+	inspectMu       sync.Mutex
+	pendingInspects sync.WaitGroup
 }
 
+// defaultDebounce is the default idle time to wait for subsequent changes before inspecting.
+const defaultDebounce = 300 * time.Millisecond
+
 func (h *handler) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	// Inspections return the context error if the request is canceled by $/cancelRequest
+	defer func() {
+		if err != nil && ctx.Err() != nil && errors.Is(err, context.Canceled) {
+			err = requestCancelledError()
+		}
+	}()
+	ctx, progress := withWorkDoneProgress(ctx, conn, req)
+	defer progress.end(ctx)
+
 	if req.Params != nil {
 		params, err := json.Marshal(&req.Params)
 		if err != nil {
@@ -125,10 +241,11 @@ func (h *handler) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2
 
 	switch req.Method {
 	case "initialize":
-		return initialize(ctx, conn, req)
+		return h.initialize(ctx, conn, req)
 	case "initialized":
 		return nil, nil
 	case "shutdown":
+		h.flushInspections(conn)
 		h.shutdown = true
 		return nil, nil
 	case "exit":
@@ -139,8 +256,26 @@ func (h *handler) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2
 		return nil, nil
 	case "textDocument/didChange":
 		return h.textDocumentDidChange(ctx, conn, req)
+	case "textDocument/didSave":
+		return h.textDocumentDidSave(ctx, conn, req)
+	case "textDocument/codeAction":
+		return h.textDocumentCodeAction(ctx, conn, req)
+	case "codeAction/resolve":
+		return h.codeActionResolve(ctx, conn, req)
+	case "textDocument/hover":
+		return h.textDocumentHover(ctx, conn, req)
+	case "textDocument/completion":
+		return h.textDocumentCompletion(ctx, conn, req)
+	case "textDocument/diagnostic":
+		return h.textDocumentDiagnostic(ctx, conn, req)
+	case "workspace/diagnostic":
+		return h.workspaceDiagnostic(ctx, conn, req)
+	case "workspace/executeCommand":
+		return h.workspaceExecuteCommand(ctx, conn, req)
 	case "workspace/didChangeWatchedFiles":
 		return h.workspaceDidChangeWatchedFiles(ctx, conn, req)
+	case "workspace/didChangeWorkspaceFolders":
+		return h.workspaceDidChangeWorkspaceFolders(ctx, conn, req)
 	}
 
 	return nil, &jsonrpc2.Error{
@@ -149,32 +284,172 @@ func (h *handler) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2
 	}
 }
 
-func (h *handler) chdir(dir string) error {
-	if h.rootDir != dir {
-		log.Printf("Changing directory: %s", dir)
-		if err := os.Chdir(dir); err != nil {
-			return fmt.Errorf("Failed to chdir to %s: %s", dir, err)
+func (h *handler) inspect(ctx context.Context, conn *jsonrpc2.Conn, mod *module) (map[string][]diagnostic, error) {
+	ret := map[string][]diagnostic{}
+
+	done := h.startProgress(ctx, conn, fmt.Sprintf("Inspecting %s", mod.dir))
+	defer done()
+
+	runners, err := h.check(ctx, mod, false, nil, nil)
+	if err != nil {
+		return ret, err
+	}
+
+	// In order to publish that the issue has been fixed,
+	// notify also the path where the past diagnostics were published.
+	for _, path := range mod.diagsPaths {
+		ret[path] = []diagnostic{}
+	}
+	mod.diagsPaths = []string{}
+	mod.issues = map[string]tflint.Issues{}
+	mod.fixEdits = map[*tflint.Issue]*lsp.WorkspaceEdit{}
+	mod.inspected = true
+
+	for _, runner := range runners {
+		maps.Copy(h.rules, runner.LookupRules())
+
+		for _, issue := range runner.LookupIssues() {
+			path := filepath.Join(mod.dir, issue.Range.Filename)
+			mod.diagsPaths = append(mod.diagsPaths, path)
+			mod.issues[path] = append(mod.issues[path], issue)
+
+			diag := toDiagnostic(mod.dir, issue)
+
+			if ret[path] == nil {
+				ret[path] = []diagnostic{diag}
+			} else {
+				ret[path] = append(ret[path], diag)
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// scheduleInspection inspects the module and publishes diagnostics after the idle time.
+// If it is called again before that, the previous inspection is postponed.
+func (h *handler) scheduleInspection(conn *jsonrpc2.Conn, mod *module) {
+	h.inspectMu.Lock()
+	defer h.inspectMu.Unlock()
+
+	if mod.inspectTimer != nil && mod.inspectTimer.Stop() {
+		h.pendingInspects.Done()
+	}
+	h.pendingInspects.Add(1)
+	mod.inspectTimer = time.AfterFunc(h.debounce, func() {
+		defer h.pendingInspects.Done()
+		h.runInspection(conn, mod)
+	})
+}
+
+// flushInspections runs the scheduled inspections immediately and waits for all inspections to finish.
+func (h *handler) flushInspections(conn *jsonrpc2.Conn) {
+	h.mu.Lock()
+	modules := []*module{}
+	for _, mod := range h.modules {
+		modules = append(modules, mod)
+	}
+	h.mu.Unlock()
+
+	for _, mod := range modules {
+		h.inspectMu.Lock()
+		scheduled := mod.inspectTimer != nil && mod.inspectTimer.Stop()
+		h.inspectMu.Unlock()
+
+		if scheduled {
+			h.runInspection(conn, mod)
+			h.pendingInspects.Done()
 		}
-		h.rootDir = dir
 	}
-	return nil
+	h.pendingInspects.Wait()
 }
 
-func (h *handler) inspect() (map[string][]lsp.Diagnostic, error) {
-	ret := map[string][]lsp.Diagnostic{}
+// abortInspection cancels the in-flight inspection of the module containing the path.
+// Since it is called without the lock for the handler states, the module is looked up
+// from the known modules without accessing the filesystem.
+func (h *handler) abortInspection(path string) {
+	h.inspectMu.Lock()
+	defer h.inspectMu.Unlock()
+
+	var target *module
+	for _, mod := range h.modules {
+		if mod.contains(path) && (target == nil || len(mod.dir) > len(target.dir)) {
+			target = mod
+		}
+	}
+	if target != nil && target.cancelInspection != nil {
+		target.cancelInspection()
+	}
+}
+
+// stopInspection cancels both the scheduled and in-flight inspections of the module.
+func (h *handler) stopInspection(mod *module) {
+	h.inspectMu.Lock()
+	defer h.inspectMu.Unlock()
+
+	if mod.inspectTimer != nil && mod.inspectTimer.Stop() {
+		h.pendingInspects.Done()
+	}
+	if mod.cancelInspection != nil {
+		mod.cancelInspection()
+	}
+}
 
-	loader, err := terraform.NewLoader(afero.Afero{Fs: h.fs}, h.rootDir)
+func (h *handler) runInspection(conn *jsonrpc2.Conn, mod *module) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h.inspectMu.Lock()
+	mod.cancelInspection = cancel
+	h.inspectMu.Unlock()
+
+	h.mu.Lock()
+	diagnostics, err := h.inspect(ctx, conn, mod)
+	h.mu.Unlock()
 	if err != nil {
-		return ret, fmt.Errorf("Failed to prepare loading: %w", err)
+		var callErr *plugin.CallError
+		if errors.Is(err, context.Canceled) {
+			log.Println("Inspection was canceled by subsequent changes")
+		} else if errors.As(err, &callErr) {
+			log.Printf("Failed to inspect: %s", err)
+			showMessage(ctx, conn, lsp.MTError, fmt.Sprintf("Failed to inspect %s; %s", mod.dir, err))
+		} else {
+			log.Printf("Failed to inspect: %s", err)
+		}
+		return
+	}
+
+	if err := h.publishDiagnostics(ctx, conn, diagnostics); err != nil {
+		log.Println(err)
 	}
+}
 
-	configs, diags := loader.LoadConfig(".", h.config.CallModuleType)
+// check loads the module and runs all rules against it.
+// If fix is true, plugins apply autofixes to the runners. If only is passed,
+// only the specified rules are run, and filter is used to narrow down the issues
+// (and autofixes) to be emitted. The last runner of the returned runners is the
+// root module runner. If the context is canceled, the check is aborted
+// between plugin calls and returns the context error.
+// If a plugin crashes or does not respond, it is restarted for the next check.
+func (h *handler) check(ctx context.Context, mod *module, fix bool, only []string, filter func(*tflint.Issue) bool) (_ []*tflint.Runner, err error) {
+	defer func() { h.restartCrashedPlugin(err) }()
+
+	cfg := mod.workspace.config
+
+	// Relative paths are resolved from the module directory by the filesystem,
+	// so the check does not depend on the working directory of the server.
+	loader, err := terraform.NewLoaderAt(afero.Afero{Fs: newDirFs(h.fs, mod.dir)}, mod.dir, mod.dir)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to prepare loading: %w", err)
+	}
+
+	configs, diags := loader.LoadConfig(".", cfg.CallModuleType)
 	if diags.HasErrors() {
-		return ret, fmt.Errorf("Failed to load configurations: %w", diags)
+		return nil, fmt.Errorf("Failed to load configurations: %w", diags)
 	}
 	files, diags := loader.LoadConfigDirFiles(".")
 	if diags.HasErrors() {
-		return ret, fmt.Errorf("Failed to load configurations: %w", diags)
+		return nil, fmt.Errorf("Failed to load configurations: %w", diags)
 	}
 	annotations := map[string]tflint.Annotations{}
 	for path, file := range files {
@@ -183,85 +458,136 @@ func (h *handler) inspect() (map[string][]lsp.Diagnostic, error) {
 		annotations[path] = ants
 	}
 
-	variables, diags := loader.LoadValuesFiles(".", h.config.Varfiles...)
+	variables, diags := loader.LoadValuesFiles(".", cfg.Varfiles...)
 	if diags.HasErrors() {
-		return ret, fmt.Errorf("Failed to load values files: %w", diags)
+		return nil, fmt.Errorf("Failed to load values files: %w", diags)
 	}
-	cliVars, diags := terraform.ParseVariableValues(h.config.Variables, configs.Module.Variables)
+	cliVars, diags := terraform.ParseVariableValues(cfg.Variables, configs.Module.Variables)
 	if diags.HasErrors() {
-		return ret, fmt.Errorf("Failed to parse variables: %w", diags)
+		return nil, fmt.Errorf("Failed to parse variables: %w", diags)
 	}
 	variables = append(variables, cliVars)
 
-	runner, err := tflint.NewRunner(h.rootDir, h.config, annotations, configs, variables...)
+	runner, err := tflint.NewRunner(mod.dir, cfg, annotations, configs, variables...)
 	if err != nil {
-		return ret, fmt.Errorf("Failed to initialize a runner: %w", err)
+		return nil, fmt.Errorf("Failed to initialize a runner: %w", err)
 	}
+	// Terraform functions and the workspace are also resolved from the module directory
+	runner.Ctx.Meta.WorkingDir = mod.dir
+	runner.Ctx.Meta.Env = loader.Workspace()
 	runners, err := tflint.NewModuleRunners(runner)
 	if err != nil {
-		return ret, fmt.Errorf("Failed to prepare rule checking: %w", err)
+		return nil, fmt.Errorf("Failed to prepare rule checking: %w", err)
 	}
 	runners = append(runners, runner)
 
-	config := h.config.ToPluginConfig()
+	for _, runner := range runners {
+		runner.SetIssueFilter(filter)
+	}
+
+	config := cfg.ToPluginConfig()
+	config.Fix = fix
+	if len(only) > 0 {
+		config.Only = only
+	}
 	for name, ruleset := range h.plugin.RuleSets {
 		if err := ruleset.ApplyGlobalConfig(config); err != nil {
-			return ret, fmt.Errorf(`Failed to apply global config to "%s" plugin`, name)
+			return nil, fmt.Errorf(`Failed to apply global config to "%s" plugin: %w`, name, err)
 		}
 		configSchema, err := ruleset.ConfigSchema()
 		if err != nil {
-			return ret, fmt.Errorf(`Failed to fetch config schema from "%s" plugin`, name)
+			return nil, fmt.Errorf(`Failed to fetch config schema from "%s" plugin: %w`, name, err)
 		}
 		content := &hclext.BodyContent{}
-		if plugin, exists := h.config.Plugins[name]; exists {
+		if plugin, exists := cfg.Plugins[name]; exists {
 			var diags hcl.Diagnostics
 			content, diags = plugin.Content(configSchema)
 			if diags.HasErrors() {
-				return ret, fmt.Errorf(`Failed to parse "%s" plugin config`, name)
+				return nil, fmt.Errorf(`Failed to parse "%s" plugin config`, name)
 			}
 		}
-		err = ruleset.ApplyConfig(content, h.config.Sources())
+		err = ruleset.ApplyConfig(content, cfg.Sources())
 		if err != nil {
-			return ret, fmt.Errorf(`Failed to apply config to "%s" plugin`, name)
+			return nil, fmt.Errorf(`Failed to apply config to "%s" plugin: %w`, name, err)
 		}
 		for _, runner := range runners {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
 			err = ruleset.Check(plugin.NewGRPCServer(runner, runners[len(runners)-1], loader.Files(), h.clientSDKVersions[name]))
 			if err != nil {
-				return ret, fmt.Errorf("Failed to check ruleset: %w", err)
+				return nil, fmt.Errorf("Failed to check ruleset: %w", err)
 			}
 		}
 	}
 
-	// In order to publish that the issue has been fixed,
-	// notify also the path where the past diagnostics were published.
-	for _, path := range h.diagsPaths {
-		ret[path] = []lsp.Diagnostic{}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
-	h.diagsPaths = []string{}
 
-	for _, runner := range runners {
-		for _, issue := range runner.LookupIssues() {
-			path := filepath.Join(h.rootDir, issue.Range.Filename)
-			h.diagsPaths = append(h.diagsPaths, path)
-
-			diag := lsp.Diagnostic{
-				Message:  issue.Message,
-				Severity: toLSPSeverity(issue.Rule.Severity()),
-				Range: lsp.Range{
-					Start: lsp.Position{Line: issue.Range.Start.Line - 1, Character: issue.Range.Start.Column - 1},
-					End:   lsp.Position{Line: issue.Range.End.Line - 1, Character: issue.Range.End.Column - 1},
-				},
+	// Custom rules and policies don't support autofixes
+	if !fix {
+		for _, runner := range runners {
+			if err := runner.RunCustomRules(); err != nil {
+				return nil, err
 			}
-
-			if ret[path] == nil {
-				ret[path] = []lsp.Diagnostic{diag}
-			} else {
-				ret[path] = append(ret[path], diag)
+			if err := runner.RunPolicies(mod.workspace.policies); err != nil {
+				return nil, err
 			}
 		}
 	}
 
-	return ret, nil
+	return runners, nil
+}
+
+// restartCrashedPlugin restarts the plugin if the error is caused by a crash or hang of the plugin.
+// Plugins are shared between connections, so the restarted process is used by all handlers.
+func (h *handler) restartCrashedPlugin(err error) {
+	var callErr *plugin.CallError
+	if !errors.As(err, &callErr) {
+		return
+	}
+	ruleset, exists := h.plugin.RuleSets[callErr.Plugin]
+	if !exists {
+		return
+	}
+	if err := ruleset.Restart(); err != nil {
+		log.Printf(`Failed to restart "%s" plugin: %s`, callErr.Plugin, err)
+	}
+}
+
+// publishDiagnostics notifies the client of the diagnostics.
+// If the client pulls diagnostics, it asks the client to pull them again instead,
+// because pushed diagnostics are shown separately from pulled ones.
+func (h *handler) publishDiagnostics(ctx context.Context, conn *jsonrpc2.Conn, diagnostics map[string][]diagnostic) error {
+	if h.pullDiagnostics {
+		if h.refreshSupport {
+			// The client sends the response after the handler returns, so don't wait for it
+			go func() {
+				if err := conn.Call(context.Background(), "workspace/diagnostic/refresh", nil, nil); err != nil {
+					log.Printf("Failed to request workspace/diagnostic/refresh: %s", err)
+				}
+			}()
+		}
+		return nil
+	}
+
+	log.Printf("Notify textDocument/publishDiagnostics with %#v", diagnostics)
+	// Publish in a stable order so that clients and tests see the same sequence
+	for _, path := range slices.Sorted(maps.Keys(diagnostics)) {
+		err := conn.Notify(
+			ctx,
+			"textDocument/publishDiagnostics",
+			publishDiagnosticsParams{
+				URI:         pathToURI(path),
+				Diagnostics: diagnostics[path],
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("Failed to notify textDocument/publishDiagnostics: %s", err)
+		}
+	}
+	return nil
 }
 
 func uriToPath(uri lsp.DocumentURI) (string, error) {
@@ -293,6 +619,13 @@ func pathToURI(path string) lsp.DocumentURI {
 	return lsp.DocumentURI("file://" + head + rest)
 }
 
+func toLSPRange(rng hcl.Range) lsp.Range {
+	return lsp.Range{
+		Start: lsp.Position{Line: rng.Start.Line - 1, Character: rng.Start.Column - 1},
+		End:   lsp.Position{Line: rng.End.Line - 1, Character: rng.End.Column - 1},
+	}
+}
+
 func toLSPSeverity(severity tflint.Severity) lsp.DiagnosticSeverity {
 	switch severity {
 	case sdk.ERROR:
@@ -2,20 +2,169 @@ package langserver
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"time"
 
 	lsp "github.com/sourcegraph/go-lsp"
 	"github.com/sourcegraph/jsonrpc2"
 )
 
-func initialize(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
-	return lsp.InitializeResult{
-		Capabilities: lsp.ServerCapabilities{
-			TextDocumentSync: &lsp.TextDocumentSyncOptionsOrKind{
-				Options: &lsp.TextDocumentSyncOptions{
-					OpenClose: true,
-					Change:    lsp.TDSKFull,
+// initializationOptions are server-specific options passed by the client.
+type initializationOptions struct {
+	// DebounceMs is the idle time in milliseconds to wait for subsequent
+	// textDocument/didChange notifications before inspecting.
+	DebounceMs *int `json:"debounceMs"`
+}
+
+// initializeResult is an InitializeResult in the LSP specification.
+// go-lsp does not support workspace capabilities of the server.
+type initializeResult struct {
+	Capabilities serverCapabilities `json:"capabilities"`
+}
+
+type serverCapabilities struct {
+	lsp.ServerCapabilities
+	CodeActionProvider *codeActionOptions           `json:"codeActionProvider,omitempty"`
+	DiagnosticProvider *diagnosticOptions           `json:"diagnosticProvider,omitempty"`
+	Workspace          *workspaceServerCapabilities `json:"workspace,omitempty"`
+}
+
+// codeActionOptions is a CodeActionOptions in the LSP specification.
+// go-lsp does not support resolveProvider.
+type codeActionOptions struct {
+	ResolveProvider bool `json:"resolveProvider"`
+}
+
+type diagnosticOptions struct {
+	InterFileDependencies bool `json:"interFileDependencies"`
+	WorkspaceDiagnostics  bool `json:"workspaceDiagnostics"`
+}
+
+type workspaceServerCapabilities struct {
+	WorkspaceFolders workspaceFoldersServerCapabilities `json:"workspaceFolders"`
+}
+
+type workspaceFoldersServerCapabilities struct {
+	Supported           bool `json:"supported"`
+	ChangeNotifications bool `json:"changeNotifications"`
+}
+
+// clientCapabilities is a part of ClientCapabilities in the LSP specification
+// that go-lsp does not support.
+type clientCapabilities struct {
+	TextDocument struct {
+		Diagnostic *struct{} `json:"diagnostic"`
+		CodeAction struct {
+			ResolveSupport struct {
+				Properties []string `json:"properties"`
+			} `json:"resolveSupport"`
+		} `json:"codeAction"`
+	} `json:"textDocument"`
+	Workspace struct {
+		Diagnostics struct {
+			RefreshSupport bool `json:"refreshSupport"`
+		} `json:"diagnostics"`
+	} `json:"workspace"`
+	Window struct {
+		WorkDoneProgress bool `json:"workDoneProgress"`
+	} `json:"window"`
+}
+
+func (h *handler) initialize(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	progressSupport := false
+	if req.Params != nil {
+		var params struct {
+			InitializationOptions *initializationOptions `json:"initializationOptions"`
+			WorkspaceFolders      []workspaceFolder      `json:"workspaceFolders"`
+			Capabilities          clientCapabilities     `json:"capabilities"`
+		}
+		if err := json.Unmarshal(*req.Params, &params); err != nil {
+			return nil, &jsonrpc2.Error{
+				Code:    jsonrpc2.CodeParseError,
+				Message: err.Error(),
+				Data:    req.Params,
+			}
+		}
+
+		if opts := params.InitializationOptions; opts != nil && opts.DebounceMs != nil {
+			h.debounce = time.Duration(*opts.DebounceMs) * time.Millisecond
+		}
+
+		h.mu.Lock()
+		h.pullDiagnostics = params.Capabilities.TextDocument.Diagnostic != nil
+		h.refreshSupport = params.Capabilities.Workspace.Diagnostics.RefreshSupport
+		h.codeActionResolveSupport = slices.Contains(params.Capabilities.TextDocument.CodeAction.ResolveSupport.Properties, "edit")
+		_, err := h.addWorkspaceFolders(params.WorkspaceFolders)
+		h.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		progressSupport = params.Capabilities.Window.WorkDoneProgress
+	}
+
+	// The server must not send requests until it responds to initialize,
+	// so progress created by the server is enabled after starting plugins.
+	// Rules in the configs can be validated only after plugins are started.
+	h.mu.Lock()
+	h.startPlugins(ctx, conn)
+	h.validateWorkspaces(ctx, conn, append([]*workspace{h.defaultWorkspace}, h.workspaces...))
+	h.progressSupport = progressSupport
+	h.mu.Unlock()
+
+	return initializeResult{
+		Capabilities: serverCapabilities{
+			ServerCapabilities: lsp.ServerCapabilities{
+				TextDocumentSync: &lsp.TextDocumentSyncOptionsOrKind{
+					Options: &lsp.TextDocumentSyncOptions{
+						OpenClose: true,
+						Change:    lsp.TDSKIncremental,
+						Save:      &lsp.SaveOptions{},
+					},
+				},
+				HoverProvider:      true,
+				CompletionProvider: &lsp.CompletionOptions{},
+				ExecuteCommandProvider: &lsp.ExecuteCommandOptions{
+					Commands: []string{fixAllCommand},
+				},
+			},
+			CodeActionProvider: &codeActionOptions{ResolveProvider: true},
+			DiagnosticProvider: &diagnosticOptions{
+				InterFileDependencies: true,
+				WorkspaceDiagnostics:  true,
+			},
+			Workspace: &workspaceServerCapabilities{
+				WorkspaceFolders: workspaceFoldersServerCapabilities{
+					Supported:           true,
+					ChangeNotifications: true,
 				},
 			},
 		},
 	}, nil
 }
+
+// startPlugins starts plugins shared between connections if they have not been started.
+// If plugins fail to start, the failure is shown to the user and the server keeps running
+// without plugins, so that the user can see what went wrong in the editor.
+func (h *handler) startPlugins(ctx context.Context, conn *jsonrpc2.Conn) {
+	if !h.server.pluginStarted {
+		done := h.startProgress(ctx, conn, "Starting plugins")
+		err := h.server.startPlugins()
+		done()
+		if err != nil {
+			log.Printf("Failed to start plugins: %s", err)
+			showMessage(ctx, conn, lsp.MTError, fmt.Sprintf("Failed to start plugins; %s", err))
+		}
+	}
+	h.plugin = h.server.plugin
+	h.clientSDKVersions = h.server.clientSDKVersions
+}
+
+// showMessage shows the message to the user with window/showMessage.
+func showMessage(ctx context.Context, conn *jsonrpc2.Conn, typ lsp.MessageType, message string) {
+	if err := conn.Notify(ctx, "window/showMessage", lsp.ShowMessageParams{Type: typ, Message: message}); err != nil {
+		log.Printf("Failed to show message: %s", err)
+	}
+}
@@ -0,0 +1,48 @@
+package langserver
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	lsp "github.com/sourcegraph/go-lsp"
+)
+
+// positionAt converts the byte offset in the source into a position in the LSP.
+// Characters are counted in UTF-16 code units.
+func positionAt(src []byte, offset int) lsp.Position {
+	pos := lsp.Position{}
+	lineStart := 0
+	for i := 0; i < offset; i++ {
+		if src[i] == '\n' {
+			pos.Line++
+			lineStart = i + 1
+		}
+	}
+	pos.Character = len(utf16.Encode([]rune(string(src[lineStart:offset]))))
+	return pos
+}
+
+// offsetAt converts the position in the LSP into a byte offset in the source.
+// If the character is greater than the line length, it defaults back to the end of the line.
+func offsetAt(src []byte, pos lsp.Position) (int, error) {
+	offset := 0
+	for line := 0; line < pos.Line; line++ {
+		idx := bytes.IndexByte(src[offset:], '\n')
+		if idx < 0 {
+			return 0, fmt.Errorf("line %d is out of range", pos.Line)
+		}
+		offset += idx + 1
+	}
+
+	for character := 0; character < pos.Character && offset < len(src); {
+		r, size := utf8.DecodeRune(src[offset:])
+		if r == '\n' || (r == '\r' && bytes.HasPrefix(src[offset:], []byte("\r\n"))) {
+			break
+		}
+		character += len(utf16.Encode([]rune{r}))
+		offset += size
+	}
+	return offset, nil
+}
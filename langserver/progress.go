@@ -0,0 +1,111 @@
+package langserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// progressParams is a ProgressParams in the LSP specification.
+// go-lsp does not support work done progress.
+type progressParams struct {
+	Token any `json:"token"`
+	Value any `json:"value"`
+}
+
+type workDoneProgressBegin struct {
+	Kind    string `json:"kind"`
+	Title   string `json:"title"`
+	Message string `json:"message,omitempty"`
+}
+
+type workDoneProgressReport struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message,omitempty"`
+}
+
+type workDoneProgressEnd struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message,omitempty"`
+}
+
+// workDoneProgress reports the progress of a long-running operation with a token.
+// The first report begins the progress, and subsequent reports update the message.
+// All methods do nothing on a nil progress.
+type workDoneProgress struct {
+	conn  *jsonrpc2.Conn
+	token any
+	begun bool
+}
+
+type workDoneProgressKey struct{}
+
+// withWorkDoneProgress returns a context with the progress of the workDoneToken
+// passed in the request params. The progress is ended when the request is processed.
+func withWorkDoneProgress(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (context.Context, *workDoneProgress) {
+	if req.Params == nil {
+		return ctx, nil
+	}
+	var params struct {
+		WorkDoneToken json.RawMessage `json:"workDoneToken"`
+	}
+	// Params other than objects have no token
+	if err := json.Unmarshal(*req.Params, &params); err != nil || params.WorkDoneToken == nil {
+		return ctx, nil
+	}
+	progress := &workDoneProgress{conn: conn, token: params.WorkDoneToken}
+	return context.WithValue(ctx, workDoneProgressKey{}, progress), progress
+}
+
+// startProgress reports the message as a progress of the operation.
+// If the request has a workDoneToken, the message is reported with the token.
+// Otherwise, if the client supports progress created by the server, a new progress
+// is created. The returned function must be called when the operation is done.
+func (h *handler) startProgress(ctx context.Context, conn *jsonrpc2.Conn, message string) func() {
+	if progress, ok := ctx.Value(workDoneProgressKey{}).(*workDoneProgress); ok {
+		progress.report(ctx, message)
+		return func() {}
+	}
+	if !h.progressSupport {
+		return func() {}
+	}
+
+	h.progressCount++
+	token := fmt.Sprintf("tflint-%d", h.progressCount)
+	if err := conn.Call(ctx, "window/workDoneProgress/create", map[string]any{"token": token}, nil); err != nil {
+		log.Printf("Failed to create work done progress: %s", err)
+		return func() {}
+	}
+	progress := &workDoneProgress{conn: conn, token: token}
+	progress.report(ctx, message)
+	return func() { progress.end(ctx) }
+}
+
+func (p *workDoneProgress) report(ctx context.Context, message string) {
+	if p == nil {
+		return
+	}
+	if !p.begun {
+		p.begun = true
+		p.notify(ctx, workDoneProgressBegin{Kind: "begin", Title: "TFLint", Message: message})
+		return
+	}
+	p.notify(ctx, workDoneProgressReport{Kind: "report", Message: message})
+}
+
+// end ends the progress. If nothing has been reported, nothing is sent.
+func (p *workDoneProgress) end(ctx context.Context) {
+	if p == nil || !p.begun {
+		return
+	}
+	p.notify(ctx, workDoneProgressEnd{Kind: "end"})
+}
+
+func (p *workDoneProgress) notify(ctx context.Context, value any) {
+	if err := p.conn.Notify(ctx, "$/progress", progressParams{Token: p.token, Value: value}); err != nil {
+		log.Printf("Failed to notify progress: %s", err)
+	}
+}
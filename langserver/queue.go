@@ -0,0 +1,98 @@
+package langserver
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// codeRequestCancelled is the error code returned for requests canceled by $/cancelRequest.
+const codeRequestCancelled = -32800
+
+func requestCancelledError() *jsonrpc2.Error {
+	return &jsonrpc2.Error{Code: codeRequestCancelled, Message: "request was cancelled"}
+}
+
+// queueHandler processes requests one by one in the received order in other goroutines.
+// Unlike synchronous handlers, the connection keeps reading messages during processing,
+// so that $/cancelRequest and responses to requests sent by the server are received.
+type queueHandler struct {
+	handler jsonrpc2.Handler
+
+	mu sync.Mutex
+	// last is closed when the last queued request has been processed.
+	last    chan struct{}
+	cancels map[jsonrpc2.ID]context.CancelFunc
+}
+
+var _ jsonrpc2.Handler = (*queueHandler)(nil)
+
+func newQueueHandler(handler jsonrpc2.Handler) *queueHandler {
+	last := make(chan struct{})
+	close(last)
+	return &queueHandler{handler: handler, last: last, cancels: map[jsonrpc2.ID]context.CancelFunc{}}
+}
+
+func (q *queueHandler) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	// Cancellation is handled immediately without waiting for the queued requests
+	if req.Method == "$/cancelRequest" {
+		q.cancel(req)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	q.mu.Lock()
+	prev, done := q.last, make(chan struct{})
+	q.last = done
+	if !req.Notif {
+		q.cancels[req.ID] = cancel
+	}
+	q.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		defer cancel()
+		<-prev
+
+		if ctx.Err() != nil && !req.Notif {
+			if err := conn.ReplyWithError(ctx, req.ID, requestCancelledError()); err != nil {
+				log.Printf("Failed to reply to the canceled request: %s", err)
+			}
+		} else {
+			q.handler.Handle(ctx, conn, req)
+		}
+
+		q.mu.Lock()
+		if !req.Notif {
+			delete(q.cancels, req.ID)
+		}
+		q.mu.Unlock()
+	}()
+}
+
+// cancel cancels the context of the request specified by $/cancelRequest.
+// Requests that have already been processed are ignored.
+func (q *queueHandler) cancel(req *jsonrpc2.Request) {
+	if req.Params == nil {
+		return
+	}
+	var params struct {
+		ID jsonrpc2.ID `json:"id"`
+	}
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		log.Printf("Failed to parse $/cancelRequest: %s", err)
+		return
+	}
+	log.Printf("Received $/cancelRequest for %s", params.ID)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if cancel, exists := q.cancels[params.ID]; exists {
+		cancel()
+	}
+}
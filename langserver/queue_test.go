@@ -0,0 +1,79 @@
+package langserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+func Test_queueHandler_cancel(t *testing.T) {
+	var mu sync.Mutex
+	handled := []string{}
+	started := make(chan struct{})
+
+	handler := newQueueHandler(jsonrpc2.HandlerWithError(func(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (any, error) {
+		mu.Lock()
+		handled = append(handled, req.Method)
+		mu.Unlock()
+
+		if req.Method == "slow" {
+			close(started)
+			<-ctx.Done()
+			return nil, requestCancelledError()
+		}
+		return "ok", nil
+	}))
+
+	serverConn, clientConn := net.Pipe()
+	server := jsonrpc2.NewConn(context.Background(), jsonrpc2.NewPlainObjectStream(serverConn), handler)
+	defer server.Close()
+	client := jsonrpc2.NewConn(context.Background(), jsonrpc2.NewPlainObjectStream(clientConn), nil)
+	defer client.Close()
+
+	ctx := context.Background()
+	slow, err := client.DispatchCall(ctx, "slow", nil, jsonrpc2.PickID(jsonrpc2.ID{Num: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	queued, err := client.DispatchCall(ctx, "queued", nil, jsonrpc2.PickID(jsonrpc2.ID{Num: 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	// Cancel the queued request first, and then the in-flight request
+	for _, id := range []uint64{2, 1} {
+		if err := client.Notify(ctx, "$/cancelRequest", map[string]any{"id": id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, waiter := range []jsonrpc2.Waiter{slow, queued} {
+		err := waiter.Wait(ctx, nil)
+		var rpcErr *jsonrpc2.Error
+		if !errors.As(err, &rpcErr) || rpcErr.Code != codeRequestCancelled {
+			t.Fatalf("expected the request to be cancelled, but got %v", err)
+		}
+	}
+
+	// Requests after cancellation are processed as usual
+	var result string
+	if err := client.Call(ctx, "fast", nil, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result != "ok" {
+		t.Fatalf("expected ok, but got %s", result)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// The queued request is canceled before processing
+	if diff := cmp.Diff([]string{"slow", "fast"}, handled); diff != "" {
+		t.Error(diff)
+	}
+}
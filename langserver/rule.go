@@ -0,0 +1,160 @@
+package langserver
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+// ruleInfo is a rule shown in hovers and completions.
+type ruleInfo struct {
+	name    string
+	ruleset string
+	// rule is nil if the metadata of the rule is unknown.
+	// Plugins send the metadata only with issues, so it is known
+	// only after the rule emits issues.
+	rule             tflint.Rule
+	enabledByDefault bool
+	// defaultUnknown is true if whether the rule is enabled by default is unknown.
+	// Plugins do not send it, so enabledByDefault is meaningless for plugin rules.
+	defaultUnknown bool
+}
+
+// staticRule is an implementation of tflint.Rule for rules declared in the config.
+type staticRule struct {
+	name     string
+	severity tflint.Severity
+	link     string
+}
+
+var _ tflint.Rule = (*staticRule)(nil)
+
+func (r *staticRule) Name() string {
+	return r.name
+}
+
+func (r *staticRule) Severity() tflint.Severity {
+	return r.severity
+}
+
+func (r *staticRule) Link() string {
+	return r.link
+}
+
+// lookupRules returns all rules available in the workspace, keyed by name.
+func (h *handler) lookupRules(ws *workspace) (map[string]*ruleInfo, error) {
+	ret := map[string]*ruleInfo{}
+
+	for name, ruleset := range h.plugin.RuleSets {
+		ruleNames, err := ruleset.RuleNames()
+		if err != nil {
+			return ret, fmt.Errorf(`Failed to get rule names from "%s" plugin; %w`, name, err)
+		}
+		for _, ruleName := range ruleNames {
+			ret[ruleName] = &ruleInfo{name: ruleName, ruleset: name, rule: h.rules[ruleName], defaultUnknown: true}
+		}
+	}
+
+	for _, config := range ws.config.CustomRules {
+		// Severity is validated on loading, so errors are ignored
+		severity, _ := tflint.NewSeverity(config.Severity)
+		ret[config.Name] = &ruleInfo{
+			name:             config.Name,
+			ruleset:          "custom rules",
+			rule:             &staticRule{name: config.Name, severity: severity, link: config.Link},
+			enabledByDefault: config.Enabled,
+		}
+	}
+
+	if ws.policies != nil {
+		for _, rule := range ws.policies.Rules() {
+			ret[rule.Name()] = &ruleInfo{name: rule.Name(), ruleset: "policies", rule: rule, enabledByDefault: true}
+		}
+	}
+
+	return ret, nil
+}
+
+// sortedRuleNames returns the rule names in alphabetical order.
+func sortedRuleNames(rules map[string]*ruleInfo) []string {
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// markdown returns the description of the rule in Markdown.
+// The enabled state is resolved for the passed file relative to the module directory.
+// It is omitted if it depends on the default of the rule, which is unknown.
+func (r *ruleInfo) markdown(cfg *tflint.Config, filename string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s**\n\n", r.name)
+	fmt.Fprintf(&b, "- Ruleset: %s\n", r.ruleset)
+	if r.rule != nil {
+		fmt.Fprintf(&b, "- Severity: %s\n", r.rule.Severity())
+	} else {
+		b.WriteString("- Severity: Unknown until the rule reports an issue\n")
+	}
+	enabledFor := cfg.IsRuleEnabledFor(r.name, filename)
+	if !r.defaultUnknown || !enabledFor || cfg.IsRuleEnabled(r.name, true) == cfg.IsRuleEnabled(r.name, false) {
+		fmt.Fprintf(&b, "- Enabled: %t\n", cfg.IsRuleEnabled(r.name, r.enabledByDefault) && enabledFor)
+	}
+	if r.rule != nil && r.rule.Link() != "" {
+		fmt.Fprintf(&b, "- Link: %s\n", r.rule.Link())
+	}
+	return b.String()
+}
+
+// annotationPattern matches both tflint-ignore and tflint-ignore-file annotations.
+// Unlike the patterns in the tflint package, this also matches annotations without rules
+// so that rule names can be completed.
+var annotationPattern = regexp.MustCompile(`tflint-ignore(?:-file)?: ([^\n*/#]*)`)
+
+// annotationRule is a rule name in an annotation.
+// Start and end are byte offsets in the source.
+type annotationRule struct {
+	name  string
+	start int
+	end   int
+}
+
+// annotationRuleAt returns the rule name in the annotation at the offset.
+// If the offset is in the annotation but not on any rule name, an empty name
+// at the offset is returned. It returns false if the offset is outside of annotations.
+func annotationRuleAt(src []byte, offset int) (annotationRule, bool) {
+	lineStart := bytes.LastIndexByte(src[:offset], '\n') + 1
+	lineEnd := len(src)
+	if idx := bytes.IndexByte(src[offset:], '\n'); idx >= 0 {
+		lineEnd = offset + idx
+	}
+	line := src[lineStart:lineEnd]
+
+	for _, match := range annotationPattern.FindAllSubmatchIndex(line, -1) {
+		contentStart, contentEnd := lineStart+match[2], lineStart+match[3]
+		if offset < contentStart || offset > contentEnd {
+			continue
+		}
+
+		start := contentStart
+		for _, name := range strings.Split(string(src[contentStart:contentEnd]), ",") {
+			end := start + len(name)
+			nameStart := start + len(name) - len(strings.TrimLeft(name, " \t"))
+			nameEnd := start + len(strings.TrimRight(name, " \t\r"))
+			if offset >= nameStart && offset <= nameEnd && nameStart < nameEnd {
+				return annotationRule{name: string(src[nameStart:nameEnd]), start: nameStart, end: nameEnd}, true
+			}
+			if offset >= start && offset <= end {
+				return annotationRule{start: offset, end: offset}, true
+			}
+			start = end + 1
+		}
+	}
+
+	return annotationRule{}, false
+}
@@ -0,0 +1,227 @@
+package langserver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	sdk "github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+func Test_annotationRuleAt(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    string
+		cursor string
+		want   annotationRule
+		ok     bool
+	}{
+		{
+			name:   "rule name",
+			src:    "# tflint-ignore: aws_instance_invalid_type\n",
+			cursor: "aws_inst",
+			want:   annotationRule{name: "aws_instance_invalid_type", start: 17, end: 42},
+			ok:     true,
+		},
+		{
+			name:   "end of rule name",
+			src:    "# tflint-ignore: aws_inst\n",
+			cursor: "aws_inst",
+			want:   annotationRule{name: "aws_inst", start: 17, end: 25},
+			ok:     true,
+		},
+		{
+			name:   "second rule name",
+			src:    "// tflint-ignore: rule_a, rule_b\n",
+			cursor: "rule_b",
+			want:   annotationRule{name: "rule_b", start: 26, end: 32},
+			ok:     true,
+		},
+		{
+			name:   "after comma",
+			src:    "# tflint-ignore: rule_a, \n",
+			cursor: "rule_a, ",
+			want:   annotationRule{start: 25, end: 25},
+			ok:     true,
+		},
+		{
+			name:   "empty annotation",
+			src:    "resource \"null_resource\" \"foo\" {}\n# tflint-ignore-file: ",
+			cursor: "tflint-ignore-file: ",
+			want:   annotationRule{start: 56, end: 56},
+			ok:     true,
+		},
+		{
+			name:   "block comment",
+			src:    "/* tflint-ignore: rule_a */",
+			cursor: "rule_a",
+			want:   annotationRule{name: "rule_a", start: 18, end: 24},
+			ok:     true,
+		},
+		{
+			name:   "outside of annotation",
+			src:    "# tflint-ignore: rule_a\nfoo = 1\n",
+			cursor: "foo",
+			ok:     false,
+		},
+		{
+			name:   "annotation keyword",
+			src:    "# tflint-ignore: rule_a\n",
+			cursor: "tflint",
+			ok:     false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			offset := strings.Index(test.src, test.cursor) + len(test.cursor)
+
+			got, ok := annotationRuleAt([]byte(test.src), offset)
+			if ok != test.ok {
+				t.Fatalf("expected %t, got %t", test.ok, ok)
+			}
+			if diff := cmp.Diff(test.want, got, cmp.AllowUnexported(annotationRule{})); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func Test_ruleInfo_markdown(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   *ruleInfo
+		config *tflint.Config
+		want   string
+	}{
+		{
+			name: "known rule",
+			rule: &ruleInfo{
+				name:             "aws_instance_invalid_type",
+				ruleset:          "aws",
+				rule:             &staticRule{name: "aws_instance_invalid_type", severity: sdk.ERROR, link: "https://example.com"},
+				enabledByDefault: true,
+			},
+			config: tflint.EmptyConfig(),
+			want: `**aws_instance_invalid_type**
+
+- Ruleset: aws
+- Severity: Error
+- Enabled: true
+- Link: https://example.com
+`,
+		},
+		{
+			name: "unknown metadata",
+			rule: &ruleInfo{
+				name:             "aws_instance_invalid_type",
+				ruleset:          "aws",
+				enabledByDefault: true,
+			},
+			config: tflint.EmptyConfig(),
+			want: `**aws_instance_invalid_type**
+
+- Ruleset: aws
+- Severity: Unknown until the rule reports an issue
+- Enabled: true
+`,
+		},
+		{
+			name: "disabled for the file",
+			rule: &ruleInfo{
+				name:             "aws_instance_invalid_type",
+				ruleset:          "aws",
+				enabledByDefault: true,
+			},
+			config: &tflint.Config{
+				Rules: map[string]*tflint.RuleConfig{
+					"aws_instance_invalid_type": {
+						Name:         "aws_instance_invalid_type",
+						Enabled:      true,
+						ExcludePaths: []string{"*.tf"},
+					},
+				},
+			},
+			want: `**aws_instance_invalid_type**
+
+- Ruleset: aws
+- Severity: Unknown until the rule reports an issue
+- Enabled: false
+`,
+		},
+		{
+			name: "unknown default",
+			rule: &ruleInfo{
+				name:           "aws_instance_invalid_type",
+				ruleset:        "aws",
+				defaultUnknown: true,
+			},
+			config: tflint.EmptyConfig(),
+			want: `**aws_instance_invalid_type**
+
+- Ruleset: aws
+- Severity: Unknown until the rule reports an issue
+`,
+		},
+		{
+			name: "unknown default with rule block",
+			rule: &ruleInfo{
+				name:           "aws_instance_invalid_type",
+				ruleset:        "aws",
+				defaultUnknown: true,
+			},
+			config: &tflint.Config{
+				Rules: map[string]*tflint.RuleConfig{
+					"aws_instance_invalid_type": {Name: "aws_instance_invalid_type", Enabled: true},
+				},
+			},
+			want: `**aws_instance_invalid_type**
+
+- Ruleset: aws
+- Severity: Unknown until the rule reports an issue
+- Enabled: true
+`,
+		},
+		{
+			name: "unknown default with disabled_by_default",
+			rule: &ruleInfo{
+				name:           "aws_instance_invalid_type",
+				ruleset:        "aws",
+				defaultUnknown: true,
+			},
+			config: &tflint.Config{DisabledByDefault: true},
+			want: `**aws_instance_invalid_type**
+
+- Ruleset: aws
+- Severity: Unknown until the rule reports an issue
+- Enabled: false
+`,
+		},
+		{
+			name: "disabled by default",
+			rule: &ruleInfo{
+				name:             "custom",
+				ruleset:          "custom rules",
+				rule:             &staticRule{name: "custom", severity: sdk.WARNING},
+				enabledByDefault: false,
+			},
+			config: tflint.EmptyConfig(),
+			want: `**custom**
+
+- Ruleset: custom rules
+- Severity: Warning
+- Enabled: false
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.rule.markdown(test.config, "main.tf")
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package langserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	lsp "github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/spf13/afero"
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+// codeAction is a CodeAction literal in the LSP specification.
+// go-lsp only supports the Command literal as a result of textDocument/codeAction.
+type codeAction struct {
+	Title       string             `json:"title"`
+	Kind        lsp.CodeActionKind `json:"kind,omitempty"`
+	Diagnostics []diagnostic       `json:"diagnostics,omitempty"`
+	IsPreferred bool               `json:"isPreferred,omitempty"`
+	Edit        *lsp.WorkspaceEdit `json:"edit,omitempty"`
+	Data        *codeActionData    `json:"data,omitempty"`
+}
+
+// codeActionData identifies the issue to be fixed when the code action is resolved.
+type codeActionData struct {
+	URI        lsp.DocumentURI `json:"uri"`
+	Diagnostic lsp.Diagnostic  `json:"diagnostic"`
+}
+
+func (h *handler) textDocumentCodeAction(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidRequest,
+			Message: "request params are nil",
+		}
+	}
+
+	var params lsp.CodeActionParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeParseError,
+			Message: err.Error(),
+			Data:    req.Params,
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	path, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	mod := h.moduleFor(path)
+
+	actions := []codeAction{}
+	for _, diag := range params.Context.Diagnostics {
+		issue := mod.lookupIssue(path, diag)
+		if issue == nil {
+			continue
+		}
+
+		if issue.Fixable {
+			action := codeAction{
+				Title:       fmt.Sprintf(`Fix "%s"`, issue.Rule.Name()),
+				Kind:        lsp.CAKQuickFix,
+				Diagnostics: []diagnostic{toDiagnostic(mod.dir, issue)},
+				IsPreferred: true,
+			}
+			_, cached := mod.fixEdits[issue]
+			if h.codeActionResolveSupport && !cached {
+				// Clients request code actions on cursor moves, so running the rule
+				// is deferred until the client resolves the code action.
+				action.Data = &codeActionData{URI: params.TextDocument.URI, Diagnostic: diag}
+				actions = append(actions, action)
+			} else {
+				edit, err := h.fixEdit(ctx, mod, issue)
+				if err != nil {
+					return nil, err
+				}
+				if edit != nil {
+					action.Edit = edit
+					actions = append(actions, action)
+				}
+			}
+		}
+
+		// Only tflint-ignore-file is supported in JSON, and it cannot be inserted as a comment
+		if strings.HasSuffix(issue.Range.Filename, ".json") || issue.Source == nil {
+			continue
+		}
+		uri := pathToURI(filepath.Join(mod.dir, issue.Range.Filename))
+
+		lineEdit, err := ignoreLineEdit(issue)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, codeAction{
+			Title:       fmt.Sprintf(`Ignore "%s" on this line`, issue.Rule.Name()),
+			Kind:        lsp.CAKQuickFix,
+			Diagnostics: []diagnostic{toDiagnostic(mod.dir, issue)},
+			Edit:        &lsp.WorkspaceEdit{Changes: map[string][]lsp.TextEdit{string(uri): {lineEdit}}},
+		})
+
+		fileEdit, err := ignoreFileEdit(issue)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, codeAction{
+			Title:       fmt.Sprintf(`Ignore "%s" in this file`, issue.Rule.Name()),
+			Kind:        lsp.CAKQuickFix,
+			Diagnostics: []diagnostic{toDiagnostic(mod.dir, issue)},
+			Edit:        &lsp.WorkspaceEdit{Changes: map[string][]lsp.TextEdit{string(uri): {fileEdit}}},
+		})
+	}
+
+	return actions, nil
+}
+
+func (h *handler) codeActionResolve(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidRequest,
+			Message: "request params are nil",
+		}
+	}
+
+	var action codeAction
+	if err := json.Unmarshal(*req.Params, &action); err != nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeParseError,
+			Message: err.Error(),
+			Data:    req.Params,
+		}
+	}
+	if action.Data == nil {
+		return action, nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	path, err := uriToPath(action.Data.URI)
+	if err != nil {
+		return nil, err
+	}
+	mod := h.moduleFor(path)
+
+	issue := mod.lookupIssue(path, action.Data.Diagnostic)
+	if issue == nil {
+		// The issue has gone since the code action was returned
+		log.Printf("Issue to be fixed is not found in %s", path)
+		return action, nil
+	}
+	action.Edit, err = h.fixEdit(ctx, mod, issue)
+	if err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+// lookupIssue returns the issue corresponding to the diagnostic published by the last inspection.
+func (m *module) lookupIssue(path string, diag lsp.Diagnostic) *tflint.Issue {
+	for _, issue := range m.issues[path] {
+		if toLSPRange(issue.Range) == diag.Range && issue.Message == diag.Message && (diag.Code == "" || diag.Code == issue.Rule.Name()) {
+			return issue
+		}
+	}
+	return nil
+}
+
+// fixEdit runs the rule that emitted the issue with autofixes enabled,
+// and returns the changes as a workspace edit. Other issues of the rule are
+// filtered out so that only the fix for the passed issue is applied.
+// The edit is cached until the next inspection of the module.
+func (h *handler) fixEdit(ctx context.Context, mod *module, issue *tflint.Issue) (*lsp.WorkspaceEdit, error) {
+	if edit, cached := mod.fixEdits[issue]; cached {
+		return edit, nil
+	}
+
+	filter := func(i *tflint.Issue) bool {
+		return i.Rule.Name() == issue.Rule.Name() && i.Range == issue.Range
+	}
+	runners, err := h.check(ctx, mod, true, []string{issue.Rule.Name()}, filter)
+	if err != nil {
+		return nil, err
+	}
+	edit, err := h.changesEdit(mod, runners)
+	if err != nil {
+		return nil, err
+	}
+	// If the module has been changed since the last inspection, the edit may be outdated soon
+	if mod.inspected {
+		mod.fixEdits[issue] = edit
+	}
+	return edit, nil
+}
+
+// changesEdit returns the changes applied by autofixes as a workspace edit.
+// The runners are the result of check, and the changes are looked up from the root module runner.
+// It returns nil if no files are changed.
+func (h *handler) changesEdit(mod *module, runners []*tflint.Runner) (*lsp.WorkspaceEdit, error) {
+	changes := map[string][]lsp.TextEdit{}
+	for filename, src := range runners[len(runners)-1].LookupChanges() {
+		path := filepath.Join(mod.dir, filename)
+		old, err := afero.ReadFile(h.fs, path)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read %s: %w", filename, err)
+		}
+		if bytes.Equal(old, src) {
+			continue
+		}
+		changes[string(pathToURI(path))] = []lsp.TextEdit{diffEdit(old, src)}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return &lsp.WorkspaceEdit{Changes: changes}, nil
+}
+
+// ignoreLineEdit returns an edit to ignore the issue with a tflint-ignore annotation.
+// If the line above already has an annotation, the rule is appended to it.
+func ignoreLineEdit(issue *tflint.Issue) (lsp.TextEdit, error) {
+	src := issue.Source
+	annotations, diags := tflint.NewAnnotations(issue.Range.Filename, &hcl.File{Bytes: src})
+	if diags.HasErrors() {
+		return lsp.TextEdit{}, diags
+	}
+
+	for _, annotation := range annotations {
+		annotation, ok := annotation.(*tflint.LineAnnotation)
+		if !ok || annotation.Token.Range.Start.Line != issue.Range.Start.Line-1 {
+			continue
+		}
+		lineStart := bytes.LastIndexByte(src[:annotation.Token.Range.Start.Byte], '\n') + 1
+		if len(bytes.TrimSpace(src[lineStart:annotation.Token.Range.Start.Byte])) > 0 {
+			// Not a whole-line comment
+			continue
+		}
+		if offset, ok := annotationContentEnd(annotation.Token.Bytes, "tflint-ignore:", annotation.Content); ok {
+			pos := positionAt(src, annotation.Token.Range.Start.Byte+offset)
+			return lsp.TextEdit{
+				Range:   lsp.Range{Start: pos, End: pos},
+				NewText: ", " + issue.Rule.Name(),
+			}, nil
+		}
+	}
+
+	lineStart := bytes.LastIndexByte(src[:issue.Range.Start.Byte], '\n') + 1
+	indentEnd := lineStart
+	for indentEnd < len(src) && (src[indentEnd] == ' ' || src[indentEnd] == '\t') {
+		indentEnd++
+	}
+	pos := lsp.Position{Line: issue.Range.Start.Line - 1, Character: 0}
+	return lsp.TextEdit{
+		Range:   lsp.Range{Start: pos, End: pos},
+		NewText: fmt.Sprintf("%s# tflint-ignore: %s%s", src[lineStart:indentEnd], issue.Rule.Name(), newLine(src)),
+	}, nil
+}
+
+// ignoreFileEdit returns an edit to ignore the issue with a tflint-ignore-file annotation.
+// If the file already has an annotation, the rule is appended to it.
+func ignoreFileEdit(issue *tflint.Issue) (lsp.TextEdit, error) {
+	src := issue.Source
+	annotations, diags := tflint.NewAnnotations(issue.Range.Filename, &hcl.File{Bytes: src})
+	if diags.HasErrors() {
+		return lsp.TextEdit{}, diags
+	}
+
+	for _, annotation := range annotations {
+		annotation, ok := annotation.(*tflint.FileAnnotation)
+		if !ok {
+			continue
+		}
+		if offset, ok := annotationContentEnd(annotation.Token.Bytes, "tflint-ignore-file:", annotation.Content); ok {
+			pos := positionAt(src, annotation.Token.Range.Start.Byte+offset)
+			return lsp.TextEdit{
+				Range:   lsp.Range{Start: pos, End: pos},
+				NewText: ", " + issue.Rule.Name(),
+			}, nil
+		}
+	}
+
+	pos := lsp.Position{Line: 0, Character: 0}
+	return lsp.TextEdit{
+		Range:   lsp.Range{Start: pos, End: pos},
+		NewText: fmt.Sprintf("# tflint-ignore-file: %s%s", issue.Rule.Name(), newLine(src)),
+	}, nil
+}
+
+// annotationContentEnd returns the byte offset of the end of the annotation content in the comment.
+func annotationContentEnd(comment []byte, prefix string, content string) (int, bool) {
+	start := bytes.Index(comment, []byte(prefix))
+	if start < 0 {
+		return 0, false
+	}
+	start += len(prefix)
+	idx := bytes.Index(comment[start:], []byte(content))
+	if idx < 0 {
+		return 0, false
+	}
+	return start + idx + len(content), true
+}
+
+// diffEdit returns a text edit that replaces the changed part of the old source with the new source.
+func diffEdit(old []byte, new []byte) lsp.TextEdit {
+	prefix := 0
+	for prefix < len(old) && prefix < len(new) && old[prefix] == new[prefix] {
+		prefix++
+	}
+	for prefix > 0 && prefix < len(old) && !utf8.RuneStart(old[prefix]) {
+		prefix--
+	}
+
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(new)-prefix && old[len(old)-1-suffix] == new[len(new)-1-suffix] {
+		suffix++
+	}
+	for suffix > 0 && !utf8.RuneStart(old[len(old)-suffix]) {
+		suffix--
+	}
+
+	return lsp.TextEdit{
+		Range: lsp.Range{
+			Start: positionAt(old, prefix),
+			End:   positionAt(old, len(old)-suffix),
+		},
+		NewText: string(new[prefix : len(new)-suffix]),
+	}
+}
+
+func newLine(src []byte) string {
+	if bytes.Contains(src, []byte("\r\n")) {
+		return "\r\n"
+	}
+	return "\n"
+}
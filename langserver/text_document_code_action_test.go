@@ -0,0 +1,193 @@
+package langserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	hcl "github.com/hashicorp/hcl/v2"
+	lsp "github.com/sourcegraph/go-lsp"
+	sdk "github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+type testRule struct {
+	link string
+}
+
+func (r *testRule) Name() string              { return "test_rule" }
+func (r *testRule) Severity() tflint.Severity { return sdk.ERROR }
+func (r *testRule) Link() string              { return r.link }
+
+func Test_ignoreLineEdit(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		start hcl.Pos
+		want  lsp.TextEdit
+	}{
+		{
+			name: "new annotation",
+			src: `resource "aws_instance" "foo" {
+  instance_type = "t1.2xlarge"
+}`,
+			start: hcl.Pos{Line: 2, Column: 19, Byte: 50},
+			want: lsp.TextEdit{
+				Range:   lsp.Range{Start: lsp.Position{Line: 1}, End: lsp.Position{Line: 1}},
+				NewText: "  # tflint-ignore: test_rule\n",
+			},
+		},
+		{
+			name: "append to existing annotation",
+			src: `resource "aws_instance" "foo" {
+  # tflint-ignore: other_rule # comment
+  instance_type = "t1.2xlarge"
+}`,
+			start: hcl.Pos{Line: 3, Column: 19, Byte: 90},
+			want: lsp.TextEdit{
+				Range:   lsp.Range{Start: lsp.Position{Line: 1, Character: 29}, End: lsp.Position{Line: 1, Character: 29}},
+				NewText: ", test_rule",
+			},
+		},
+		{
+			name: "trailing annotation",
+			src: `resource "aws_instance" "foo" { # tflint-ignore: other_rule
+  instance_type = "t1.2xlarge"
+}`,
+			start: hcl.Pos{Line: 2, Column: 19, Byte: 78},
+			want: lsp.TextEdit{
+				Range:   lsp.Range{Start: lsp.Position{Line: 1}, End: lsp.Position{Line: 1}},
+				NewText: "  # tflint-ignore: test_rule\n",
+			},
+		},
+		{
+			name:  "CRLF",
+			src:   "resource \"aws_instance\" \"foo\" {\r\n  instance_type = \"t1.2xlarge\"\r\n}",
+			start: hcl.Pos{Line: 2, Column: 19, Byte: 51},
+			want: lsp.TextEdit{
+				Range:   lsp.Range{Start: lsp.Position{Line: 1}, End: lsp.Position{Line: 1}},
+				NewText: "  # tflint-ignore: test_rule\r\n",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			issue := &tflint.Issue{
+				Rule:   &testRule{},
+				Range:  hcl.Range{Filename: "main.tf", Start: test.start, End: test.start},
+				Source: []byte(test.src),
+			}
+
+			got, err := ignoreLineEdit(issue)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func Test_ignoreFileEdit(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want lsp.TextEdit
+	}{
+		{
+			name: "new annotation",
+			src: `resource "aws_instance" "foo" {
+  instance_type = "t1.2xlarge"
+}`,
+			want: lsp.TextEdit{
+				Range:   lsp.Range{Start: lsp.Position{}, End: lsp.Position{}},
+				NewText: "# tflint-ignore-file: test_rule\n",
+			},
+		},
+		{
+			name: "append to existing annotation",
+			src: `# tflint-ignore-file: other_rule
+resource "aws_instance" "foo" {
+  instance_type = "t1.2xlarge"
+}`,
+			want: lsp.TextEdit{
+				Range:   lsp.Range{Start: lsp.Position{Character: 32}, End: lsp.Position{Character: 32}},
+				NewText: ", test_rule",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			issue := &tflint.Issue{
+				Rule:   &testRule{},
+				Range:  hcl.Range{Filename: "main.tf"},
+				Source: []byte(test.src),
+			}
+
+			got, err := ignoreFileEdit(issue)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func Test_diffEdit(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want lsp.TextEdit
+	}{
+		{
+			name: "replace",
+			old:  "foo\n// autofixed\nbar\n",
+			new:  "foo\n# autofixed\nbar\n",
+			want: lsp.TextEdit{
+				Range:   lsp.Range{Start: lsp.Position{Line: 1}, End: lsp.Position{Line: 1, Character: 2}},
+				NewText: "#",
+			},
+		},
+		{
+			name: "remove",
+			old:  "foo\nbar\nbaz\n",
+			new:  "foo\nbaz\n",
+			want: lsp.TextEdit{
+				Range:   lsp.Range{Start: lsp.Position{Line: 1, Character: 2}, End: lsp.Position{Line: 2, Character: 2}},
+				NewText: "",
+			},
+		},
+		{
+			name: "multibyte",
+			old:  `tags = "🍣日本"`,
+			new:  `tags = "🍣日本語"`,
+			want: lsp.TextEdit{
+				Range:   lsp.Range{Start: lsp.Position{Character: 12}, End: lsp.Position{Character: 12}},
+				NewText: "語",
+			},
+		},
+		{
+			name: "multibyte with a common prefix byte",
+			old:  `"あ"`,
+			new:  `"い"`,
+			want: lsp.TextEdit{
+				Range:   lsp.Range{Start: lsp.Position{Character: 1}, End: lsp.Position{Character: 2}},
+				NewText: "い",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := diffEdit([]byte(test.old), []byte(test.new))
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
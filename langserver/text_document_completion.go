@@ -0,0 +1,193 @@
+package langserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	lsp "github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/spf13/afero"
+)
+
+func (h *handler) textDocumentCompletion(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidRequest,
+			Message: "request params are nil",
+		}
+	}
+
+	var params lsp.CompletionParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeParseError,
+			Message: err.Error(),
+			Data:    req.Params,
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	path, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := afero.ReadFile(h.fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %w", path, err)
+	}
+	offset, err := offsetAt(src, params.Position)
+	if err != nil {
+		return nil, err
+	}
+	if h.isConfigFile(path) {
+		return h.configCompletion(path, src, offset)
+	}
+
+	// Rule names are completed only in annotations
+	ant, ok := annotationRuleAt(src, offset)
+	if !ok {
+		return lsp.CompletionList{Items: []lsp.CompletionItem{}}, nil
+	}
+
+	rules, err := h.lookupRules(h.moduleFor(path).workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	rng := lsp.Range{Start: positionAt(src, ant.start), End: positionAt(src, ant.end)}
+	items := []lsp.CompletionItem{
+		{
+			Label:    "all",
+			Kind:     lsp.CIKKeyword,
+			Detail:   "All rules",
+			TextEdit: &lsp.TextEdit{Range: rng, NewText: "all"},
+		},
+	}
+	for _, name := range sortedRuleNames(rules) {
+		items = append(items, lsp.CompletionItem{
+			Label:    name,
+			Kind:     lsp.CIKValue,
+			Detail:   rules[name].ruleset,
+			TextEdit: &lsp.TextEdit{Range: rng, NewText: name},
+		})
+	}
+
+	return lsp.CompletionList{Items: items}, nil
+}
+
+// ruleLabelPattern matches the line prefix in the label of a rule block.
+var ruleLabelPattern = regexp.MustCompile(`^\s*rule\s+"([^"]*)$`)
+
+// attributeNamePattern matches the line prefix in an attribute name.
+var attributeNamePattern = regexp.MustCompile(`^\s*([A-Za-z0-9_-]*)$`)
+
+// pluginBuiltinAttributes are attributes of plugin blocks handled by TFLint itself.
+var pluginBuiltinAttributes = []string{"enabled", "version", "source", "source_type", "signing_key", "timeout"}
+
+// configCompletion returns completions in the config file.
+// Rule names are completed in rule block labels, and attributes are completed in plugin blocks.
+func (h *handler) configCompletion(path string, src []byte, offset int) (lsp.CompletionList, error) {
+	ret := lsp.CompletionList{Items: []lsp.CompletionItem{}}
+	// JSON syntax is not supported
+	if strings.HasSuffix(path, ".json") {
+		return ret, nil
+	}
+
+	lineStart := bytes.LastIndexByte(src[:offset], '\n') + 1
+	prefix := src[lineStart:offset]
+
+	if match := ruleLabelPattern.FindSubmatch(prefix); match != nil {
+		rules, err := h.lookupRules(h.workspaceFor(path))
+		if err != nil {
+			return ret, err
+		}
+
+		rng := lsp.Range{Start: positionAt(src, offset-len(match[1])), End: positionAt(src, offset)}
+		for _, name := range sortedRuleNames(rules) {
+			ret.Items = append(ret.Items, lsp.CompletionItem{
+				Label:    name,
+				Kind:     lsp.CIKValue,
+				Detail:   rules[name].ruleset,
+				TextEdit: &lsp.TextEdit{Range: rng, NewText: name},
+			})
+		}
+		return ret, nil
+	}
+
+	match := attributeNamePattern.FindSubmatch(prefix)
+	if match == nil {
+		return ret, nil
+	}
+	// The file may be incomplete during editing, so errors are ignored
+	file, _ := hclsyntax.ParseConfig(src, path, hcl.InitialPos)
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return ret, nil
+	}
+	block := pluginBlockAt(body, offset)
+	if block == nil {
+		return ret, nil
+	}
+
+	type attribute struct {
+		name   string
+		detail string
+	}
+	attributes := []attribute{}
+	for _, name := range pluginBuiltinAttributes {
+		attributes = append(attributes, attribute{name: name, detail: "TFLint"})
+	}
+	if ruleset, exists := h.plugin.RuleSets[block.Labels[0]]; exists {
+		schema, err := ruleset.ConfigSchema()
+		if err != nil {
+			h.restartCrashedPlugin(err)
+			return ret, fmt.Errorf(`Failed to fetch config schema from "%s" plugin; %w`, block.Labels[0], err)
+		}
+		for _, attr := range schema.Attributes {
+			attributes = append(attributes, attribute{name: attr.Name, detail: fmt.Sprintf(`"%s" plugin`, block.Labels[0])})
+		}
+	}
+
+	rng := lsp.Range{Start: positionAt(src, offset-len(match[1])), End: positionAt(src, offset)}
+	for _, attr := range attributes {
+		// Attributes that are already declared are not offered
+		if _, exists := block.Body.Attributes[attr.name]; exists {
+			continue
+		}
+		ret.Items = append(ret.Items, lsp.CompletionItem{
+			Label:    attr.name,
+			Kind:     lsp.CIKProperty,
+			Detail:   attr.detail,
+			TextEdit: &lsp.TextEdit{Range: rng, NewText: attr.name + " = "},
+		})
+	}
+	return ret, nil
+}
+
+// pluginBlockAt returns the plugin block whose body directly contains the offset.
+func pluginBlockAt(body *hclsyntax.Body, offset int) *hclsyntax.Block {
+	for _, block := range body.Blocks {
+		if block.Type != "plugin" || len(block.Labels) == 0 {
+			continue
+		}
+		if offset <= block.OpenBraceRange.Start.Byte || offset > block.CloseBraceRange.Start.Byte {
+			continue
+		}
+		for _, inner := range block.Body.Blocks {
+			if offset > inner.OpenBraceRange.Start.Byte && offset <= inner.CloseBraceRange.Start.Byte {
+				return nil
+			}
+		}
+		return block
+	}
+	return nil
+}
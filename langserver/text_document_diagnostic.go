@@ -0,0 +1,120 @@
+package langserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+
+	lsp "github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// documentDiagnosticParams is a DocumentDiagnosticParams in the LSP specification.
+// go-lsp does not support pull diagnostics.
+type documentDiagnosticParams struct {
+	TextDocument     lsp.TextDocumentIdentifier `json:"textDocument"`
+	Identifier       string                     `json:"identifier,omitempty"`
+	PreviousResultID string                     `json:"previousResultId,omitempty"`
+}
+
+// fullDocumentDiagnosticReport is a report that contains all diagnostics of the document.
+type fullDocumentDiagnosticReport struct {
+	Kind     string       `json:"kind"`
+	ResultID string       `json:"resultId"`
+	Items    []diagnostic `json:"items"`
+}
+
+// unchangedDocumentDiagnosticReport is a report that tells the client
+// the diagnostics of the previous result are still valid.
+type unchangedDocumentDiagnosticReport struct {
+	Kind     string `json:"kind"`
+	ResultID string `json:"resultId"`
+}
+
+func (h *handler) textDocumentDiagnostic(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidRequest,
+			Message: "request params are nil",
+		}
+	}
+
+	var params documentDiagnosticParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeParseError,
+			Message: err.Error(),
+			Data:    req.Params,
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	path, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	var diagnostics []diagnostic
+	if h.isConfigFile(path) {
+		ret, err := h.validateConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		diagnostics = ret[path]
+	} else {
+		mod := h.moduleFor(path)
+		if err := h.inspectIfChanged(ctx, conn, mod); err != nil {
+			return nil, err
+		}
+		diagnostics = mod.diagnostics(path)
+	}
+
+	return newDocumentDiagnosticReport(diagnostics, params.PreviousResultID)
+}
+
+// inspectIfChanged inspects the module only if it has been changed since the last inspection.
+func (h *handler) inspectIfChanged(ctx context.Context, conn *jsonrpc2.Conn, mod *module) error {
+	if mod.inspected {
+		return nil
+	}
+	_, err := h.inspect(ctx, conn, mod)
+	return err
+}
+
+// diagnostics returns diagnostics of the file from the last inspection.
+func (m *module) diagnostics(path string) []diagnostic {
+	ret := []diagnostic{}
+	for _, issue := range m.issues[filepath.Clean(path)] {
+		ret = append(ret, toDiagnostic(m.dir, issue))
+	}
+	return ret
+}
+
+// newDocumentDiagnosticReport returns an unchanged report if the diagnostics
+// are the same as the previous result. Otherwise, it returns a full report.
+func newDocumentDiagnosticReport(diagnostics []diagnostic, previousResultID string) (any, error) {
+	resultID, err := diagnosticsResultID(diagnostics)
+	if err != nil {
+		return nil, err
+	}
+	if resultID == previousResultID {
+		return unchangedDocumentDiagnosticReport{Kind: "unchanged", ResultID: resultID}, nil
+	}
+	return fullDocumentDiagnosticReport{Kind: "full", ResultID: resultID, Items: diagnostics}, nil
+}
+
+// diagnosticsResultID returns the result ID of the diagnostics.
+// The ID is derived from the content so that the same diagnostics
+// always have the same ID, even across inspections.
+func diagnosticsResultID(diagnostics []diagnostic) (string, error) {
+	out, err := json.Marshal(diagnostics)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(out)
+	return hex.EncodeToString(sum[:8]), nil
+}
@@ -4,13 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
-	"path/filepath"
 
 	lsp "github.com/sourcegraph/go-lsp"
 	"github.com/sourcegraph/jsonrpc2"
 	"github.com/spf13/afero"
+	"github.com/terraform-linters/tflint/tflint"
 )
 
 func (h *handler) textDocumentDidChange(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
@@ -35,35 +34,83 @@ func (h *handler) textDocumentDidChange(ctx context.Context, conn *jsonrpc2.Conn
 		return nil, err
 	}
 
-	if err := h.chdir(filepath.Dir(changedPath)); err != nil {
+	// The in-flight inspection is outdated, so abort it before waiting for the lock
+	if !tflint.IsDefaultConfigFile(changedPath) {
+		h.abortInspection(changedPath)
+	}
+
+	h.mu.Lock()
+	if err := h.applyContentChanges(changedPath, params.ContentChanges); err != nil {
+		h.mu.Unlock()
 		return nil, err
 	}
+	// Config files are validated immediately because it is much faster than inspections.
+	// Changes are applied to workspaces on save.
+	if h.isConfigFile(changedPath) {
+		defer h.mu.Unlock()
+		if h.pullDiagnostics {
+			return nil, nil
+		}
+		diagnostics, err := h.validateConfig(changedPath)
+		if err != nil {
+			return nil, err
+		}
+		return nil, h.publishDiagnostics(ctx, conn, diagnostics)
+	}
+	mod := h.moduleFor(changedPath)
+	mod.inspected = false
+	pull := h.pullDiagnostics
+	h.mu.Unlock()
+
+	// In pull mode, the module is inspected when the client requests diagnostics
+	if pull {
+		return nil, nil
+	}
+	h.scheduleInspection(conn, mod)
+
+	return nil, nil
+}
 
-	for idx, contentChange := range params.ContentChanges {
-		if err := afero.WriteFile(h.fs, filepath.Base(changedPath), []byte(contentChange.Text), os.ModePerm); err != nil {
-			return nil, fmt.Errorf("Failed to synchronize contentChanges[%d].Text: %s", idx, err)
+// applyContentChanges applies the changes to the file in the overlay filesystem.
+// If a change has a range, only the range is replaced with the text. Otherwise,
+// the whole content is replaced.
+func (h *handler) applyContentChanges(path string, changes []lsp.TextDocumentContentChangeEvent) error {
+	for idx, contentChange := range changes {
+		src := []byte(contentChange.Text)
+		if contentChange.Range != nil {
+			current, err := afero.ReadFile(h.fs, path)
+			if err != nil {
+				return fmt.Errorf("Failed to read %s: %s", path, err)
+			}
+			src, err = applyRangeEdit(current, *contentChange.Range, contentChange.Text)
+			if err != nil {
+				return fmt.Errorf("Failed to apply contentChanges[%d]: %s", idx, err)
+			}
+		}
+
+		if err := afero.WriteFile(h.fs, path, src, os.ModePerm); err != nil {
+			return fmt.Errorf("Failed to synchronize contentChanges[%d].Text: %s", idx, err)
 		}
 	}
+	return nil
+}
 
-	diagnostics, err := h.inspect()
+func applyRangeEdit(src []byte, rng lsp.Range, text string) ([]byte, error) {
+	start, err := offsetAt(src, rng.Start)
 	if err != nil {
 		return nil, err
 	}
-
-	log.Printf("Notify textDocument/publishDiagnostics with %#v", diagnostics)
-	for path, diags := range diagnostics {
-		err = conn.Notify(
-			ctx,
-			"textDocument/publishDiagnostics",
-			lsp.PublishDiagnosticsParams{
-				URI:         pathToURI(path),
-				Diagnostics: diags,
-			},
-		)
-		if err != nil {
-			return nil, fmt.Errorf("Failed to notify textDocument/publishDiagnostics: %s", err)
-		}
+	end, err := offsetAt(src, rng.End)
+	if err != nil {
+		return nil, err
+	}
+	if start > end {
+		return nil, fmt.Errorf("invalid range: %d:%d-%d:%d", rng.Start.Line, rng.Start.Character, rng.End.Line, rng.End.Character)
 	}
 
-	return nil, nil
+	ret := make([]byte, 0, len(src)-(end-start)+len(text))
+	ret = append(ret, src[:start]...)
+	ret = append(ret, text...)
+	ret = append(ret, src[end:]...)
+	return ret, nil
 }
@@ -0,0 +1,84 @@
+package langserver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	lsp "github.com/sourcegraph/go-lsp"
+)
+
+func Test_applyRangeEdit(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		rng     lsp.Range
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "replace",
+			src:  "foo = 1\nbar = 2\n",
+			rng:  lsp.Range{Start: lsp.Position{Line: 1, Character: 6}, End: lsp.Position{Line: 1, Character: 7}},
+			text: "3",
+			want: "foo = 1\nbar = 3\n",
+		},
+		{
+			name: "insert lines",
+			src:  "foo = 1\n",
+			rng:  lsp.Range{Start: lsp.Position{Line: 1}, End: lsp.Position{Line: 1}},
+			text: "bar = 2\n",
+			want: "foo = 1\nbar = 2\n",
+		},
+		{
+			name: "remove lines",
+			src:  "foo = 1\nbar = 2\nbaz = 3\n",
+			rng:  lsp.Range{Start: lsp.Position{Line: 1}, End: lsp.Position{Line: 2}},
+			want: "foo = 1\nbaz = 3\n",
+		},
+		{
+			name: "multibyte",
+			src:  `tags = "🍣日本"`,
+			rng:  lsp.Range{Start: lsp.Position{Character: 10}, End: lsp.Position{Character: 12}},
+			text: "語",
+			want: `tags = "🍣語"`,
+		},
+		{
+			name: "character out of range in CRLF",
+			src:  "foo = 1\r\nbar = 2\r\n",
+			rng:  lsp.Range{Start: lsp.Position{Line: 0, Character: 7}, End: lsp.Position{Line: 0, Character: 100}},
+			text: "0",
+			want: "foo = 10\r\nbar = 2\r\n",
+		},
+		{
+			name:    "line out of range",
+			src:     "foo = 1\n",
+			rng:     lsp.Range{Start: lsp.Position{Line: 2}, End: lsp.Position{Line: 2}},
+			wantErr: true,
+		},
+		{
+			name:    "reversed range",
+			src:     "foo = 1\n",
+			rng:     lsp.Range{Start: lsp.Position{Character: 2}, End: lsp.Position{Character: 1}},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := applyRangeEdit([]byte(test.src), test.rng, test.text)
+			if err != nil {
+				if !test.wantErr {
+					t.Fatal(err)
+				}
+				return
+			}
+			if test.wantErr {
+				t.Fatal("an error is expected, but got nil")
+			}
+			if diff := cmp.Diff(test.want, string(got)); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
@@ -4,9 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
-	"path/filepath"
 
 	lsp "github.com/sourcegraph/go-lsp"
 	"github.com/sourcegraph/jsonrpc2"
@@ -30,38 +28,35 @@ func (h *handler) textDocumentDidOpen(ctx context.Context, conn *jsonrpc2.Conn,
 		}
 	}
 
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	openedPath, err := uriToPath(params.TextDocument.URI)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := h.chdir(filepath.Dir(openedPath)); err != nil {
-		return nil, err
+	if err := afero.WriteFile(h.fs, openedPath, []byte(params.TextDocument.Text), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("Failed to synchronize TextDocument.Text: %s", err)
 	}
 
-	if err := afero.WriteFile(h.fs, filepath.Base(openedPath), []byte(params.TextDocument.Text), os.ModePerm); err != nil {
-		return nil, fmt.Errorf("Failed to synchronize TextDocument.Text: %s", err)
+	// In pull mode, the client requests diagnostics after opening, so inspections are deferred
+	if h.pullDiagnostics {
+		if !h.isConfigFile(openedPath) {
+			h.moduleFor(openedPath).inspected = false
+		}
+		return nil, nil
 	}
 
-	diagnostics, err := h.inspect()
+	var diagnostics map[string][]diagnostic
+	if h.isConfigFile(openedPath) {
+		diagnostics, err = h.validateConfig(openedPath)
+	} else {
+		diagnostics, err = h.inspect(ctx, conn, h.moduleFor(openedPath))
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	log.Printf("Notify textDocument/publishDiagnostics with %#v", diagnostics)
-	for path, diags := range diagnostics {
-		err = conn.Notify(
-			ctx,
-			"textDocument/publishDiagnostics",
-			lsp.PublishDiagnosticsParams{
-				URI:         pathToURI(path),
-				Diagnostics: diags,
-			},
-		)
-		if err != nil {
-			return nil, fmt.Errorf("Failed to notify textDocument/publishDiagnostics: %s", err)
-		}
-	}
-
-	return nil, nil
+	return nil, h.publishDiagnostics(ctx, conn, diagnostics)
 }
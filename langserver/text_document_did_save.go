@@ -0,0 +1,63 @@
+package langserver
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+
+	lsp "github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+func (h *handler) textDocumentDidSave(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidRequest,
+			Message: "request params are nil",
+		}
+	}
+
+	var params lsp.DidSaveTextDocumentParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeParseError,
+			Message: err.Error(),
+			Data:    req.Params,
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	savedPath, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	// Terraform files are inspected on changes, so only config files are handled here
+	if !h.isConfigFile(savedPath) {
+		return nil, nil
+	}
+
+	// Reload the config without waiting for workspace/didChangeWatchedFiles.
+	// If the config is invalid, keep the current config and report the errors.
+	diagnostics, err := h.validateConfig(savedPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, diag := range diagnostics[savedPath] {
+		if diag.Severity == lsp.Error {
+			return nil, h.publishDiagnostics(ctx, conn, diagnostics)
+		}
+	}
+
+	if err := h.reloadWorkspaces(); err != nil {
+		return nil, err
+	}
+	inspected, err := h.inspectAll(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	maps.Copy(diagnostics, inspected)
+
+	return nil, h.publishDiagnostics(ctx, conn, diagnostics)
+}
@@ -0,0 +1,129 @@
+package langserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	lsp "github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/spf13/afero"
+)
+
+// hover is a Hover in the LSP specification.
+// go-lsp does not support MarkupContent.
+type hover struct {
+	Contents markupContent `json:"contents"`
+	Range    *lsp.Range    `json:"range,omitempty"`
+}
+
+type markupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+func (h *handler) textDocumentHover(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidRequest,
+			Message: "request params are nil",
+		}
+	}
+
+	var params lsp.TextDocumentPositionParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeParseError,
+			Message: err.Error(),
+			Data:    req.Params,
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	path, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	mod := h.moduleFor(path)
+	filename, err := filepath.Rel(mod.dir, path)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := h.lookupRules(mod.workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rule names in annotations
+	src, err := afero.ReadFile(h.fs, path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %s: %w", path, err)
+	}
+	offset, err := offsetAt(src, params.Position)
+	if err != nil {
+		return nil, err
+	}
+	if ant, ok := annotationRuleAt(src, offset); ok {
+		rule, exists := rules[ant.name]
+		if !exists {
+			return nil, nil
+		}
+		rng := lsp.Range{Start: positionAt(src, ant.start), End: positionAt(src, ant.end)}
+		return hover{
+			Contents: markupContent{Kind: "markdown", Value: rule.markdown(mod.workspace.config, filename)},
+			Range:    &rng,
+		}, nil
+	}
+
+	// Diagnostics
+	contents := []string{}
+	var rng *lsp.Range
+	for _, issue := range mod.issues[path] {
+		issueRange := toLSPRange(issue.Range)
+		if !rangeContains(issueRange, params.Position) {
+			continue
+		}
+
+		rule, exists := rules[issue.Rule.Name()]
+		if !exists {
+			rule = &ruleInfo{name: issue.Rule.Name(), ruleset: "unknown", enabledByDefault: true}
+		}
+		// The metadata in the issue is always the latest
+		rule.rule = issue.Rule
+		// The rule is enabled by default if it reported the issue without being enabled in the config
+		rule.enabledByDefault, rule.defaultUnknown = true, false
+
+		contents = append(contents, rule.markdown(mod.workspace.config, filename))
+		if rng == nil {
+			rng = &issueRange
+		}
+	}
+	if len(contents) == 0 {
+		return nil, nil
+	}
+
+	return hover{
+		Contents: markupContent{Kind: "markdown", Value: strings.Join(contents, "\n---\n\n")},
+		Range:    rng,
+	}, nil
+}
+
+// rangeContains returns true if the position is in the range.
+// The end position is inclusive so that hovering on the end of the range works.
+func rangeContains(rng lsp.Range, pos lsp.Position) bool {
+	if pos.Line < rng.Start.Line || pos.Line > rng.End.Line {
+		return false
+	}
+	if pos.Line == rng.Start.Line && pos.Character < rng.Start.Character {
+		return false
+	}
+	if pos.Line == rng.End.Line && pos.Character > rng.End.Character {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,321 @@
+package langserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	lsp "github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/spf13/afero"
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+// workspaceFolder is a WorkspaceFolder in the LSP specification.
+// go-lsp does not support workspace folders.
+type workspaceFolder struct {
+	URI  lsp.DocumentURI `json:"uri"`
+	Name string          `json:"name"`
+}
+
+type didChangeWorkspaceFoldersParams struct {
+	Event struct {
+		Added   []workspaceFolder `json:"added"`
+		Removed []workspaceFolder `json:"removed"`
+	} `json:"event"`
+}
+
+// workspace is a workspace folder opened by the client.
+// Each workspace has its own TFLint config, which is loaded from the folder.
+// The default workspace has no root and is used for files outside of any folder.
+type workspace struct {
+	root     string
+	config   *tflint.Config
+	policies *tflint.Policies
+}
+
+// module is a Terraform module inspected by the server.
+// Diagnostics are published and cleared per module, so inspecting a module
+// doesn't affect diagnostics of other modules.
+type module struct {
+	dir        string
+	workspace  *workspace
+	diagsPaths []string
+	issues     map[string]tflint.Issues
+	// fixEdits caches edits of autofixes for issues of the last inspection.
+	fixEdits map[*tflint.Issue]*lsp.WorkspaceEdit
+	// inspected is false if the module has been changed since the last inspection.
+	// It is used to inspect modules on demand when the client pulls diagnostics.
+	inspected bool
+
+	inspectTimer     *time.Timer
+	cancelInspection context.CancelFunc
+}
+
+// contains returns true if the path is in the workspace folder.
+func (w *workspace) contains(path string) bool {
+	if w.root == "" {
+		return false
+	}
+	rel, err := filepath.Rel(w.root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// contains returns true if the path is in the module directory.
+func (m *module) contains(path string) bool {
+	rel, err := filepath.Rel(m.dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// newWorkspace returns a new workspace for the folder.
+// If the config path is relative, it is resolved from the folder.
+func (h *handler) newWorkspace(root string) (*workspace, error) {
+	var fs afero.Fs = afero.NewOsFs()
+	if root != "" {
+		fs = newDirFs(fs, root)
+	}
+	cfg, err := loadConfig(afero.Afero{Fs: fs}, h.configPath, h.cliConfig)
+	if err != nil {
+		return nil, err
+	}
+	return h.newWorkspaceWithConfig(root, cfg)
+}
+
+// newWorkspaceWithConfig returns a new workspace with the config.
+// Rules in the config are not validated here because workspaces may be created
+// before plugins are started. See validateRules.
+func (h *handler) newWorkspaceWithConfig(root string, cfg *tflint.Config) (*workspace, error) {
+	policyDir := cfg.PolicyDir
+	if root != "" && policyDir != "" && !filepath.IsAbs(policyDir) {
+		policyDir = filepath.Join(root, policyDir)
+	}
+	policies, err := tflint.LoadPolicies(policyDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &workspace{root: root, config: cfg, policies: policies}, nil
+}
+
+// validateRules checks that rules in the workspace config exist.
+// Rules of a plugin are unknown while the plugin is not running, such as before
+// plugins are started or after a config enabling a new plugin is saved,
+// so the validation is skipped in that case.
+func (h *handler) validateRules(ws *workspace) error {
+	for name, pluginCfg := range ws.config.Plugins {
+		if !pluginCfg.Enabled {
+			continue
+		}
+		if _, running := h.plugin.RuleSets[name]; !running {
+			log.Printf(`Skip validating rules because plugin "%s" is not running`, name)
+			return nil
+		}
+	}
+
+	rulesets := []tflint.RuleSet{}
+	for _, ruleset := range h.plugin.RuleSets {
+		rulesets = append(rulesets, ruleset)
+	}
+	if ws.policies != nil {
+		rulesets = append(rulesets, ws.policies)
+	}
+	return ws.config.ValidateRules(rulesets...)
+}
+
+// validateWorkspaces validates rules in the workspaces and shows errors to the user.
+// Invalid rules don't stop the server, so that the user can fix the config in the editor.
+func (h *handler) validateWorkspaces(ctx context.Context, conn *jsonrpc2.Conn, workspaces []*workspace) {
+	for _, ws := range workspaces {
+		if err := h.validateRules(ws); err != nil {
+			log.Printf("Invalid config: %s", err)
+			showMessage(ctx, conn, lsp.MTError, fmt.Sprintf("Invalid config; %s", err))
+		}
+	}
+}
+
+// reloadWorkspaces reloads the configs of all workspaces.
+// If any of the configs is invalid, no workspace is changed.
+func (h *handler) reloadWorkspaces() error {
+	workspaces := append([]*workspace{h.defaultWorkspace}, h.workspaces...)
+	reloaded := make([]*workspace, len(workspaces))
+	for i, ws := range workspaces {
+		newWs, err := h.newWorkspace(ws.root)
+		if err != nil {
+			return err
+		}
+		if err := h.validateRules(newWs); err != nil {
+			return err
+		}
+		reloaded[i] = newWs
+	}
+
+	for i, ws := range workspaces {
+		ws.config = reloaded[i].config
+		ws.policies = reloaded[i].policies
+	}
+	return nil
+}
+
+// addWorkspaceFolders adds workspaces for the folders and returns them.
+// Modules in the new folders are moved to the new workspaces.
+func (h *handler) addWorkspaceFolders(folders []workspaceFolder) ([]*workspace, error) {
+	added := []*workspace{}
+	for _, folder := range folders {
+		root, err := uriToPath(folder.URI)
+		if err != nil {
+			return added, err
+		}
+		ws, err := h.newWorkspace(root)
+		if err != nil {
+			return added, fmt.Errorf("Failed to load workspace folder %s: %w", root, err)
+		}
+		log.Printf("Add workspace folder: %s", root)
+		h.workspaces = append(h.workspaces, ws)
+		added = append(added, ws)
+	}
+
+	for _, mod := range h.modules {
+		mod.workspace = h.workspaceFor(mod.dir)
+	}
+	return added, nil
+}
+
+// removeWorkspaceFolders removes workspaces for the folders.
+// Modules in the removed folders are discarded and their diagnostics are returned as empty.
+func (h *handler) removeWorkspaceFolders(folders []workspaceFolder) (map[string][]diagnostic, error) {
+	ret := map[string][]diagnostic{}
+
+	for _, folder := range folders {
+		root, err := uriToPath(folder.URI)
+		if err != nil {
+			return ret, err
+		}
+
+		for idx, ws := range h.workspaces {
+			if ws.root != root {
+				continue
+			}
+			log.Printf("Remove workspace folder: %s", root)
+			h.workspaces = append(h.workspaces[:idx], h.workspaces[idx+1:]...)
+
+			for dir, mod := range h.modules {
+				if mod.workspace != ws {
+					continue
+				}
+				h.stopInspection(mod)
+				for _, path := range mod.diagsPaths {
+					ret[path] = []diagnostic{}
+				}
+				h.inspectMu.Lock()
+				delete(h.modules, dir)
+				h.inspectMu.Unlock()
+			}
+			break
+		}
+	}
+
+	return ret, nil
+}
+
+// workspaceFor returns the innermost workspace containing the path.
+// If no workspace contains it, the default workspace is returned.
+func (h *handler) workspaceFor(path string) *workspace {
+	ret := h.defaultWorkspace
+	for _, ws := range h.workspaces {
+		if ws.contains(path) && len(ws.root) > len(ret.root) {
+			ret = ws
+		}
+	}
+	return ret
+}
+
+// moduleFor returns the module containing the file.
+// The module directory is the nearest directory with Terraform configuration files,
+// up to the workspace folder.
+func (h *handler) moduleFor(path string) *module {
+	ws := h.workspaceFor(path)
+
+	dir := filepath.Dir(path)
+	for current := dir; ws.contains(current); current = filepath.Dir(current) {
+		if h.isModuleDir(current) {
+			dir = current
+			break
+		}
+		if current == ws.root || filepath.Dir(current) == current {
+			break
+		}
+	}
+
+	return h.moduleAt(dir, ws)
+}
+
+// moduleAt returns the module in the directory, creating it if needed.
+func (h *handler) moduleAt(dir string, ws *workspace) *module {
+	if mod, exists := h.modules[dir]; exists {
+		return mod
+	}
+	mod := &module{dir: dir, workspace: ws, diagsPaths: []string{}}
+	h.inspectMu.Lock()
+	h.modules[dir] = mod
+	h.inspectMu.Unlock()
+	return mod
+}
+
+func (h *handler) isModuleDir(dir string) bool {
+	files, err := afero.ReadDir(h.fs, dir)
+	if err != nil {
+		return false
+	}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(file.Name(), ".tf") || strings.HasSuffix(file.Name(), ".tf.json") {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *handler) workspaceDidChangeWorkspaceFolders(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidRequest,
+			Message: "request params are nil",
+		}
+	}
+
+	var params didChangeWorkspaceFoldersParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeParseError,
+			Message: err.Error(),
+			Data:    req.Params,
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	diagnostics, err := h.removeWorkspaceFolders(params.Event.Removed)
+	if err != nil {
+		return nil, err
+	}
+	added, err := h.addWorkspaceFolders(params.Event.Added)
+	if err != nil {
+		return nil, err
+	}
+	h.validateWorkspaces(ctx, conn, added)
+
+	return nil, h.publishDiagnostics(ctx, conn, diagnostics)
+}
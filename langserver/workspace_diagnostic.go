@@ -0,0 +1,170 @@
+package langserver
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"log"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	lsp "github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/spf13/afero"
+)
+
+// workspaceDiagnosticParams is a WorkspaceDiagnosticParams in the LSP specification.
+type workspaceDiagnosticParams struct {
+	Identifier        string             `json:"identifier,omitempty"`
+	PreviousResultIDs []previousResultID `json:"previousResultIds"`
+}
+
+type previousResultID struct {
+	URI   lsp.DocumentURI `json:"uri"`
+	Value string          `json:"value"`
+}
+
+type workspaceDiagnosticReport struct {
+	Items []any `json:"items"`
+}
+
+// workspaceFullDocumentDiagnosticReport is a full report for a file in the workspace.
+// Version is always null because files are not tied to the versions of open documents.
+type workspaceFullDocumentDiagnosticReport struct {
+	fullDocumentDiagnosticReport
+	URI     lsp.DocumentURI `json:"uri"`
+	Version *int            `json:"version"`
+}
+
+type workspaceUnchangedDocumentDiagnosticReport struct {
+	unchangedDocumentDiagnosticReport
+	URI     lsp.DocumentURI `json:"uri"`
+	Version *int            `json:"version"`
+}
+
+func (h *handler) workspaceDiagnostic(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidRequest,
+			Message: "request params are nil",
+		}
+	}
+
+	var params workspaceDiagnosticParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeParseError,
+			Message: err.Error(),
+			Data:    req.Params,
+		}
+	}
+	previousResultIDs := map[string]string{}
+	for _, prev := range params.PreviousResultIDs {
+		path, err := uriToPath(prev.URI)
+		if err != nil {
+			return nil, err
+		}
+		previousResultIDs[path] = prev.Value
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Modules in workspace folders are reported even if no files are opened
+	for _, ws := range h.workspaces {
+		dirs, err := h.findModuleDirs(ws.root)
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range dirs {
+			h.moduleAt(dir, ws)
+		}
+	}
+
+	ret := workspaceDiagnosticReport{Items: []any{}}
+	for _, dir := range slices.Sorted(maps.Keys(h.modules)) {
+		mod := h.modules[dir]
+		if err := h.inspectIfChanged(ctx, conn, mod); err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			// A broken module should not hide diagnostics of other modules
+			log.Printf("Failed to inspect %s: %s", mod.dir, err)
+			continue
+		}
+
+		paths, err := h.modulePaths(mod)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			diagnostics := mod.diagnostics(path)
+			resultID, err := diagnosticsResultID(diagnostics)
+			if err != nil {
+				return nil, err
+			}
+
+			if resultID == previousResultIDs[path] {
+				ret.Items = append(ret.Items, workspaceUnchangedDocumentDiagnosticReport{
+					unchangedDocumentDiagnosticReport: unchangedDocumentDiagnosticReport{Kind: "unchanged", ResultID: resultID},
+					URI:                               pathToURI(path),
+				})
+			} else {
+				ret.Items = append(ret.Items, workspaceFullDocumentDiagnosticReport{
+					fullDocumentDiagnosticReport: fullDocumentDiagnosticReport{Kind: "full", ResultID: resultID, Items: diagnostics},
+					URI:                          pathToURI(path),
+				})
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// findModuleDirs returns directories that contain Terraform files under the root.
+// Hidden directories are skipped in the same way as --recursive.
+func (h *handler) findModuleDirs(root string) ([]string, error) {
+	dirs := []string{}
+	err := afero.Walk(h.fs, root, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if h.isModuleDir(path) {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+// modulePaths returns Terraform files in the module and files that have diagnostics.
+func (h *handler) modulePaths(mod *module) ([]string, error) {
+	paths := []string{}
+	files, err := afero.ReadDir(h.fs, mod.dir)
+	if err != nil {
+		return paths, err
+	}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(file.Name(), ".tf") || strings.HasSuffix(file.Name(), ".tf.json") {
+			paths = append(paths, filepath.Join(mod.dir, file.Name()))
+		}
+	}
+	for path := range mod.issues {
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
@@ -2,48 +2,44 @@ package langserver
 
 import (
 	"context"
-	"fmt"
-	"log"
+	"maps"
 
-	lsp "github.com/sourcegraph/go-lsp"
 	"github.com/sourcegraph/jsonrpc2"
 	"github.com/spf13/afero"
-	"github.com/terraform-linters/tflint/tflint"
 )
 
 func (h *handler) workspaceDidChangeWatchedFiles(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
-	if h.rootDir == "" {
-		return nil, fmt.Errorf("root directory is undefined")
-	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-	newConfig, err := tflint.LoadConfig(afero.Afero{Fs: afero.NewOsFs()}, h.configPath)
-	if err != nil {
+	if err := h.reloadWorkspaces(); err != nil {
 		return nil, err
 	}
-	newConfig.Merge(h.cliConfig)
-	h.config = newConfig
 
 	h.fs = afero.NewCopyOnWriteFs(afero.NewOsFs(), afero.NewMemMapFs())
 
-	diagnostics, err := h.inspect()
+	diagnostics, err := h.inspectAll(ctx, conn)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Printf("Notify textDocument/publishDiagnostics with %#v", diagnostics)
-	for path, diags := range diagnostics {
-		err = conn.Notify(
-			ctx,
-			"textDocument/publishDiagnostics",
-			lsp.PublishDiagnosticsParams{
-				URI:         pathToURI(path),
-				Diagnostics: diags,
-			},
-		)
+	return nil, h.publishDiagnostics(ctx, conn, diagnostics)
+}
+
+// inspectAll inspects all modules, such as after reloading configs.
+func (h *handler) inspectAll(ctx context.Context, conn *jsonrpc2.Conn) (map[string][]diagnostic, error) {
+	ret := map[string][]diagnostic{}
+	for _, mod := range h.modules {
+		// In pull mode, modules are inspected when the client requests diagnostics
+		if h.pullDiagnostics {
+			mod.inspected = false
+			continue
+		}
+		diags, err := h.inspect(ctx, conn, mod)
 		if err != nil {
-			return nil, fmt.Errorf("Failed to notify textDocument/publishDiagnostics: %s", err)
+			return ret, err
 		}
+		maps.Copy(ret, diags)
 	}
-
-	return nil, nil
+	return ret, nil
 }
@@ -0,0 +1,75 @@
+package langserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	lsp "github.com/sourcegraph/go-lsp"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// fixAllCommand applies all available autofixes to the module of the passed document.
+const fixAllCommand = "tflint.fixAll"
+
+func (h *handler) workspaceExecuteCommand(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (result any, err error) {
+	if req.Params == nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidRequest,
+			Message: "request params are nil",
+		}
+	}
+
+	var params lsp.ExecuteCommandParams
+	if err := json.Unmarshal(*req.Params, &params); err != nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeParseError,
+			Message: err.Error(),
+			Data:    req.Params,
+		}
+	}
+
+	switch params.Command {
+	case fixAllCommand:
+		return h.fixAll(ctx, params.Arguments)
+	}
+
+	return nil, &jsonrpc2.Error{
+		Code:    jsonrpc2.CodeInvalidParams,
+		Message: fmt.Sprintf("unsupported command: %s", params.Command),
+	}
+}
+
+// fixAll runs all rules with autofixes enabled against the module of the document
+// passed as the first argument, and returns the changes as a workspace edit.
+// It returns nil if there is nothing to fix.
+func (h *handler) fixAll(ctx context.Context, arguments []any) (*lsp.WorkspaceEdit, error) {
+	if len(arguments) != 1 {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: fmt.Sprintf("%s expects a document URI, but got %d arguments", fixAllCommand, len(arguments)),
+		}
+	}
+	uri, ok := arguments[0].(string)
+	if !ok {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidParams,
+			Message: fmt.Sprintf("%s expects a document URI, but got %v", fixAllCommand, arguments[0]),
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	path, err := uriToPath(lsp.DocumentURI(uri))
+	if err != nil {
+		return nil, err
+	}
+	mod := h.moduleFor(path)
+
+	runners, err := h.check(ctx, mod, true, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return h.changesEdit(mod, runners)
+}
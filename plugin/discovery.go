@@ -10,9 +10,7 @@ import (
 	"runtime"
 	"strings"
 
-	plugin "github.com/hashicorp/go-plugin"
 	"github.com/mitchellh/go-homedir"
-	"github.com/terraform-linters/tflint-plugin-sdk/plugin/host2plugin"
 	"github.com/terraform-linters/tflint/tflint"
 )
 
@@ -20,14 +18,30 @@ import (
 // If the plugin is not enabled, skip without starting.
 // The Terraform Language plugin is treated specially. Plugins for which no version
 // is specified will launch the bundled plugin instead of returning an error.
-func Discovery(config *tflint.Config) (*Plugin, error) {
-	clients := map[string]*plugin.Client{}
-	rulesets := map[string]*host2plugin.Client{}
+// Plugins recorded in the lock file are launched only if the binary matches the checksum.
+// If "plugin_verification" is "required", plugins without a recorded verification are refused.
+func Discovery(config *tflint.Config) (_ *Plugin, err error) {
+	rulesets := map[string]*RuleSet{}
+	// Kill plugins started before the failure, since callers such as
+	// the language server keep running after the error
+	defer func() {
+		if err != nil {
+			for _, ruleset := range rulesets {
+				ruleset.Kill()
+			}
+		}
+	}()
+
+	lock, err := LoadLock(config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load %s; %w", LockFileName, err)
+	}
 
 	for _, pluginCfg := range config.Plugins {
 		installCfg := NewInstallConfig(config, pluginCfg)
+		lock.Resolve(installCfg)
 		pluginPath, err := FindPluginPath(installCfg)
-		var cmd *exec.Cmd
+		var newCmd func() *exec.Cmd
 		if os.IsNotExist(err) {
 			if pluginCfg.Name == "terraform" && installCfg.ManuallyInstalled() {
 				log.Print(`[INFO] Plugin "terraform" is not installed, but the bundled plugin is available.`)
@@ -35,7 +49,7 @@ func Discovery(config *tflint.Config) (*Plugin, error) {
 				if err != nil {
 					return nil, err
 				}
-				cmd = exec.Command(self, "--act-as-bundled-plugin")
+				newCmd = func() *exec.Cmd { return exec.Command(self, "--act-as-bundled-plugin") }
 			} else {
 				if installCfg.ManuallyInstalled() {
 					pluginDir, err := getPluginDir(config)
@@ -47,33 +61,34 @@ func Discovery(config *tflint.Config) (*Plugin, error) {
 				return nil, fmt.Errorf(`Plugin "%s" not found. Did you run "tflint --init"?`, pluginCfg.Name)
 			}
 		} else {
-			cmd = exec.Command(pluginPath)
+			newCmd = func() *exec.Cmd { return exec.Command(pluginPath) }
 		}
 
 		if pluginCfg.Enabled {
 			log.Printf(`[INFO] Plugin "%s" found`, pluginCfg.Name)
 
-			client := host2plugin.NewClient(&host2plugin.ClientOpts{
-				Cmd: cmd,
-			})
-			rpcClient, err := client.Client()
-			if err != nil {
-				return nil, pluginClientError(err, pluginCfg)
+			if pluginPath != "" {
+				if config.PluginVerification == tflint.PluginVerificationRequired {
+					if err := lock.RequireVerified(installCfg); err != nil {
+						return nil, err
+					}
+				}
+				if err := lock.Verify(installCfg, pluginPath); err != nil {
+					return nil, err
+				}
 			}
-			raw, err := rpcClient.Dispense("ruleset")
+
+			ruleset, err := newRuleSet(pluginCfg.Name, newCmd, pluginCfg.CallTimeout, config.PluginLogDir)
 			if err != nil {
-				return nil, err
+				return nil, pluginClientError(err, pluginCfg)
 			}
-			ruleset := raw.(*host2plugin.Client)
-
-			clients[pluginCfg.Name] = client
 			rulesets[pluginCfg.Name] = ruleset
 		} else {
 			log.Printf(`[INFO] Plugin "%s" found, but the plugin is disabled`, pluginCfg.Name)
 		}
 	}
 
-	return &Plugin{RuleSets: rulesets, clients: clients}, nil
+	return &Plugin{RuleSets: rulesets}, nil
 }
 
 // FindPluginPath returns the plugin binary path.
@@ -91,6 +106,11 @@ func FindPluginPath(config *InstallConfig) (string, error) {
 	return path, err
 }
 
+// PluginDir returns the base plugin directory. See getPluginDir for the priorities.
+func PluginDir(cfg *tflint.Config) (string, error) {
+	return getPluginDir(cfg)
+}
+
 // getPluginDir returns the base plugin directory.
 // Adopted with the following priorities:
 //
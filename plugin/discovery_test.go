@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/terraform-linters/tflint/tflint"
@@ -275,6 +276,102 @@ func Test_Discovery_bundledPluginWithVersion(t *testing.T) {
 	}
 }
 
+func Test_Discovery_lockMismatch(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	original := PluginRoot
+	PluginRoot = filepath.Join(cwd, "test-fixtures", "plugins")
+	defer func() { PluginRoot = original }()
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	lock := fmt.Sprintf(`
+plugin "bar" {
+  source  = "github.com/terraform-linters/tflint-ruleset-bar"
+  version = "0.1.0"
+  binary_hashes = {
+    %s = "0000000000000000000000000000000000000000000000000000000000000000"
+  }
+}`, platform())
+	if err := os.WriteFile(LockFileName, []byte(lock), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Discovery(&tflint.Config{
+		Plugins: map[string]*tflint.PluginConfig{
+			"bar": {
+				Name:    "bar",
+				Enabled: true,
+				Source:  "github.com/terraform-linters/tflint-ruleset-bar",
+				Version: "0.1.0",
+			},
+		},
+	})
+
+	if err == nil {
+		t.Fatal("An error should have occurred, but it did not occur")
+	}
+	expected := `Plugin "bar" does not match the checksum in .tflint.lock.hcl: expected=0000000000000000000000000000000000000000000000000000000000000000`
+	if !strings.HasPrefix(err.Error(), expected) {
+		t.Fatalf("Error message not matched: want=%s, got=%s", expected, err.Error())
+	}
+}
+
+func Test_Discovery_lockWithoutBinaryHash(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	original := PluginRoot
+	PluginRoot = filepath.Join(cwd, "test-fixtures", "plugins")
+	defer func() { PluginRoot = original }()
+
+	// The lock file was committed from another platform, and the installed binary
+	// has been swapped. Only the zip hash for this platform is recorded.
+	dir := t.TempDir()
+	t.Chdir(dir)
+	lock := fmt.Sprintf(`
+plugin "bar" {
+  source   = "github.com/terraform-linters/tflint-ruleset-bar"
+  version  = "0.1.0"
+  verified = true
+  binary_hashes = {
+    plan9_386 = "0000000000000000000000000000000000000000000000000000000000000000"
+  }
+  zip_hashes = {
+    plan9_386 = "0000000000000000000000000000000000000000000000000000000000000000"
+    %s = "0000000000000000000000000000000000000000000000000000000000000000"
+  }
+}`, platform())
+	if err := os.WriteFile(LockFileName, []byte(lock), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Discovery(&tflint.Config{
+		PluginVerification: tflint.PluginVerificationRequired,
+		Plugins: map[string]*tflint.PluginConfig{
+			"bar": {
+				Name:    "bar",
+				Enabled: true,
+				Source:  "github.com/terraform-linters/tflint-ruleset-bar",
+				Version: "0.1.0",
+			},
+		},
+	})
+
+	if err == nil {
+		t.Fatal("An error should have occurred, but it did not occur")
+	}
+	expected := fmt.Sprintf(`Plugin "bar" has no binary checksum for %s in .tflint.lock.hcl. Run "tflint --init" to record the checksum for this platform`, platform())
+	if err.Error() != expected {
+		t.Fatalf("Error message not matched: want=%s, got=%s", expected, err.Error())
+	}
+}
+
 func Test_FindPluginPath(t *testing.T) {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -0,0 +1,171 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// gitlabRelease is a release returned by the GitLab Releases API.
+// See https://docs.gitlab.com/ee/api/releases/
+type gitlabRelease struct {
+	TagName         string `json:"tag_name"`
+	UpcomingRelease bool   `json:"upcoming_release"`
+	Assets          struct {
+		Links []*gitlabReleaseLink `json:"links"`
+	} `json:"assets"`
+}
+
+// gitlabReleaseLink is a link to an asset attached to the GitLab release.
+type gitlabReleaseLink struct {
+	Name           string `json:"name"`
+	URL            string `json:"url"`
+	DirectAssetURL string `json:"direct_asset_url"`
+}
+
+// gitlabSource fetches releases from GitLab.
+// Assets must be attached to the release as links with the same names as GitHub release assets.
+type gitlabSource struct {
+	config *InstallConfig
+	assets map[string]*gitlabReleaseLink
+}
+
+func (s *gitlabSource) String() string {
+	return "GitLab releases"
+}
+
+func (s *gitlabSource) versions() ([]*version.Version, error) {
+	versions := []*version.Version{}
+
+	page := "1"
+	for page != "" {
+		var releases []*gitlabRelease
+		resp, err := s.get(fmt.Sprintf("%s/releases?per_page=100&page=%s", s.projectURL(), page), &releases)
+		if err != nil {
+			return nil, err
+		}
+		for _, release := range releases {
+			if release.UpcomingRelease {
+				continue
+			}
+			v, err := version.NewVersion(strings.TrimPrefix(release.TagName, "v"))
+			if err != nil {
+				log.Printf("[DEBUG] Ignore release %s: %s", release.TagName, err)
+				continue
+			}
+			versions = append(versions, v)
+		}
+		page = resp.Header.Get("X-Next-Page")
+	}
+	return versions, nil
+}
+
+func (s *gitlabSource) fetch() error {
+	var release gitlabRelease
+	if _, err := s.get(fmt.Sprintf("%s/releases/%s", s.projectURL(), url.PathEscape(s.config.TagName())), &release); err != nil {
+		return err
+	}
+
+	s.assets = map[string]*gitlabReleaseLink{}
+	for _, link := range release.Assets.Links {
+		log.Printf("[DEBUG] asset found: %s", link.Name)
+		s.assets[link.Name] = link
+	}
+	return nil
+}
+
+func (s *gitlabSource) download(filename string) (*os.File, error) {
+	link, exists := s.assets[filename]
+	if !exists {
+		return nil, fmt.Errorf("file not found in the GitLab release. Does the release contain the file with the correct name ?")
+	}
+	assetURL := link.DirectAssetURL
+	if assetURL == "" {
+		assetURL = link.URL
+	}
+
+	resp, err := s.do(assetURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	file, err := os.CreateTemp("", "tflint-download-temp-file-*")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(file, resp.Body); err != nil {
+		return file, err
+	}
+	if _, err := file.Seek(0, 0); err != nil {
+		return file, err
+	}
+
+	log.Printf("[DEBUG] Downloaded to %s", file.Name())
+	return file, nil
+}
+
+// projectURL returns the API URL of the project. The project path is URL-encoded
+// as the project ID, like https://gitlab.com/api/v4/projects/group%2Fproject.
+func (s *gitlabSource) projectURL() string {
+	return fmt.Sprintf("https://%s/api/v4/projects/%s", s.config.SourceHost, url.PathEscape(s.config.SourceOwner+"/"+s.config.SourceRepo))
+}
+
+// get sends a GET request to the GitLab API and decodes the JSON response into v.
+func (s *gitlabSource) get(url string, v any) (*http.Response, error) {
+	resp, err := s.do(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, fmt.Errorf("Failed to parse the response from %s: %w", url, err)
+	}
+	return resp, nil
+}
+
+// do sends a GET request. Requests to the source host are authenticated
+// if a token is set. Asset links to other hosts are requested without the token.
+func (s *gitlabSource) do(rawURL string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL.Host == s.config.SourceHost {
+		if t := s.config.getGitLabToken(); t != "" {
+			req.Header.Set("PRIVATE-TOKEN", t)
+		}
+	}
+
+	// The HTTP client only strips standard credential headers like Authorization on redirects,
+	// so remove the token when assets are redirected to another host, such as object storage.
+	client := *releaseHTTPClient
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		if req.URL.Host != s.config.SourceHost {
+			req.Header.Del("PRIVATE-TOKEN")
+		}
+		return nil
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", rawURL, resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,286 @@
+package plugin
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+func Test_Install_gitlab(t *testing.T) {
+	original := PluginRoot
+	PluginRoot = t.TempDir()
+	defer func() { PluginRoot = original }()
+
+	t.Setenv("GITLAB_TOKEN", "gitlab_token")
+	server := newTestGitLab(t, "0.1.0")
+	config := NewInstallConfig(tflint.EmptyConfig(), newGitLabTestPluginConfig(server.URL, "0.1.0"))
+
+	// Signature verification is skipped as no signing key is configured
+	path, err := config.Install(nil)
+	if !errors.Is(err, ErrPluginNotVerified) {
+		t.Fatalf("Expected ErrPluginNotVerified, but got %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "0.1.0" {
+		t.Fatalf("Installed binary is invalid: got=%s", got)
+	}
+}
+
+func Test_Install_gitlab_unauthorized(t *testing.T) {
+	original := PluginRoot
+	PluginRoot = t.TempDir()
+	defer func() { PluginRoot = original }()
+
+	t.Setenv("GITLAB_TOKEN", "")
+	server := newTestGitLab(t, "0.1.0")
+	config := NewInstallConfig(tflint.EmptyConfig(), newGitLabTestPluginConfig(server.URL, "0.1.0"))
+
+	_, err := config.Install(nil)
+	if err == nil {
+		t.Fatal("An error should have occurred, but it did not occur")
+	}
+	expected := fmt.Sprintf("Failed to fetch GitLab releases: GET %s/api/v4/projects/group%%2Fsubgroup%%2Ftflint-ruleset-foo/releases/v0.1.0: 401 Unauthorized", server.URL)
+	if err.Error() != expected {
+		t.Fatalf("Error message not matched: want=%s, got=%s", expected, err)
+	}
+}
+
+func Test_ResolveVersion_gitlab(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "gitlab_token")
+	server := newTestGitLab(t, "0.1.0", "0.2.0", "0.3.0-beta", "1.0.0")
+
+	tests := []struct {
+		constraint string
+		want       string
+	}{
+		{
+			constraint: "~> 0.1",
+			want:       "0.2.0",
+		},
+		{
+			constraint: ">= 0.1",
+			want:       "1.0.0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.constraint, func(t *testing.T) {
+			pluginCfg := newGitLabTestPluginConfig(server.URL, test.constraint)
+			pluginCfg.VersionConstraints = version.MustConstraints(version.NewConstraint(test.constraint))
+			config := NewInstallConfig(tflint.EmptyConfig(), pluginCfg)
+
+			if err := config.ResolveVersion(); err != nil {
+				t.Fatal(err)
+			}
+			if config.ResolvedVersion() != test.want {
+				t.Fatalf("want=%s, got=%s", test.want, config.ResolvedVersion())
+			}
+		})
+	}
+}
+
+func Test_gitlabSource_download_redirect(t *testing.T) {
+	t.Setenv("GITLAB_TOKEN", "gitlab_token")
+
+	var token string
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("PRIVATE-TOKEN")
+		fmt.Fprint(w, "content")
+	}))
+	t.Cleanup(storage.Close)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "gitlab_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.Redirect(w, r, storage.URL+"/asset", http.StatusFound)
+	}))
+	t.Cleanup(server.Close)
+	useTestHTTPClient(t, server)
+
+	source := &gitlabSource{
+		config: NewInstallConfig(tflint.EmptyConfig(), newGitLabTestPluginConfig(server.URL, "0.1.0")),
+		assets: map[string]*gitlabReleaseLink{
+			"checksums.txt": {Name: "checksums.txt", DirectAssetURL: server.URL + "/asset"},
+		},
+	}
+	file, err := source.download("checksums.txt")
+	if file != nil {
+		defer os.Remove(file.Name())
+		defer file.Close()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "content" {
+		t.Fatalf("Downloaded content is invalid: got=%s", got)
+	}
+	if token != "" {
+		t.Fatalf("The token should not be sent to another host, but got %q", token)
+	}
+}
+
+func TestGetGitLabToken(t *testing.T) {
+	tests := []struct {
+		name string
+		envs map[string]string
+		want string
+	}{
+		{
+			name: "no token",
+			want: "",
+		},
+		{
+			name: "GITLAB_TOKEN",
+			envs: map[string]string{
+				"GITLAB_TOKEN": "gitlab_token",
+			},
+			want: "gitlab_token",
+		},
+		{
+			name: "GITLAB_TOKEN and GITLAB_TOKEN_gitlab_example_com",
+			envs: map[string]string{
+				"GITLAB_TOKEN":                    "gitlab_token",
+				"GITLAB_TOKEN_gitlab_example_com": "gitlab_example_com_token",
+			},
+			want: "gitlab_example_com_token",
+		},
+		{
+			name: "GITHUB_TOKEN_gitlab_example_com",
+			envs: map[string]string{
+				"GITHUB_TOKEN_gitlab_example_com": "github_token",
+			},
+			want: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("GITLAB_TOKEN", "")
+			for k, v := range test.envs {
+				t.Setenv(k, v)
+			}
+
+			config := &InstallConfig{PluginConfig: &tflint.PluginConfig{SourceHost: "gitlab.example.com"}}
+			got := config.getGitLabToken()
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func newGitLabTestPluginConfig(serverURL string, version string) *tflint.PluginConfig {
+	host := strings.TrimPrefix(serverURL, "https://")
+	return &tflint.PluginConfig{
+		Name:        "foo",
+		Enabled:     true,
+		Version:     version,
+		Source:      host + "/group/subgroup/tflint-ruleset-foo",
+		SourceType:  "gitlab",
+		SourceHost:  host,
+		SourceOwner: "group/subgroup",
+		SourceRepo:  "tflint-ruleset-foo",
+	}
+}
+
+// newTestGitLab starts a GitLab API server that serves releases whose binary content is the version.
+// Requests must be authenticated with "gitlab_token". Releases are paginated one per page.
+func newTestGitLab(t *testing.T, versions ...string) *httptest.Server {
+	const project = "/api/v4/projects/group%2Fsubgroup%2Ftflint-ruleset-foo"
+
+	var serverURL string
+	files := map[string][]byte{}
+	releases := map[string]*gitlabRelease{}
+	for _, v := range versions {
+		buf := new(bytes.Buffer)
+		w := zip.NewWriter(buf)
+		binary, err := w.Create("tflint-ruleset-foo" + fileExt())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := binary.Write([]byte(v)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		files["/downloads/"+v+"/"+mirrorTestAssetName()] = buf.Bytes()
+		files["/downloads/"+v+"/checksums.txt"] = []byte(fmt.Sprintf("%x  %s\n", sha256.Sum256(buf.Bytes()), mirrorTestAssetName()))
+
+		releases["v"+v] = &gitlabRelease{TagName: "v" + v}
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "gitlab_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message":"401 Unauthorized"}`)
+			return
+		}
+
+		var body any
+		switch path := r.URL.EscapedPath(); {
+		case path == project+"/releases":
+			page := 1
+			if _, err := fmt.Sscan(r.URL.Query().Get("page"), &page); err != nil {
+				t.Error(err)
+			}
+			if page < len(versions) {
+				w.Header().Set("X-Next-Page", fmt.Sprint(page+1))
+			}
+			body = []*gitlabRelease{releases["v"+versions[page-1]]}
+		case strings.HasPrefix(path, project+"/releases/"):
+			release, exists := releases[strings.TrimPrefix(path, project+"/releases/")]
+			if !exists {
+				http.NotFound(w, r)
+				return
+			}
+			v := strings.TrimPrefix(release.TagName, "v")
+			release.Assets.Links = []*gitlabReleaseLink{
+				{Name: "checksums.txt", URL: serverURL + "/downloads/" + v + "/checksums.txt"},
+				{Name: mirrorTestAssetName(), URL: "https://example.com/unused", DirectAssetURL: serverURL + "/downloads/" + v + "/" + mirrorTestAssetName()},
+			}
+			body = release
+		default:
+			content, exists := files[path]
+			if !exists {
+				http.NotFound(w, r)
+				return
+			}
+			if _, err := w.Write(content); err != nil {
+				t.Error(err)
+			}
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			t.Error(err)
+		}
+	}))
+	serverURL = server.URL
+	t.Cleanup(server.Close)
+	useTestHTTPClient(t, server)
+	return server
+}
@@ -18,6 +18,7 @@ import (
 	"strings"
 
 	"github.com/google/go-github/v67/github"
+	"github.com/hashicorp/go-version"
 	"github.com/terraform-linters/tflint/tflint"
 	"golang.org/x/net/idna"
 	"golang.org/x/oauth2"
@@ -41,11 +42,26 @@ type InstallConfig struct {
 	globalConfig *tflint.Config
 
 	*tflint.PluginConfig
+
+	// resolvedVersion is an exact version to be installed.
+	// If the version is a constraint, it is empty until resolved from
+	// the lock file or releases.
+	resolvedVersion string
 }
 
 // NewInstallConfig returns a new InstallConfig from passed PluginConfig.
 func NewInstallConfig(config *tflint.Config, pluginCfg *tflint.PluginConfig) *InstallConfig {
-	return &InstallConfig{globalConfig: config, PluginConfig: pluginCfg}
+	c := &InstallConfig{globalConfig: config, PluginConfig: pluginCfg}
+	if pluginCfg.VersionConstraints == nil {
+		c.resolvedVersion = pluginCfg.Version
+	}
+	return c
+}
+
+// ResolvedVersion returns the exact version to be installed.
+// If the version constraint has not been resolved yet, an empty string is returned.
+func (c *InstallConfig) ResolvedVersion() string {
+	return c.resolvedVersion
 }
 
 // ManuallyInstalled returns whether the plugin should be installed manually.
@@ -56,14 +72,24 @@ func (c *InstallConfig) ManuallyInstalled() bool {
 
 // InstallPath returns an installation path from the plugin directory.
 func (c *InstallConfig) InstallPath() string {
-	return filepath.Join(c.Source, c.Version, fmt.Sprintf("tflint-ruleset-%s", c.Name))
+	return filepath.Join(c.sourcePath(), c.resolvedVersion, fmt.Sprintf("tflint-ruleset-%s", c.Name))
+}
+
+// sourcePath returns the source as a slash-separated path.
+// The scheme of registry URLs is omitted, like registry.example.com/ns/name.
+// The port separator is replaced with "_" as it is not allowed in Windows paths.
+func (c *InstallConfig) sourcePath() string {
+	if !c.SourceRegistry {
+		return c.Source
+	}
+	return strings.ReplaceAll(strings.TrimPrefix(c.Source, "https://"), ":", "_")
 }
 
 // TagName returns a tag name that the GitHub release should meet.
 // The version must not contain leading "v", as the prefix "v" is added here,
 // and the release tag must be in a format similar to `v1.1.1`.
 func (c *InstallConfig) TagName() string {
-	return fmt.Sprintf("v%s", c.Version)
+	return fmt.Sprintf("v%s", c.resolvedVersion)
 }
 
 // AssetName returns a name that the asset contained in the release should meet.
@@ -93,18 +119,39 @@ func (c *InstallConfig) CertificateIdentityIssuer() string {
 
 var ErrPluginNotVerified = errors.New("plugin not verified")
 
-// Install fetches the release from GitHub and puts the binary in the plugin directory.
+// Install fetches the release from GitHub, GitLab, the plugin registry, or the plugin mirror and puts the binary in the plugin directory.
 // This installation process will automatically check the checksum of the downloaded zip file.
 // The release must always contain a checksum file and meet the following conventions:
 //
-//   - The release must be tagged with a name like v1.1.1
+//   - The release must be tagged with a name like v1.1.1 (a directory like {source}/1.1.1 in the mirror)
 //   - The release must contain an asset with a name like tflint-ruleset-{name}_{GOOS}_{GOARCH}.zip
 //   - The zip file must contain a binary named tflint-ruleset-{name} (tflint-ruleset-{name}.exe in Windows)
 //   - The release must contain a checksum file for the zip file with the name checksums.txt
 //   - The checksum file must contain a sha256 hash and filename
 //
 // If possible, verify the signature to ensure that the checksum file has not been tampered with.
-func (c *InstallConfig) Install() (string, error) {
+// If the signature cannot be verified, ErrPluginNotVerified is returned with the installed path,
+// but if "plugin_verification" is "required", the installation fails. If it is "off", no error is returned.
+//
+// If the version is a constraint and has not been resolved, the newest release
+// that satisfies the constraint is installed.
+//
+// If the plugin cache directory is set, the release files are reused from the cache
+// and the downloaded files are saved in the cache after verification.
+//
+// If a lock is passed, the checksum file is verified against the hashes recorded in the lock,
+// and the installed plugin is recorded in the lock. It is the caller's responsibility to write the lock.
+func (c *InstallConfig) Install(lock *Lock) (string, error) {
+	source, err := c.releaseSource()
+	if err != nil {
+		return "", err
+	}
+	if c.resolvedVersion == "" {
+		if err := c.ResolveVersion(); err != nil {
+			return "", err
+		}
+	}
+
 	dir, err := getPluginDir(c.globalConfig)
 	if err != nil {
 		return "", fmt.Errorf("Failed to get plugin dir: %w", err)
@@ -116,13 +163,12 @@ func (c *InstallConfig) Install() (string, error) {
 		return "", fmt.Errorf("Failed to mkdir to %s: %w", filepath.Dir(path), err)
 	}
 
-	assets, err := c.fetchReleaseAssets()
-	if err != nil {
-		return "", fmt.Errorf("Failed to fetch GitHub releases: %w", err)
+	if err := source.fetch(); err != nil {
+		return "", fmt.Errorf("Failed to fetch %s: %w", source, err)
 	}
 
 	log.Printf("[DEBUG] Download checksums.txt")
-	checksumsFile, err := c.downloadToTempFile(assets["checksums.txt"])
+	checksumsFile, err := source.download("checksums.txt")
 	if checksumsFile != nil {
 		defer os.Remove(checksumsFile.Name())
 	}
@@ -133,11 +179,12 @@ func (c *InstallConfig) Install() (string, error) {
 	var verified bool
 	sigchecker := NewSignatureChecker(c)
 	if sigchecker.HasSigningKey() {
-		if err := c.verifyChecksumsSignature(sigchecker, checksumsFile, assets); err != nil {
+		if err := c.verifyChecksumsSignature(sigchecker, checksumsFile, source); err != nil {
 			return "", err
 		}
 		verified = true
-	} else {
+	} else if isGitHubSource(source) {
+		// Artifact attestations are only available on GitHub
 		verified, err = c.tryKeylessVerifyChecksumsSignature(sigchecker, checksumsFile)
 		if err != nil {
 			return "", err
@@ -145,7 +192,7 @@ func (c *InstallConfig) Install() (string, error) {
 	}
 
 	log.Printf("[DEBUG] Download %s", c.AssetName())
-	zipFile, err := c.downloadToTempFile(assets[c.AssetName()])
+	zipFile, err := source.download(c.AssetName())
 	if zipFile != nil {
 		defer os.Remove(zipFile.Name())
 	}
@@ -160,14 +207,37 @@ func (c *InstallConfig) Install() (string, error) {
 	if err = checksummer.Verify(c.AssetName(), zipFile); err != nil {
 		return "", fmt.Errorf("Failed to verify checksums: %s", err)
 	}
+	if lock != nil {
+		if err := lock.verifyChecksums(c, checksummer); err != nil {
+			return "", fmt.Errorf("Failed to verify checksums: %s", err)
+		}
+		if !verified && lock.verifiedRelease(c, checksummer) {
+			log.Printf("[DEBUG] The checksums match the release verified in the lock file")
+			verified = true
+		}
+	}
 	log.Printf("[DEBUG] Matched checksum successfully")
 
+	if !verified && c.globalConfig.PluginVerification == tflint.PluginVerificationRequired {
+		return "", fmt.Errorf(`Failed to verify the signature of checksums.txt; "plugin_verification" is "required", but no signing key is configured and artifact attestations are not available`)
+	}
+
 	if err = extractFileFromZipFile(zipFile, path); err != nil {
 		return "", fmt.Errorf("Failed to extract binary from %s: %s", c.AssetName(), err)
 	}
+	if lock != nil {
+		if err := lock.record(c, checksummer, path, verified); err != nil {
+			return "", fmt.Errorf("Failed to record %s in the lock file: %s", c.AssetName(), err)
+		}
+	}
+	if cache, ok := source.(*cacheSource); ok {
+		if err := cache.save(); err != nil {
+			return "", fmt.Errorf("Failed to save %s in the plugin cache: %s", c.AssetName(), err)
+		}
+	}
 
 	log.Printf("[DEBUG] Installed %s successfully", path)
-	if !verified {
+	if !verified && c.globalConfig.PluginVerification != tflint.PluginVerificationOff {
 		return path, ErrPluginNotVerified
 	}
 	return path, nil
@@ -176,9 +246,9 @@ func (c *InstallConfig) Install() (string, error) {
 // Verify checksums.txt.sig by PGP signing key.
 // The release must contain a signature file for the checksum file with the name checksums.txt.sig.
 // The signature file must be binary OpenPGP format.
-func (c *InstallConfig) verifyChecksumsSignature(sigchecker *SignatureChecker, checksum io.ReadSeeker, assets map[string]*github.ReleaseAsset) error {
+func (c *InstallConfig) verifyChecksumsSignature(sigchecker *SignatureChecker, checksum io.ReadSeeker, source releaseSource) error {
 	log.Printf("[DEBUG] Download checksums.txt.sig")
-	signatureFile, err := c.downloadToTempFile(assets["checksums.txt.sig"])
+	signatureFile, err := source.download("checksums.txt.sig")
 	if signatureFile != nil {
 		defer os.Remove(signatureFile.Name())
 	}
@@ -234,6 +304,83 @@ func (c *InstallConfig) tryKeylessVerifyChecksumsSignature(sigchecker *Signature
 	return true, nil
 }
 
+// ResolveVersion resolves the version constraint to the newest release
+// that satisfies it. Pre-releases are ignored unless the constraint
+// explicitly allows them.
+func (c *InstallConfig) ResolveVersion() error {
+	if c.VersionConstraints == nil {
+		c.resolvedVersion = c.Version
+		return nil
+	}
+
+	versions, err := c.fetchVersions()
+	if err != nil {
+		return err
+	}
+
+	var latest *version.Version
+	for _, v := range versions {
+		if c.VersionConstraints.Check(v) && (latest == nil || v.GreaterThan(latest)) {
+			latest = v
+		}
+	}
+	if latest == nil {
+		return fmt.Errorf(`No release of "%s" plugin satisfies the version constraint "%s"`, c.Name, c.Version)
+	}
+
+	log.Printf(`[DEBUG] Resolved "%s" to %s`, c.Version, latest.Original())
+	c.resolvedVersion = latest.Original()
+	return nil
+}
+
+// fetchVersions fetches versions of all releases from the release source.
+func (c *InstallConfig) fetchVersions() ([]*version.Version, error) {
+	source, err := c.releaseSource()
+	if err != nil {
+		return nil, err
+	}
+	versions, err := source.versions()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch %s: %w", source, err)
+	}
+	return versions, nil
+}
+
+// fetchReleaseVersions fetches versions of all GitHub releases.
+// Drafts and releases not tagged with a name like v1.1.1 are ignored.
+func (c *InstallConfig) fetchReleaseVersions() ([]*version.Version, error) {
+	ctx := context.Background()
+	client, err := newGitHubClient(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []*version.Version{}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		releases, resp, err := client.Repositories.ListReleases(ctx, c.SourceOwner, c.SourceRepo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, release := range releases {
+			if release.GetDraft() {
+				continue
+			}
+			v, err := version.NewVersion(strings.TrimPrefix(release.GetTagName(), "v"))
+			if err != nil {
+				log.Printf("[DEBUG] Ignore release %s: %s", release.GetTagName(), err)
+				continue
+			}
+			versions = append(versions, v)
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return versions, nil
+}
+
 // fetchReleaseAssets fetches assets from the GitHub release.
 // The release is determined by the source path and tag name.
 func (c *InstallConfig) fetchReleaseAssets() (map[string]*github.ReleaseAsset, error) {
@@ -343,7 +490,41 @@ func (c *InstallConfig) downloadToTempFile(asset *github.ReleaseAsset) (*os.File
 // can be useful, for example if you are hosting your plugin on GHES.
 // The host name must be normalized with Punycode, and "-" can be converted to "__" and "." to "-".
 func (c *InstallConfig) getGitHubToken() string {
-	prefix := "GITHUB_TOKEN_"
+	if t := c.getHostToken("GITHUB_TOKEN_"); t != "" {
+		return t
+	}
+
+	if t := os.Getenv("GITHUB_TOKEN"); t != "" {
+		log.Printf("[DEBUG] GITHUB_TOKEN set, plugin requests to the GitHub API will be authenticated")
+		return t
+	}
+
+	return ""
+}
+
+// getGitLabToken gets a GitLab access token from environment variables.
+// Environment variables are used in the following order of priority:
+//
+//   - GITLAB_TOKEN_{source_host} (e.g. GITLAB_TOKEN_gitlab_example_com)
+//   - GITLAB_TOKEN
+//
+// The host name is normalized in the same way as GITHUB_TOKEN_{source_host}.
+func (c *InstallConfig) getGitLabToken() string {
+	if t := c.getHostToken("GITLAB_TOKEN_"); t != "" {
+		return t
+	}
+
+	if t := os.Getenv("GITLAB_TOKEN"); t != "" {
+		log.Printf("[DEBUG] GITLAB_TOKEN set, plugin requests to the GitLab API will be authenticated")
+		return t
+	}
+
+	return ""
+}
+
+// getHostToken gets an access token for the source host from environment variables
+// named {prefix}{source_host}. If not found, an empty string is returned.
+func (c *InstallConfig) getHostToken(prefix string) string {
 	for _, env := range os.Environ() {
 		eqIdx := strings.Index(env, "=")
 		if eqIdx < 0 {
@@ -372,11 +553,6 @@ func (c *InstallConfig) getGitHubToken() string {
 		return value
 	}
 
-	if t := os.Getenv("GITHUB_TOKEN"); t != "" {
-		log.Printf("[DEBUG] GITHUB_TOKEN set, plugin requests to the GitHub API will be authenticated")
-		return t
-	}
-
 	return ""
 }
 
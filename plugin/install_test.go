@@ -76,7 +76,7 @@ func Test_Install(t *testing.T) {
 		SourceRepo:  "tflint-ruleset-aws",
 	})
 
-	path, err := config.Install()
+	path, err := config.Install(nil)
 	if err != nil {
 		t.Fatalf("Failed to install: %s", err)
 	}
@@ -120,7 +120,7 @@ func Test_Install_Keyless(t *testing.T) {
 
 	// Because the built-in signing key is disabled, an error should be returned,
 	// but because artifact attestation is present, no error occurs.
-	path, err := config.Install()
+	path, err := config.Install(nil)
 	if err != nil {
 		t.Fatalf("Failed to install: %s", err)
 	}
@@ -160,9 +160,9 @@ func Test_Install_Keyless_withoutAttestation(t *testing.T) {
 		SourceRepo:  "tflint-ruleset-aws",
 	})
 
-	path, err := config.Install()
+	path, err := config.Install(nil)
 	if err == nil {
-		t.Fatal("config.Install() should return ErrPluginNotVerified, but did not")
+		t.Fatal("config.Install(nil) should return ErrPluginNotVerified, but did not")
 	}
 	if !errors.Is(err, ErrPluginNotVerified) {
 		t.Fatalf("Failed to install: %s", err)
@@ -206,9 +206,9 @@ func Test_Install_Keyless_withoutAttestation_InExperimentalMode(t *testing.T) {
 		SourceRepo:  "tflint-ruleset-aws",
 	})
 
-	_, err := config.Install()
+	_, err := config.Install(nil)
 	if err == nil {
-		t.Fatal("config.Install() should return an error, but did not")
+		t.Fatal("config.Install(nil) should return an error, but did not")
 	}
 	wantErr := "Failed to download artifact attestations: GET https://api.github.com/repos/terraform-linters/tflint-ruleset-aws/attestations/sha256:2263ed2f64b535a95ab7d19ff22b366bf6b36fb84e4f7fa879f85da698a96595: 404 Not Found []"
 	if err.Error() != wantErr {
@@ -0,0 +1,111 @@
+package plugin
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+// InstalledPlugin is a plugin binary found in the plugin directory.
+// Source and Version are empty for manually installed plugins.
+type InstalledPlugin struct {
+	Name    string
+	Source  string
+	Version string
+	Path    string
+}
+
+// ListInstalledPlugins returns plugins installed in the plugin directory.
+// Automatically installed plugins are placed as {source}/{version}/tflint-ruleset-{name},
+// so the source and version are taken from the path.
+// For registry sources, the source is the path without the scheme.
+func ListInstalledPlugins(config *tflint.Config) ([]*InstalledPlugin, error) {
+	dir, err := getPluginDir(config)
+	if err != nil {
+		return nil, err
+	}
+
+	plugins := []*InstalledPlugin{}
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		name, ok := strings.CutPrefix(d.Name(), "tflint-ruleset-")
+		// Release zip files like tflint-ruleset-{name}_{GOOS}_{GOARCH}.zip are not plugins
+		if !ok || strings.HasSuffix(name, ".zip") {
+			return nil
+		}
+		if runtime.GOOS == "windows" {
+			if name, ok = strings.CutSuffix(name, ".exe"); !ok {
+				return nil
+			}
+		}
+		plugin := &InstalledPlugin{Name: name, Path: path}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		if len(parts) >= 4 {
+			if _, err := version.NewVersion(parts[len(parts)-2]); err == nil {
+				plugin.Source = strings.Join(parts[:len(parts)-2], "/")
+				plugin.Version = parts[len(parts)-2]
+			}
+		}
+
+		plugins = append(plugins, plugin)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(plugins, func(a, b *InstalledPlugin) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return plugins, nil
+}
+
+// ManuallyInstalled returns whether the plugin is installed manually.
+func (p *InstalledPlugin) ManuallyInstalled() bool {
+	return p.Source == ""
+}
+
+// Matches returns whether the installed plugin is the plugin in the config.
+// The version is not compared.
+func (p *InstalledPlugin) Matches(config *InstallConfig) bool {
+	return !p.ManuallyInstalled() && p.Name == config.Name && p.Source == config.sourcePath()
+}
+
+// LatestVersion returns the newest release version. Pre-releases are ignored.
+func (c *InstallConfig) LatestVersion() (string, error) {
+	versions, err := c.fetchVersions()
+	if err != nil {
+		return "", err
+	}
+
+	var latest *version.Version
+	for _, v := range versions {
+		if v.Prerelease() == "" && (latest == nil || v.GreaterThan(latest)) {
+			latest = v
+		}
+	}
+	if latest == nil {
+		return "", nil
+	}
+	return latest.Original(), nil
+}
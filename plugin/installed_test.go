@@ -0,0 +1,96 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+func Test_ListInstalledPlugins(t *testing.T) {
+	dir := t.TempDir()
+	config := tflint.EmptyConfig()
+	config.PluginDir = dir
+
+	files := []string{
+		"tflint-ruleset-manual",
+		"github.com/terraform-linters/tflint-ruleset-aws/0.30.0/tflint-ruleset-aws",
+		"github.com/terraform-linters/tflint-ruleset-aws/0.29.0/tflint-ruleset-aws",
+		"gitlab.com/group/subgroup/tflint-ruleset-foo/0.1.0/tflint-ruleset-foo",
+		"github.com/terraform-linters/tflint-ruleset-aws/0.30.0/tflint-ruleset-aws_linux_amd64.zip",
+		"github.com/terraform-linters/tflint-ruleset-aws/0.30.0/checksums.txt",
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file)+fileExt())
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte{}, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := ListInstalledPlugins(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*InstalledPlugin{
+		{
+			Name:    "aws",
+			Source:  "github.com/terraform-linters/tflint-ruleset-aws",
+			Version: "0.29.0",
+			Path:    filepath.Join(dir, "github.com", "terraform-linters", "tflint-ruleset-aws", "0.29.0", "tflint-ruleset-aws"+fileExt()),
+		},
+		{
+			Name:    "aws",
+			Source:  "github.com/terraform-linters/tflint-ruleset-aws",
+			Version: "0.30.0",
+			Path:    filepath.Join(dir, "github.com", "terraform-linters", "tflint-ruleset-aws", "0.30.0", "tflint-ruleset-aws"+fileExt()),
+		},
+		{
+			Name:    "foo",
+			Source:  "gitlab.com/group/subgroup/tflint-ruleset-foo",
+			Version: "0.1.0",
+			Path:    filepath.Join(dir, "gitlab.com", "group", "subgroup", "tflint-ruleset-foo", "0.1.0", "tflint-ruleset-foo"+fileExt()),
+		},
+		{
+			Name: "manual",
+			Path: filepath.Join(dir, "tflint-ruleset-manual"+fileExt()),
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// A missing plugin directory is not an error
+	config.PluginDir = filepath.Join(dir, "not_found")
+	got, err = ListInstalledPlugins(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no plugins, but got %d plugins", len(got))
+	}
+}
+
+func Test_LatestVersion(t *testing.T) {
+	mirror := t.TempDir()
+	for _, v := range []string{"0.1.0", "0.2.0", "0.3.0-beta"} {
+		writeMirrorRelease(t, mirror, v)
+	}
+
+	globalConfig := tflint.EmptyConfig()
+	globalConfig.PluginMirror = mirror
+	config := NewInstallConfig(globalConfig, newMirrorTestPluginConfig("0.1.0"))
+
+	got, err := config.LatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "0.2.0" {
+		t.Fatalf("want=0.2.0, got=%s", got)
+	}
+}
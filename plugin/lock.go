@@ -0,0 +1,314 @@
+package plugin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/terraform-linters/tflint/tflint"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// LockFileName is the name of the plugin lock file.
+// The lock file is placed next to the config file.
+const LockFileName = ".tflint.lock.hcl"
+
+const lockFileHeader = `# This file is maintained automatically by "tflint --init".
+# Manual edits may be lost in future updates.
+`
+
+// Lock is a plugin lock file that records installed plugins and their checksums.
+// It is similar to Terraform's dependency lock file, and is intended to be
+// committed to version control.
+type Lock struct {
+	Plugins map[string]*LockedPlugin
+
+	path string
+}
+
+// LockedPlugin is a plugin recorded in the lock file.
+// Hashes are SHA-256 hashes keyed by platforms like "linux_amd64".
+// Zip hashes are recorded for all platforms in the release, but binary hashes
+// are only recorded for platforms where the plugin has been installed.
+// Verified records whether the signature of the release checksums has been verified.
+type LockedPlugin struct {
+	Name         string            `hcl:"name,label"`
+	Source       string            `hcl:"source"`
+	Version      string            `hcl:"version"`
+	Verified     bool              `hcl:"verified,optional"`
+	BinaryHashes map[string]string `hcl:"binary_hashes,optional"`
+	ZipHashes    map[string]string `hcl:"zip_hashes,optional"`
+}
+
+type lockFile struct {
+	Plugins []*LockedPlugin `hcl:"plugin,block"`
+}
+
+// LoadLock loads the lock file next to the config file.
+// If the lock file does not exist, an empty lock is returned.
+func LoadLock(config *tflint.Config) (*Lock, error) {
+	lock := &Lock{
+		Plugins: map[string]*LockedPlugin{},
+		path:    filepath.Join(filepath.Dir(config.File()), LockFileName),
+	}
+
+	src, err := os.ReadFile(lock.path)
+	if os.IsNotExist(err) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[DEBUG] Load lock file: %s", lock.path)
+
+	file, diags := hclparse.NewParser().ParseHCL(src, lock.path)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	var content lockFile
+	if diags := gohcl.DecodeBody(file.Body, nil, &content); diags.HasErrors() {
+		return nil, diags
+	}
+	for _, plugin := range content.Plugins {
+		if plugin.BinaryHashes == nil {
+			plugin.BinaryHashes = map[string]string{}
+		}
+		if plugin.ZipHashes == nil {
+			plugin.ZipHashes = map[string]string{}
+		}
+		lock.Plugins[plugin.Name] = plugin
+	}
+
+	return lock, nil
+}
+
+// Path returns the path of the lock file.
+func (l *Lock) Path() string {
+	return l.path
+}
+
+// Prune removes plugins that are no longer installed automatically.
+func (l *Lock) Prune(config *tflint.Config) {
+	for name := range l.Plugins {
+		pluginCfg, exists := config.Plugins[name]
+		if !exists || NewInstallConfig(config, pluginCfg).ManuallyInstalled() {
+			delete(l.Plugins, name)
+		}
+	}
+}
+
+// Write writes the lock file. The file is not touched if the content
+// is not changed, and is not created if there are no plugins.
+func (l *Lock) Write() error {
+	f := hclwrite.NewEmptyFile()
+	root := f.Body()
+	for _, name := range slices.Sorted(maps.Keys(l.Plugins)) {
+		plugin := l.Plugins[name]
+
+		root.AppendNewline()
+		block := root.AppendNewBlock("plugin", []string{name}).Body()
+		block.SetAttributeValue("source", cty.StringVal(plugin.Source))
+		block.SetAttributeValue("version", cty.StringVal(plugin.Version))
+		if plugin.Verified {
+			block.SetAttributeValue("verified", cty.True)
+		}
+		if len(plugin.BinaryHashes) > 0 {
+			block.SetAttributeValue("binary_hashes", cty.MapVal(hashValues(plugin.BinaryHashes)))
+		}
+		if len(plugin.ZipHashes) > 0 {
+			block.SetAttributeValue("zip_hashes", cty.MapVal(hashValues(plugin.ZipHashes)))
+		}
+	}
+	out := append([]byte(lockFileHeader), f.Bytes()...)
+
+	current, err := os.ReadFile(l.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if bytes.Equal(current, out) || (os.IsNotExist(err) && len(l.Plugins) == 0) {
+		return nil
+	}
+	log.Printf("[DEBUG] Write lock file: %s", l.path)
+	return os.WriteFile(l.path, out, 0644)
+}
+
+// Verify checks that the plugin binary matches the checksum recorded in the lock file.
+// Plugins that are not recorded in the lock file or are installed manually are not verified.
+// Plugins without a binary hash for the current platform are refused, even if the release zip
+// for the platform is recorded, since the zip hash cannot verify the installed binary.
+func (l *Lock) Verify(config *InstallConfig, path string) error {
+	locked, exists := l.Plugins[config.Name]
+	if !exists || config.ManuallyInstalled() {
+		return nil
+	}
+
+	if locked.Source != config.Source || locked.Version != config.resolvedVersion {
+		return fmt.Errorf(`Plugin "%s" is locked to %s (%s) in %s, but %s (%s) is required. Run "tflint --init" to update the lock file`, config.Name, locked.Source, locked.Version, l.path, config.Source, config.Version)
+	}
+	expected, exists := locked.BinaryHashes[platform()]
+	if !exists {
+		return l.RequireBinaryHash(config)
+	}
+	actual, err := hashFile(path)
+	if err != nil {
+		return fmt.Errorf(`Failed to calculate the checksum of "%s" plugin; %w`, config.Name, err)
+	}
+	if actual != expected {
+		return fmt.Errorf(`Plugin "%s" does not match the checksum in %s: expected=%s, actual=%s. The binary may have been modified after installation`, config.Name, l.path, expected, actual)
+	}
+
+	log.Printf(`[DEBUG] Plugin "%s" matched the checksum in the lock file`, config.Name)
+	return nil
+}
+
+// RequireVerified checks that the plugin was installed from a release whose signature has been verified.
+// Manually installed plugins are refused as they have no recorded verification.
+// Note that this does not check the binary itself, so it should be used with Verify.
+func (l *Lock) RequireVerified(config *InstallConfig) error {
+	if config.ManuallyInstalled() {
+		return fmt.Errorf(`Plugin "%s" is installed manually and has no recorded verification. Manually installed plugins cannot be used when "plugin_verification" is "required"`, config.Name)
+	}
+	locked, exists := l.Plugins[config.Name]
+	if !exists || !locked.Verified {
+		return fmt.Errorf(`Plugin "%s" has no recorded verification in %s. Configure "signing_key" and run "tflint --init" to verify the plugin, as "plugin_verification" is "required"`, config.Name, l.path)
+	}
+	return nil
+}
+
+// RequireBinaryHash checks that the lock file records the binary hash of the plugin for the current platform.
+// Plugins that are not recorded in the lock file or are installed manually are not checked.
+func (l *Lock) RequireBinaryHash(config *InstallConfig) error {
+	locked, exists := l.Plugins[config.Name]
+	if !exists || config.ManuallyInstalled() {
+		return nil
+	}
+	if _, exists := locked.BinaryHashes[platform()]; !exists {
+		return fmt.Errorf(`Plugin "%s" has no binary checksum for %s in %s. Run "tflint --init" to record the checksum for this platform`, config.Name, platform(), l.path)
+	}
+	return nil
+}
+
+// Resolve resolves the version constraint of the plugin to the version recorded in the lock file.
+// If the recorded version does not satisfy the constraint, the version remains unresolved.
+func (l *Lock) Resolve(config *InstallConfig) {
+	if config.VersionConstraints == nil || config.resolvedVersion != "" {
+		return
+	}
+	locked, exists := l.Plugins[config.Name]
+	if !exists || locked.Source != config.Source {
+		return
+	}
+	v, err := version.NewVersion(locked.Version)
+	if err != nil || !config.VersionConstraints.Check(v) {
+		return
+	}
+	log.Printf(`[DEBUG] Resolved "%s" to %s by the lock file`, config.Version, locked.Version)
+	config.resolvedVersion = locked.Version
+}
+
+// verifyChecksums checks that the downloaded checksum file matches the zip hashes
+// recorded in the lock file. If the lock file records a different version, it is not verified.
+// Since the zip file is verified by the checksum file, this ensures that the same zip is installed.
+func (l *Lock) verifyChecksums(config *InstallConfig, checksummer *Checksummer) error {
+	locked, exists := l.Plugins[config.Name]
+	if !exists || locked.Source != config.Source || locked.Version != config.resolvedVersion {
+		return nil
+	}
+
+	for _, platform := range slices.Sorted(maps.Keys(locked.ZipHashes)) {
+		filename := fmt.Sprintf("tflint-ruleset-%s_%s.zip", config.Name, platform)
+		checksum, exists := checksummer.checksums[filename]
+		if !exists {
+			continue
+		}
+		if expected, actual := locked.ZipHashes[platform], hex.EncodeToString(checksum); actual != expected {
+			return fmt.Errorf("Failed to match checksums of %s in %s: expected=%s, actual=%s", filename, l.path, expected, actual)
+		}
+	}
+	return nil
+}
+
+// verifiedRelease returns whether the lock file records the release as verified,
+// and the checksum file has the same checksum for the current platform.
+func (l *Lock) verifiedRelease(config *InstallConfig, checksummer *Checksummer) bool {
+	locked, exists := l.Plugins[config.Name]
+	if !exists || !locked.Verified || locked.Source != config.Source || locked.Version != config.resolvedVersion {
+		return false
+	}
+	expected, exists := locked.ZipHashes[platform()]
+	checksum, ok := checksummer.checksums[config.AssetName()]
+	return exists && ok && expected == hex.EncodeToString(checksum)
+}
+
+// record records the installed plugin in the lock file. If the lock file already
+// records the same version, the binary hash for the current platform is added.
+// Once verified, the version remains verified, since the checksums are the same as the verified ones.
+func (l *Lock) record(config *InstallConfig, checksummer *Checksummer, path string, verified bool) error {
+	locked, exists := l.Plugins[config.Name]
+	if !exists || locked.Source != config.Source || locked.Version != config.resolvedVersion {
+		locked = &LockedPlugin{
+			Name:         config.Name,
+			Source:       config.Source,
+			Version:      config.resolvedVersion,
+			BinaryHashes: map[string]string{},
+			ZipHashes:    map[string]string{},
+		}
+		l.Plugins[config.Name] = locked
+	}
+	locked.Verified = locked.Verified || verified
+
+	prefix := fmt.Sprintf("tflint-ruleset-%s_", config.Name)
+	for filename, checksum := range checksummer.checksums {
+		if !strings.HasPrefix(filename, prefix) || !strings.HasSuffix(filename, ".zip") {
+			continue
+		}
+		platform := strings.TrimSuffix(strings.TrimPrefix(filename, prefix), ".zip")
+		locked.ZipHashes[platform] = hex.EncodeToString(checksum)
+	}
+
+	hash, err := hashFile(path)
+	if err != nil {
+		return err
+	}
+	locked.BinaryHashes[platform()] = hash
+	return nil
+}
+
+func hashValues(hashes map[string]string) map[string]cty.Value {
+	ret := map[string]cty.Value{}
+	for platform, hash := range hashes {
+		ret[platform] = cty.StringVal(hash)
+	}
+	return ret
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func platform() string {
+	return fmt.Sprintf("%s_%s", runtime.GOOS, runtime.GOARCH)
+}
@@ -0,0 +1,429 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-version"
+	"github.com/spf13/afero"
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+func Test_Lock_Write(t *testing.T) {
+	dir := t.TempDir()
+	config := loadLockTestConfig(t, dir)
+
+	lock, err := LoadLock(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lock.Plugins) != 0 {
+		t.Fatalf("lock should be empty, but got %d plugins", len(lock.Plugins))
+	}
+
+	// Empty lock files are not created
+	if err := lock.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, LockFileName)); !os.IsNotExist(err) {
+		t.Fatalf("lock file should not be created, but got %v", err)
+	}
+
+	binary := filepath.Join(dir, "tflint-ruleset-foo")
+	if err := os.WriteFile(binary, []byte("foo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	checksummer, err := NewChecksummer(strings.NewReader(`2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae  tflint-ruleset-foo_darwin_amd64.zip
+fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9  tflint-ruleset-foo_linux_amd64.zip
+baa5a0964d3320fbc0c6a922140453c8513ea24ab8fd0577034804a967248096  tflint-ruleset-bar_linux_amd64.zip
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.record(NewInstallConfig(config, config.Plugins["foo"]), checksummer, binary, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := lock.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, LockFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `# This file is maintained automatically by "tflint --init".
+# Manual edits may be lost in future updates.
+
+plugin "foo" {
+  source   = "github.com/terraform-linters/tflint-ruleset-foo"
+  version  = "0.1.0"
+  verified = true
+  binary_hashes = {
+    ` + platform() + ` = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+  }
+  zip_hashes = {
+    darwin_amd64 = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+    linux_amd64  = "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"
+  }
+}
+`
+	if diff := cmp.Diff(expected, string(got)); diff != "" {
+		t.Fatal(diff)
+	}
+
+	loaded, err := LoadLock(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(lock.Plugins, loaded.Plugins); diff != "" {
+		t.Fatal(diff)
+	}
+
+	loaded.Prune(tflint.EmptyConfig())
+	if len(loaded.Plugins) != 0 {
+		t.Fatalf("plugins not in the config should be pruned, but got %d plugins", len(loaded.Plugins))
+	}
+}
+
+func Test_Lock_Verify(t *testing.T) {
+	dir := t.TempDir()
+	config := loadLockTestConfig(t, dir)
+
+	binary := filepath.Join(dir, "tflint-ruleset-foo")
+	if err := os.WriteFile(binary, []byte("foo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	lockPath := filepath.Join(dir, LockFileName)
+
+	tests := []struct {
+		name   string
+		config *tflint.PluginConfig
+		locked *LockedPlugin
+		err    string
+	}{
+		{
+			name:   "not locked",
+			config: config.Plugins["foo"],
+			err:    "",
+		},
+		{
+			name:   "manually installed",
+			config: &tflint.PluginConfig{Name: "foo", Enabled: true},
+			locked: &LockedPlugin{Name: "foo", Source: "github.com/terraform-linters/tflint-ruleset-foo", Version: "0.1.0"},
+			err:    "",
+		},
+		{
+			name:   "matched",
+			config: config.Plugins["foo"],
+			locked: &LockedPlugin{
+				Name:         "foo",
+				Source:       "github.com/terraform-linters/tflint-ruleset-foo",
+				Version:      "0.1.0",
+				BinaryHashes: map[string]string{platform(): "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"},
+			},
+			err: "",
+		},
+		{
+			name:   "different version",
+			config: config.Plugins["foo"],
+			locked: &LockedPlugin{
+				Name:         "foo",
+				Source:       "github.com/terraform-linters/tflint-ruleset-foo",
+				Version:      "0.2.0",
+				BinaryHashes: map[string]string{platform(): "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"},
+			},
+			err: `Plugin "foo" is locked to github.com/terraform-linters/tflint-ruleset-foo (0.2.0) in ` + lockPath + `, but github.com/terraform-linters/tflint-ruleset-foo (0.1.0) is required. Run "tflint --init" to update the lock file`,
+		},
+		{
+			name:   "no checksum for the platform",
+			config: config.Plugins["foo"],
+			locked: &LockedPlugin{
+				Name:         "foo",
+				Source:       "github.com/terraform-linters/tflint-ruleset-foo",
+				Version:      "0.1.0",
+				BinaryHashes: map[string]string{"plan9_386": "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"},
+			},
+			err: `Plugin "foo" has no binary checksum for ` + platform() + ` in ` + lockPath + `. Run "tflint --init" to record the checksum for this platform`,
+		},
+		{
+			name:   "no binary checksum but zip checksum for the platform",
+			config: config.Plugins["foo"],
+			locked: &LockedPlugin{
+				Name:         "foo",
+				Source:       "github.com/terraform-linters/tflint-ruleset-foo",
+				Version:      "0.1.0",
+				BinaryHashes: map[string]string{"plan9_386": "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"},
+				ZipHashes:    map[string]string{"plan9_386": "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9", platform(): "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"},
+			},
+			err: `Plugin "foo" has no binary checksum for ` + platform() + ` in ` + lockPath + `. Run "tflint --init" to record the checksum for this platform`,
+		},
+		{
+			name:   "mismatched",
+			config: config.Plugins["foo"],
+			locked: &LockedPlugin{
+				Name:         "foo",
+				Source:       "github.com/terraform-linters/tflint-ruleset-foo",
+				Version:      "0.1.0",
+				BinaryHashes: map[string]string{platform(): "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"},
+			},
+			err: `Plugin "foo" does not match the checksum in ` + lockPath + `: expected=fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9, actual=2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae. The binary may have been modified after installation`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lock, err := LoadLock(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.locked != nil {
+				lock.Plugins["foo"] = test.locked
+			}
+
+			err = lock.Verify(NewInstallConfig(config, test.config), binary)
+			if err == nil {
+				if test.err != "" {
+					t.Fatalf("expected error %q, but got nil", test.err)
+				}
+				return
+			}
+			if err.Error() != test.err {
+				t.Fatalf("want=%s, got=%s", test.err, err)
+			}
+		})
+	}
+}
+
+func Test_Lock_RequireBinaryHash(t *testing.T) {
+	dir := t.TempDir()
+	config := loadLockTestConfig(t, dir)
+	lockPath := filepath.Join(dir, LockFileName)
+
+	tests := []struct {
+		name   string
+		config *tflint.PluginConfig
+		locked *LockedPlugin
+		err    string
+	}{
+		{
+			name:   "not locked",
+			config: config.Plugins["foo"],
+			err:    "",
+		},
+		{
+			name:   "recorded",
+			config: config.Plugins["foo"],
+			locked: &LockedPlugin{Name: "foo", Source: "github.com/terraform-linters/tflint-ruleset-foo", Version: "0.1.0", BinaryHashes: map[string]string{platform(): "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"}},
+			err:    "",
+		},
+		{
+			name:   "recorded on another platform",
+			config: config.Plugins["foo"],
+			locked: &LockedPlugin{
+				Name:         "foo",
+				Source:       "github.com/terraform-linters/tflint-ruleset-foo",
+				Version:      "0.1.0",
+				BinaryHashes: map[string]string{"plan9_386": "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"},
+				ZipHashes:    map[string]string{platform(): "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"},
+			},
+			err: `Plugin "foo" has no binary checksum for ` + platform() + ` in ` + lockPath + `. Run "tflint --init" to record the checksum for this platform`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lock, err := LoadLock(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.locked != nil {
+				lock.Plugins["foo"] = test.locked
+			}
+
+			err = lock.RequireBinaryHash(NewInstallConfig(config, test.config))
+			if err == nil {
+				if test.err != "" {
+					t.Fatalf("expected error %q, but got nil", test.err)
+				}
+				return
+			}
+			if err.Error() != test.err {
+				t.Fatalf("want=%s, got=%s", test.err, err)
+			}
+		})
+	}
+}
+
+func Test_Lock_RequireVerified(t *testing.T) {
+	dir := t.TempDir()
+	config := loadLockTestConfig(t, dir)
+	lockPath := filepath.Join(dir, LockFileName)
+
+	tests := []struct {
+		name   string
+		config *tflint.PluginConfig
+		locked *LockedPlugin
+		err    string
+	}{
+		{
+			name:   "manually installed",
+			config: &tflint.PluginConfig{Name: "foo", Enabled: true},
+			err:    `Plugin "foo" is installed manually and has no recorded verification. Manually installed plugins cannot be used when "plugin_verification" is "required"`,
+		},
+		{
+			name:   "not locked",
+			config: config.Plugins["foo"],
+			err:    `Plugin "foo" has no recorded verification in ` + lockPath + `. Configure "signing_key" and run "tflint --init" to verify the plugin, as "plugin_verification" is "required"`,
+		},
+		{
+			name:   "not verified",
+			config: config.Plugins["foo"],
+			locked: &LockedPlugin{Name: "foo", Source: "github.com/terraform-linters/tflint-ruleset-foo", Version: "0.1.0"},
+			err:    `Plugin "foo" has no recorded verification in ` + lockPath + `. Configure "signing_key" and run "tflint --init" to verify the plugin, as "plugin_verification" is "required"`,
+		},
+		{
+			name:   "verified",
+			config: config.Plugins["foo"],
+			locked: &LockedPlugin{Name: "foo", Source: "github.com/terraform-linters/tflint-ruleset-foo", Version: "0.1.0", Verified: true},
+			err:    "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lock, err := LoadLock(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.locked != nil {
+				lock.Plugins["foo"] = test.locked
+			}
+
+			err = lock.RequireVerified(NewInstallConfig(config, test.config))
+			if err == nil {
+				if test.err != "" {
+					t.Fatalf("expected error %q, but got nil", test.err)
+				}
+				return
+			}
+			if err.Error() != test.err {
+				t.Fatalf("want=%s, got=%s", test.err, err)
+			}
+		})
+	}
+}
+
+func Test_Lock_verifyChecksums(t *testing.T) {
+	dir := t.TempDir()
+	config := loadLockTestConfig(t, dir)
+
+	lock, err := LoadLock(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.Plugins["foo"] = &LockedPlugin{
+		Name:      "foo",
+		Source:    "github.com/terraform-linters/tflint-ruleset-foo",
+		Version:   "0.1.0",
+		ZipHashes: map[string]string{"linux_amd64": "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"},
+	}
+
+	checksummer, err := NewChecksummer(strings.NewReader("2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae  tflint-ruleset-foo_linux_amd64.zip\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = lock.verifyChecksums(NewInstallConfig(config, config.Plugins["foo"]), checksummer)
+	if err == nil {
+		t.Fatal("an error should have occurred, but it did not")
+	}
+	expected := "Failed to match checksums of tflint-ruleset-foo_linux_amd64.zip in " + filepath.Join(dir, LockFileName) + ": expected=fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9, actual=2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+	if err.Error() != expected {
+		t.Fatalf("want=%s, got=%s", expected, err)
+	}
+}
+
+func Test_Lock_Resolve(t *testing.T) {
+	dir := t.TempDir()
+	config := loadLockTestConfig(t, dir)
+
+	lock, err := LoadLock(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock.Plugins["foo"] = &LockedPlugin{
+		Name:    "foo",
+		Source:  "github.com/terraform-linters/tflint-ruleset-foo",
+		Version: "0.30.1",
+	}
+
+	tests := []struct {
+		name    string
+		source  string
+		version string
+		want    string
+	}{
+		{
+			name:    "exact version",
+			source:  "github.com/terraform-linters/tflint-ruleset-foo",
+			version: "0.29.0",
+			want:    "0.29.0",
+		},
+		{
+			name:    "satisfied constraint",
+			source:  "github.com/terraform-linters/tflint-ruleset-foo",
+			version: "~> 0.30.0",
+			want:    "0.30.1",
+		},
+		{
+			name:    "unsatisfied constraint",
+			source:  "github.com/terraform-linters/tflint-ruleset-foo",
+			version: ">= 0.31",
+			want:    "",
+		},
+		{
+			name:    "different source",
+			source:  "github.com/terraform-linters/tflint-ruleset-bar",
+			version: "~> 0.30.0",
+			want:    "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pluginCfg := &tflint.PluginConfig{Name: "foo", Enabled: true, Source: test.source, Version: test.version}
+			if _, err := version.NewVersion(test.version); err != nil {
+				pluginCfg.VersionConstraints = version.MustConstraints(version.NewConstraint(test.version))
+			}
+			installCfg := NewInstallConfig(config, pluginCfg)
+
+			lock.Resolve(installCfg)
+
+			if got := installCfg.ResolvedVersion(); got != test.want {
+				t.Fatalf("want=%s, got=%s", test.want, got)
+			}
+		})
+	}
+}
+
+func loadLockTestConfig(t *testing.T, dir string) *tflint.Config {
+	path := filepath.Join(dir, ".tflint.hcl")
+	config := `
+plugin "foo" {
+  enabled = true
+  source  = "github.com/terraform-linters/tflint-ruleset-foo"
+  version = "0.1.0"
+}`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := tflint.LoadConfig(afero.Afero{Fs: afero.NewOsFs()}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
@@ -0,0 +1,195 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/terraform-linters/tflint-plugin-sdk/logger"
+)
+
+// stderrTailLines is the number of the last lines of the plugin stderr attached to errors.
+const stderrTailLines = 20
+
+// goPluginLogPattern matches a line written by the go-plugin logger, like
+// "15:04:05 [DEBUG] go-plugin@v1.7.0/client.go:1244: tflint-ruleset-foo: message".
+// Lines of the plugin stderr are prefixed with the executable name of the plugin.
+var goPluginLogPattern = regexp.MustCompile(`^\S+ \[([A-Z]+)\]\s+\S+:\d+: (.*)$`)
+
+// stderrLogPattern matches a line written by the logger of plugins built with the SDK,
+// like "15:04:05 [DEBUG] host2plugin/server.go:20: message".
+var stderrLogPattern = regexp.MustCompile(`^\S+ \[([A-Z]+)\]\s+(.*)$`)
+
+// pluginLogs routes logs of go-plugin and the stderr of plugin processes.
+var pluginLogs = &logRouter{plugins: map[string]*pluginLog{}}
+
+// logRouter receives logs from the go-plugin logger and routes them to the TFLint logger.
+// Lines of the plugin stderr are also recorded in the log of the plugin.
+type logRouter struct {
+	once sync.Once
+
+	mu      sync.Mutex
+	buf     []byte
+	plugins map[string]*pluginLog
+}
+
+// pluginLog holds the stderr of a plugin process.
+// If the log directory is set, the stderr is also written to {name}.log in the directory.
+type pluginLog struct {
+	name string
+	file *os.File
+
+	mu      sync.Mutex
+	written int
+	tail    []string
+	// panic is the last line starting with "panic: ", which is kept even after
+	// it drops out of the tail because the stack trace is often longer than the tail.
+	panic   string
+	panicAt int
+}
+
+// install replaces the output of the go-plugin logger with the router.
+// The logger is shared by the SDK, so it is configured only once.
+func (r *logRouter) install() {
+	r.once.Do(func() {
+		l := logger.Logger()
+		if resettable, ok := l.(hclog.OutputResettable); ok {
+			if err := resettable.ResetOutput(&hclog.LoggerOptions{Output: r}); err != nil {
+				log.Printf("[WARN] Failed to capture plugin logs: %s", err)
+				return
+			}
+			// Levels are filtered by the TFLint logger, and the plugin stderr is always recorded
+			l.SetLevel(hclog.Trace)
+		}
+	})
+}
+
+// register returns the log of the plugin launched with the executable.
+// If the log directory is set, the log file is opened in append mode.
+func (r *logRouter) register(name string, executable string, logDir string) (*pluginLog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := filepath.Base(executable)
+	l, exists := r.plugins[key]
+	if !exists || l.name != name {
+		l = &pluginLog{name: name}
+		r.plugins[key] = l
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if logDir != "" && l.file == nil {
+		if err := os.MkdirAll(logDir, 0755); err != nil {
+			return nil, fmt.Errorf("Failed to create plugin log directory; %w", err)
+		}
+		file, err := os.OpenFile(filepath.Join(logDir, name+".log"), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to open plugin log file; %w", err)
+		}
+		l.file = file
+	}
+	return l, nil
+}
+
+// Write implements io.Writer for the go-plugin logger.
+func (r *logRouter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.buf = append(r.buf, p...)
+	for {
+		idx := bytes.IndexByte(r.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		r.route(string(r.buf[:idx]))
+		r.buf = r.buf[idx+1:]
+	}
+	return len(p), nil
+}
+
+func (r *logRouter) route(line string) {
+	match := goPluginLogPattern.FindStringSubmatch(line)
+	if match == nil {
+		log.Printf("[DEBUG] go-plugin: %s", line)
+		return
+	}
+	level, message := match[1], match[2]
+
+	// Empty lines of the plugin stderr are written without the separator
+	executable, stderr, _ := strings.Cut(message, ": ")
+	if l, exists := r.plugins[executable]; exists {
+		l.record(stderr)
+
+		// Prefer the level in the plugin log to the level guessed by go-plugin
+		if match := stderrLogPattern.FindStringSubmatch(stderr); match != nil {
+			level, stderr = match[1], match[2]
+		}
+		log.Printf(`[%s] plugin "%s": %s`, level, l.name, stderr)
+		return
+	}
+	log.Printf("[%s] go-plugin: %s", level, message)
+}
+
+func (l *pluginLog) record(line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.written++
+	if strings.HasPrefix(line, "panic: ") {
+		l.panic, l.panicAt = line, l.written
+	}
+	l.tail = append(l.tail, line)
+	if len(l.tail) > stderrTailLines {
+		l.tail = l.tail[len(l.tail)-stderrTailLines:]
+	}
+	if l.file != nil {
+		if _, err := fmt.Fprintln(l.file, line); err != nil {
+			log.Printf(`[WARN] Failed to write the log of "%s" plugin: %s`, l.name, err)
+		}
+	}
+}
+
+// lines returns the number of lines written to the stderr so far.
+func (l *pluginLog) lines() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.written
+}
+
+// linesSince returns the last lines written after the stderr had the given number of lines.
+// If a panic message was written but dropped out of the tail, it is returned first.
+func (l *pluginLog) linesSince(written int) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := min(l.written-written, len(l.tail))
+	lines := []string{}
+	if l.panicAt > written && l.panicAt <= l.written-n {
+		lines = append(lines, l.panic, "...")
+	}
+	return append(lines, l.tail[len(l.tail)-n:]...)
+}
+
+// StderrError is an error of a plugin call with the lines that the plugin wrote to stderr during the call.
+type StderrError struct {
+	Plugin string
+	Stderr []string
+	Err    error
+}
+
+func (e *StderrError) Error() string {
+	return fmt.Sprintf("%s\n\nStderr of \"%s\" plugin:\n  %s", e.Err, e.Plugin, strings.Join(e.Stderr, "\n  "))
+}
+
+func (e *StderrError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,81 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_logRouter(t *testing.T) {
+	logDir := t.TempDir()
+	router := &logRouter{plugins: map[string]*pluginLog{}}
+
+	foo, err := router.register("foo", "/path/to/tflint-ruleset-foo", logDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bar, err := router.register("bar", "/path/to/tflint-ruleset-bar", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	written := foo.lines()
+	fmt.Fprint(router, "15:04:05 [DEBUG] go-plugin@v1.7.0/client.go:1244: tflint-ruleset-foo: 15:04:05 [ERROR] main.go:10: failed\n")
+	fmt.Fprint(router, "15:04:05 [DEBUG] go-plugin@v1.7.0/client.go:1244: tflint-ruleset-bar: message\n")
+	// Lines may be written in pieces
+	fmt.Fprint(router, "15:04:05 [DEBUG] go-plugin@v1.7.0/client.go:1244: tflint-ruleset-foo: panic: ")
+	fmt.Fprint(router, "crashed\n15:04:05 [DEBUG] go-plugin@v1.7.0/client.go:1244: tflint-ruleset-foo\n15:04:05 [DEBUG] go-plugin@v1.7.0/client.go:814: plugin started: pid=1\n")
+
+	if diff := cmp.Diff([]string{"15:04:05 [ERROR] main.go:10: failed", "panic: crashed", ""}, foo.linesSince(written)); diff != "" {
+		t.Errorf("foo: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"message"}, bar.linesSince(0)); diff != "" {
+		t.Errorf("bar: %s", diff)
+	}
+	if got := foo.linesSince(foo.lines()); len(got) != 0 {
+		t.Errorf("Expected no lines, but got %v", got)
+	}
+
+	content, err := os.ReadFile(filepath.Join(logDir, "foo.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("15:04:05 [ERROR] main.go:10: failed\npanic: crashed\n\n", string(content)); diff != "" {
+		t.Error(diff)
+	}
+	if _, err := os.Stat(filepath.Join(logDir, "bar.log")); !os.IsNotExist(err) {
+		t.Errorf("bar.log should not exist: %s", err)
+	}
+}
+
+func Test_pluginLog_linesSince(t *testing.T) {
+	l := &pluginLog{name: "foo"}
+	for i := range stderrTailLines + 5 {
+		l.record(fmt.Sprintf("line %d", i))
+	}
+
+	got := l.linesSince(0)
+	if len(got) != stderrTailLines {
+		t.Fatalf("Expected %d lines, but got %d", stderrTailLines, len(got))
+	}
+	if got[0] != "line 5" || got[len(got)-1] != fmt.Sprintf("line %d", stderrTailLines+4) {
+		t.Errorf("Unexpected lines: %v", got)
+	}
+
+	// The panic message is kept even if the stack trace is longer than the tail
+	written := l.lines()
+	l.record("panic: crashed")
+	for i := range stderrTailLines {
+		l.record(fmt.Sprintf("stack %d", i))
+	}
+	got = l.linesSince(written)
+	if len(got) != stderrTailLines+2 || got[0] != "panic: crashed" || got[1] != "..." || got[2] != "stack 0" {
+		t.Errorf("Unexpected lines: %v", got)
+	}
+	if got := l.linesSince(l.lines()); len(got) != 0 {
+		t.Errorf("Expected no lines, but got %v", got)
+	}
+}
@@ -1,9 +1,7 @@
 package plugin
 
 import (
-	plugin "github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/go-version"
-	"github.com/terraform-linters/tflint-plugin-sdk/plugin/host2plugin"
 )
 
 // PluginRoot is the root directory of the plugins
@@ -19,14 +17,12 @@ var SDKVersionConstraints = version.MustConstraints(version.NewConstraint(">= 0.
 // Plugin is an object handling plugins
 // Basically, it is a wrapper for go-plugin and provides an API to handle them collectively.
 type Plugin struct {
-	RuleSets map[string]*host2plugin.Client
-
-	clients map[string]*plugin.Client
+	RuleSets map[string]*RuleSet
 }
 
 // Clean is a helper for ending plugin processes
 func (p *Plugin) Clean() {
-	for _, client := range p.clients {
-		client.Kill()
+	for _, ruleset := range p.RuleSets {
+		ruleset.Kill()
 	}
 }
@@ -0,0 +1,209 @@
+package plugin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// registryIndexFileName is the name of the index file served by plugin registries.
+const registryIndexFileName = "index.json"
+
+// releaseHTTPClient is the HTTP client to fetch releases from plugin registries and GitLab.
+var releaseHTTPClient = &http.Client{
+	Transport: &requestLoggingTransport{http.DefaultTransport},
+}
+
+// registryIndex is an index of plugin releases served by a plugin registry.
+// The index must be served at {source}/index.json, for example:
+//
+//	{
+//	  "versions": [
+//	    {
+//	      "version": "0.1.0",
+//	      "assets": [
+//	        {"name": "checksums.txt", "url": "0.1.0/checksums.txt"},
+//	        {"name": "tflint-ruleset-foo_linux_amd64.zip", "url": "0.1.0/tflint-ruleset-foo_linux_amd64.zip", "sha256": "..."}
+//	      ]
+//	    }
+//	  ]
+//	}
+//
+// Asset URLs can be relative to the index URL. If the release does not contain
+// checksums.txt, it is generated from the SHA-256 hashes of assets.
+type registryIndex struct {
+	Versions []*registryRelease `json:"versions"`
+}
+
+// registryRelease is a release listed in the registry index.
+type registryRelease struct {
+	Version string           `json:"version"`
+	Assets  []*registryAsset `json:"assets"`
+}
+
+// registryAsset is an asset contained in the registry release.
+type registryAsset struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// registrySource fetches releases from a plugin registry.
+type registrySource struct {
+	config  *InstallConfig
+	index   *registryIndex
+	release *registryRelease
+}
+
+func (s *registrySource) String() string {
+	return fmt.Sprintf("plugin registry %s", s.config.Source)
+}
+
+func (s *registrySource) versions() ([]*version.Version, error) {
+	index, err := s.fetchIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []*version.Version{}
+	for _, release := range index.Versions {
+		v, err := version.NewVersion(release.Version)
+		if err != nil {
+			log.Printf("[DEBUG] Ignore release %s: %s", release.Version, err)
+			continue
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+func (s *registrySource) fetch() error {
+	index, err := s.fetchIndex()
+	if err != nil {
+		return err
+	}
+
+	for _, release := range index.Versions {
+		if release.Version == s.config.resolvedVersion {
+			s.release = release
+			return nil
+		}
+	}
+	return fmt.Errorf("version %s not found in %s", s.config.resolvedVersion, s.indexURL())
+}
+
+func (s *registrySource) download(filename string) (*os.File, error) {
+	var asset *registryAsset
+	for _, a := range s.release.Assets {
+		if a.Name == filename {
+			asset = a
+			break
+		}
+	}
+	if asset == nil {
+		if filename == "checksums.txt" {
+			return s.generateChecksums()
+		}
+		return nil, fmt.Errorf("file not found in the plugin registry. Does the release contain the file with the correct name ?")
+	}
+
+	u, err := url.Parse(s.indexURL())
+	if err != nil {
+		return nil, err
+	}
+	assetURL, err := u.Parse(asset.URL)
+	if err != nil {
+		return nil, err
+	}
+	body, err := s.get(assetURL.String())
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	file, err := os.CreateTemp("", "tflint-download-temp-file-*")
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(file, hash), body); err != nil {
+		return file, err
+	}
+	if asset.SHA256 != "" {
+		if actual := hex.EncodeToString(hash.Sum(nil)); actual != asset.SHA256 {
+			return file, fmt.Errorf("Failed to match checksums in the registry index: expected=%s, actual=%s", asset.SHA256, actual)
+		}
+	}
+	if _, err := file.Seek(0, 0); err != nil {
+		return file, err
+	}
+
+	log.Printf("[DEBUG] Downloaded to %s", file.Name())
+	return file, nil
+}
+
+// generateChecksums generates a checksum file from the hashes in the registry index.
+func (s *registrySource) generateChecksums() (*os.File, error) {
+	file, err := os.CreateTemp("", "tflint-download-temp-file-*")
+	if err != nil {
+		return nil, err
+	}
+	for _, asset := range s.release.Assets {
+		if asset.SHA256 == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(file, "%s  %s\n", asset.SHA256, asset.Name); err != nil {
+			return file, err
+		}
+	}
+	if _, err := file.Seek(0, 0); err != nil {
+		return file, err
+	}
+
+	log.Printf("[DEBUG] Generated checksums.txt from the registry index to %s", file.Name())
+	return file, nil
+}
+
+func (s *registrySource) fetchIndex() (*registryIndex, error) {
+	if s.index != nil {
+		return s.index, nil
+	}
+
+	body, err := s.get(s.indexURL())
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var index registryIndex
+	if err := json.NewDecoder(body).Decode(&index); err != nil {
+		return nil, fmt.Errorf("Failed to parse %s: %w", s.indexURL(), err)
+	}
+	s.index = &index
+	return s.index, nil
+}
+
+func (s *registrySource) indexURL() string {
+	return strings.TrimSuffix(s.config.Source, "/") + "/" + registryIndexFileName
+}
+
+func (s *registrySource) get(url string) (io.ReadCloser, error) {
+	resp, err := releaseHTTPClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
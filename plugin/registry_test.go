@@ -0,0 +1,251 @@
+package plugin
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+func Test_Install_registry(t *testing.T) {
+	tests := []struct {
+		name      string
+		checksums bool
+	}{
+		{
+			name:      "with checksums.txt",
+			checksums: true,
+		},
+		{
+			name:      "without checksums.txt",
+			checksums: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			original := PluginRoot
+			PluginRoot = t.TempDir()
+			defer func() { PluginRoot = original }()
+
+			server := newTestRegistry(t, test.checksums, "0.1.0")
+			config := NewInstallConfig(tflint.EmptyConfig(), newRegistryTestPluginConfig(server.URL, "0.1.0"))
+
+			// Signature verification is skipped as no signing key is configured
+			path, err := config.Install(nil)
+			if !errors.Is(err, ErrPluginNotVerified) {
+				t.Fatalf("Expected ErrPluginNotVerified, but got %v", err)
+			}
+
+			expected := filepath.Join(PluginRoot, strings.ReplaceAll(strings.TrimPrefix(server.URL, "https://"), ":", "_"), "ns", "tflint-ruleset-foo", "0.1.0", "tflint-ruleset-foo"+fileExt())
+			if path != expected {
+				t.Fatalf("Installed path is invalid: expected=%s, got=%s", expected, path)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != "0.1.0" {
+				t.Fatalf("Installed binary is invalid: got=%s", got)
+			}
+		})
+	}
+}
+
+func Test_Install_registry_errors(t *testing.T) {
+	original := PluginRoot
+	PluginRoot = t.TempDir()
+	defer func() { PluginRoot = original }()
+
+	server := newTestRegistry(t, false, "0.1.0")
+
+	tests := []struct {
+		name   string
+		source string
+		err    string
+	}{
+		{
+			name:   "version not found",
+			source: server.URL + "/ns/tflint-ruleset-foo",
+			err:    fmt.Sprintf("Failed to fetch plugin registry %s/ns/tflint-ruleset-foo: version 0.2.0 not found in %s/ns/tflint-ruleset-foo/index.json", server.URL, server.URL),
+		},
+		{
+			name:   "index not found",
+			source: server.URL + "/ns/tflint-ruleset-bar",
+			err:    fmt.Sprintf("Failed to fetch plugin registry %s/ns/tflint-ruleset-bar: GET %s/ns/tflint-ruleset-bar/index.json: 404 Not Found", server.URL, server.URL),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pluginCfg := newRegistryTestPluginConfig(server.URL, "0.2.0")
+			pluginCfg.Source = test.source
+			config := NewInstallConfig(tflint.EmptyConfig(), pluginCfg)
+
+			_, err := config.Install(nil)
+			if err == nil {
+				t.Fatal("An error should have occurred, but it did not occur")
+			}
+			if err.Error() != test.err {
+				t.Fatalf("Error message not matched: want=%s, got=%s", test.err, err)
+			}
+		})
+	}
+}
+
+func Test_Install_registry_checksumMismatch(t *testing.T) {
+	original := PluginRoot
+	PluginRoot = t.TempDir()
+	defer func() { PluginRoot = original }()
+
+	index := registryIndex{
+		Versions: []*registryRelease{
+			{
+				Version: "0.1.0",
+				Assets: []*registryAsset{
+					{Name: mirrorTestAssetName(), URL: "asset.zip", SHA256: fmt.Sprintf("%x", sha256.Sum256([]byte("foo")))},
+				},
+			},
+		},
+	}
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ns/tflint-ruleset-foo/index.json":
+			if err := json.NewEncoder(w).Encode(index); err != nil {
+				t.Error(err)
+			}
+		case "/ns/tflint-ruleset-foo/asset.zip":
+			fmt.Fprint(w, "bar")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	useTestHTTPClient(t, server)
+
+	config := NewInstallConfig(tflint.EmptyConfig(), newRegistryTestPluginConfig(server.URL, "0.1.0"))
+
+	_, err := config.Install(nil)
+	if err == nil {
+		t.Fatal("An error should have occurred, but it did not occur")
+	}
+	expected := fmt.Sprintf("Failed to download %s: Failed to match checksums in the registry index: expected=%x, actual=%x", mirrorTestAssetName(), sha256.Sum256([]byte("foo")), sha256.Sum256([]byte("bar")))
+	if err.Error() != expected {
+		t.Fatalf("Error message not matched: want=%s, got=%s", expected, err)
+	}
+}
+
+func Test_ResolveVersion_registry(t *testing.T) {
+	server := newTestRegistry(t, true, "0.1.0", "0.2.0", "0.3.0-beta", "1.0.0")
+
+	tests := []struct {
+		constraint string
+		want       string
+	}{
+		{
+			constraint: "~> 0.1",
+			want:       "0.2.0",
+		},
+		{
+			constraint: ">= 0.1",
+			want:       "1.0.0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.constraint, func(t *testing.T) {
+			pluginCfg := newRegistryTestPluginConfig(server.URL, test.constraint)
+			pluginCfg.VersionConstraints = version.MustConstraints(version.NewConstraint(test.constraint))
+			config := NewInstallConfig(tflint.EmptyConfig(), pluginCfg)
+
+			if err := config.ResolveVersion(); err != nil {
+				t.Fatal(err)
+			}
+			if config.ResolvedVersion() != test.want {
+				t.Fatalf("want=%s, got=%s", test.want, config.ResolvedVersion())
+			}
+		})
+	}
+}
+
+func newRegistryTestPluginConfig(serverURL string, version string) *tflint.PluginConfig {
+	return &tflint.PluginConfig{
+		Name:           "foo",
+		Enabled:        true,
+		Version:        version,
+		Source:         serverURL + "/ns/tflint-ruleset-foo",
+		SourceHost:     strings.TrimPrefix(serverURL, "https://"),
+		SourceOwner:    "ns",
+		SourceRepo:     "tflint-ruleset-foo",
+		SourceRegistry: true,
+	}
+}
+
+// newTestRegistry starts a registry that serves releases whose binary content is the version.
+// If checksums is false, checksums.txt is not served and is generated from the index.
+func newTestRegistry(t *testing.T, checksums bool, versions ...string) *httptest.Server {
+	files := map[string][]byte{}
+	index := registryIndex{}
+
+	for _, v := range versions {
+		buf := new(bytes.Buffer)
+		w := zip.NewWriter(buf)
+		binary, err := w.Create("tflint-ruleset-foo" + fileExt())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := binary.Write([]byte(v)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		hash := fmt.Sprintf("%x", sha256.Sum256(buf.Bytes()))
+
+		release := &registryRelease{Version: v}
+		// Assets are served from a different path than the index to test URL resolution
+		files["/downloads/"+v+"/"+mirrorTestAssetName()] = buf.Bytes()
+		release.Assets = append(release.Assets, &registryAsset{Name: mirrorTestAssetName(), URL: "../../downloads/" + v + "/" + mirrorTestAssetName(), SHA256: hash})
+		if checksums {
+			files["/ns/tflint-ruleset-foo/"+v+"/checksums.txt"] = []byte(fmt.Sprintf("%s  %s\n", hash, mirrorTestAssetName()))
+			release.Assets = append(release.Assets, &registryAsset{Name: "checksums.txt", URL: v + "/checksums.txt"})
+		}
+		index.Versions = append(index.Versions, release)
+	}
+	src, err := json.Marshal(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files["/ns/tflint-ruleset-foo/index.json"] = src
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, exists := files[r.URL.Path]
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+		if _, err := w.Write(content); err != nil {
+			t.Error(err)
+		}
+	}))
+	t.Cleanup(server.Close)
+	useTestHTTPClient(t, server)
+	return server
+}
+
+func useTestHTTPClient(t *testing.T, server *httptest.Server) {
+	original := releaseHTTPClient
+	releaseHTTPClient = server.Client()
+	t.Cleanup(func() { releaseHTTPClient = original })
+}
@@ -0,0 +1,263 @@
+package plugin
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	plugin "github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/go-version"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	"github.com/terraform-linters/tflint-plugin-sdk/plugin/host2plugin"
+	sdk "github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// exitWait is how long to wait for the plugin process to exit after the connection is lost.
+// The call may fail before the exit of a crashed process is detected.
+const exitWait = 200 * time.Millisecond
+
+// CallError is an error returned when a plugin crashes or does not respond.
+// Rule is the last rule that read its config or emitted an issue during Check,
+// and is empty if unknown. It is not necessarily the rule that was running.
+type CallError struct {
+	Plugin   string
+	Method   string
+	Rule     string
+	Timeout  time.Duration
+	TimedOut bool
+	Err      error
+}
+
+func (e *CallError) Error() string {
+	rule := ""
+	if e.Rule != "" {
+		rule = fmt.Sprintf(` (last rule to access TFLint: "%s")`, e.Rule)
+	}
+	if e.TimedOut {
+		return fmt.Sprintf(`Plugin "%s" did not respond to %s within %s%s. The plugin process was killed`, e.Plugin, e.Method, e.Timeout, rule)
+	}
+	return fmt.Sprintf(`Plugin "%s" exited unexpectedly during %s%s; %s`, e.Plugin, e.Method, rule, e.Err)
+}
+
+func (e *CallError) Unwrap() error {
+	return e.Err
+}
+
+// RuleSet is a ruleset served by a plugin process.
+// Each call is wrapped with a timeout if set. If the plugin crashes or does not respond,
+// a *CallError is returned. A crashed plugin can be launched again with Restart.
+type RuleSet struct {
+	name    string
+	newCmd  func() *exec.Cmd
+	timeout time.Duration
+	logDir  string
+	log     *pluginLog
+
+	// mu guards the process against Restart
+	mu      sync.Mutex
+	client  *plugin.Client
+	rpc     plugin.ClientProtocol
+	ruleset *host2plugin.Client
+}
+
+// newRuleSet launches a plugin process with the command.
+// The command is called again when the plugin is restarted.
+// If the timeout is zero, calls wait for the plugin as long as it takes.
+// If the log directory is set, the stderr of the plugin is written to the directory.
+func newRuleSet(name string, newCmd func() *exec.Cmd, timeout time.Duration, logDir string) (*RuleSet, error) {
+	r := &RuleSet{name: name, newCmd: newCmd, timeout: timeout, logDir: logDir}
+	if err := r.start(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RuleSet) start() error {
+	cmd := r.newCmd()
+	pluginLogs.install()
+	l, err := pluginLogs.register(r.name, cmd.Path, r.logDir)
+	if err != nil {
+		return err
+	}
+	r.log = l
+	if r.logDir != "" && os.Getenv("TFLINT_LOG") == "" {
+		// Plugins log nothing by default. Let them write debug logs to the log files.
+		// go-plugin adds the environment variables of TFLint after this.
+		cmd.Env = append(cmd.Env, "TFLINT_LOG=debug")
+	}
+
+	client := host2plugin.NewClient(&host2plugin.ClientOpts{Cmd: cmd})
+	rpcClient, err := client.Client()
+	if err != nil {
+		client.Kill()
+		return err
+	}
+	raw, err := rpcClient.Dispense("ruleset")
+	if err != nil {
+		client.Kill()
+		return err
+	}
+
+	r.client = client
+	r.rpc = rpcClient
+	r.ruleset = raw.(*host2plugin.Client)
+	return nil
+}
+
+// Restart kills the plugin process and launches it again.
+// Note that the configs applied to the plugin are lost.
+func (r *RuleSet) Restart() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	log.Printf(`[INFO] Restart plugin "%s"`, r.name)
+	r.client.Kill()
+	return r.start()
+}
+
+// Kill ends the plugin process.
+func (r *RuleSet) Kill() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.client.Kill()
+}
+
+// Exited returns whether the plugin process has exited.
+func (r *RuleSet) Exited() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.client.Exited()
+}
+
+// RuleSetName returns the name of the ruleset.
+func (r *RuleSet) RuleSetName() (string, error) {
+	return call(r, "RuleSetName", nil, (*host2plugin.Client).RuleSetName)
+}
+
+// RuleSetVersion returns the version of the ruleset.
+func (r *RuleSet) RuleSetVersion() (string, error) {
+	return call(r, "RuleSetVersion", nil, (*host2plugin.Client).RuleSetVersion)
+}
+
+// RuleNames returns the names of rules in the ruleset.
+func (r *RuleSet) RuleNames() ([]string, error) {
+	return call(r, "RuleNames", nil, (*host2plugin.Client).RuleNames)
+}
+
+// VersionConstraints returns the TFLint version constraints required by the plugin.
+func (r *RuleSet) VersionConstraints() (version.Constraints, error) {
+	return call(r, "VersionConstraints", nil, (*host2plugin.Client).VersionConstraints)
+}
+
+// SDKVersion returns the SDK version of the plugin.
+func (r *RuleSet) SDKVersion() (*version.Version, error) {
+	return call(r, "SDKVersion", nil, (*host2plugin.Client).SDKVersion)
+}
+
+// ConfigSchema returns the schema of the plugin config.
+func (r *RuleSet) ConfigSchema() (*hclext.BodySchema, error) {
+	return call(r, "ConfigSchema", nil, (*host2plugin.Client).ConfigSchema)
+}
+
+// ApplyGlobalConfig applies the config shared by all plugins.
+func (r *RuleSet) ApplyGlobalConfig(config *sdk.Config) error {
+	_, err := call(r, "ApplyGlobalConfig", nil, func(c *host2plugin.Client) (struct{}, error) {
+		return struct{}{}, c.ApplyGlobalConfig(config)
+	})
+	return err
+}
+
+// ApplyConfig applies the plugin config.
+func (r *RuleSet) ApplyConfig(content *hclext.BodyContent, sources map[string][]byte) error {
+	_, err := call(r, "ApplyConfig", nil, func(c *host2plugin.Client) (struct{}, error) {
+		return struct{}{}, c.ApplyConfig(content, sources)
+	})
+	return err
+}
+
+// Check runs the rules in the plugin against the module served by the server.
+// If the check fails, the lines that the plugin wrote to stderr during the check are attached to the error.
+func (r *RuleSet) Check(server *GRPCServer) error {
+	r.mu.Lock()
+	l := r.log
+	r.mu.Unlock()
+	written := l.lines()
+
+	_, err := call(r, "Check", server, func(c *host2plugin.Client) (struct{}, error) {
+		return struct{}{}, c.Check(server)
+	})
+	if err != nil {
+		if stderr := l.linesSince(written); len(stderr) > 0 {
+			return &StderrError{Plugin: r.name, Stderr: stderr, Err: err}
+		}
+	}
+	return err
+}
+
+// call calls the plugin with the timeout. If the call does not finish in time,
+// the plugin process is killed so that it does not block TFLint.
+// If the server is passed, the last rule that accessed it is reported in errors.
+func call[T any](r *RuleSet, method string, server *GRPCServer, fn func(*host2plugin.Client) (T, error)) (T, error) {
+	r.mu.Lock()
+	client, rpc, ruleset := r.client, r.rpc, r.ruleset
+	r.mu.Unlock()
+
+	type result struct {
+		value T
+		err   error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		value, err := fn(ruleset)
+		ch <- result{value: value, err: err}
+	}()
+
+	var timeout <-chan time.Time
+	if r.timeout > 0 {
+		timer := time.NewTimer(r.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var ret result
+	select {
+	case ret = <-ch:
+		if ret.err == nil {
+			return ret.value, nil
+		}
+	case <-timeout:
+		log.Printf(`[ERROR] Plugin "%s" did not respond to %s within %s. Kill the plugin process`, r.name, method, r.timeout)
+		client.Kill()
+		// Wait for the call to be aborted by the kill
+		ret = <-ch
+		return ret.value, r.callError(method, server, ret.err, true)
+	}
+
+	// Errors returned by the plugin itself are returned as is. The SDK drops
+	// gRPC status codes, so the connection is checked by pinging the plugin.
+	if rpc.Ping() == nil {
+		return ret.value, ret.err
+	}
+	// Calls to a crashed plugin fail with a connection error,
+	// but the exit may not be detected yet
+	for deadline := time.Now().Add(exitWait); !client.Exited() && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if client.Exited() {
+		return ret.value, r.callError(method, server, ret.err, false)
+	}
+	return ret.value, ret.err
+}
+
+func (r *RuleSet) callError(method string, server *GRPCServer, err error, timedOut bool) *CallError {
+	callErr := &CallError{Plugin: r.name, Method: method, Timeout: r.timeout, TimedOut: timedOut, Err: err}
+	if server != nil {
+		callErr.Rule = server.LastRule()
+	}
+	return callErr
+}
@@ -0,0 +1,142 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+func Test_RuleSet_Check(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	original := PluginRoot
+	PluginRoot = filepath.Join(cwd, "test-fixtures", "plugins")
+	defer func() { PluginRoot = original }()
+
+	tests := []struct {
+		name     string
+		mode     string
+		timedOut bool
+		err      string
+		stderr   string
+		alive    bool
+	}{
+		{
+			name: "ok",
+			mode: "",
+		},
+		{
+			name:     "hang",
+			mode:     "hang",
+			timedOut: true,
+			err:      `Plugin "unstable" did not respond to Check within 1s (last rule to access TFLint: "unstable_rule"). The plugin process was killed`,
+		},
+		{
+			name:   "crash",
+			mode:   "crash",
+			stderr: "panic: unstable_rule crashed",
+		},
+		{
+			name:  "error",
+			mode:  "error",
+			err:   "unstable_rule failed",
+			alive: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// The plugin process inherits the environment variable
+			t.Setenv("TFLINT_RULESET_UNSTABLE_MODE", test.mode)
+
+			plugin, err := Discovery(&tflint.Config{
+				Plugins: map[string]*tflint.PluginConfig{
+					"unstable": {
+						Name:        "unstable",
+						Enabled:     true,
+						CallTimeout: time.Second,
+					},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer plugin.Clean()
+			ruleset := plugin.RuleSets["unstable"]
+			if _, err := ruleset.VersionConstraints(); err != nil {
+				t.Fatal(err)
+			}
+			if err := ruleset.ApplyGlobalConfig(tflint.EmptyConfig().ToPluginConfig()); err != nil {
+				t.Fatal(err)
+			}
+
+			runner := tflint.TestRunner(t, map[string]string{"main.tf": ""})
+			err = ruleset.Check(NewGRPCServer(runner, runner, runner.Files(), SDKVersion))
+			if test.mode == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error occurred: %s", err)
+				}
+				return
+			}
+
+			if test.alive {
+				// Errors returned by the plugin are not crashes
+				var callErr *CallError
+				if errors.As(err, &callErr) {
+					t.Fatalf("Unexpected CallError: %s", err)
+				}
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("want=%s, got=%v", test.err, err)
+				}
+				if ruleset.Exited() {
+					t.Fatal("The plugin process should not exit")
+				}
+				return
+			}
+
+			var callErr *CallError
+			if !errors.As(err, &callErr) {
+				t.Fatalf("Expected CallError, but got %v", err)
+			}
+			if callErr.Plugin != "unstable" || callErr.Rule != "unstable_rule" || callErr.TimedOut != test.timedOut {
+				t.Fatalf("Unexpected error: %#v", callErr)
+			}
+			if test.err != "" && err.Error() != test.err {
+				t.Fatalf("want=%s, got=%s", test.err, err)
+			}
+			if test.stderr != "" {
+				var stderrErr *StderrError
+				if !errors.As(err, &stderrErr) {
+					t.Fatalf("Expected StderrError, but got %v", err)
+				}
+				if !slices.Contains(stderrErr.Stderr, test.stderr) {
+					t.Fatalf("Expected %q in stderr, but got %v", test.stderr, stderrErr.Stderr)
+				}
+			}
+			if !ruleset.Exited() {
+				t.Fatal("The plugin process should exit")
+			}
+
+			// The restarted plugin can be called again
+			if err := ruleset.Restart(); err != nil {
+				t.Fatal(err)
+			}
+			names, err := ruleset.RuleNames()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(names) != 1 || names[0] != "unstable_rule" {
+				t.Fatalf("Unexpected rule names: %v", names)
+			}
+		})
+	}
+}
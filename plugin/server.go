@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"sync"
+	"sync/atomic"
 
 	"github.com/hashicorp/go-version"
 	hcl "github.com/hashicorp/hcl/v2"
@@ -24,6 +25,10 @@ type GRPCServer struct {
 	rootRunner       *tflint.Runner
 	files            map[string]*hcl.File
 	clientSDKVersion *version.Version
+
+	// lastRule is the name of the last rule that accessed the server.
+	// It is used to tell which rule was running when the plugin crashes or hangs.
+	lastRule atomic.Value
 }
 
 var _ plugin2host.Server = (*GRPCServer)(nil)
@@ -33,6 +38,15 @@ func NewGRPCServer(runner *tflint.Runner, rootRunner *tflint.Runner, files map[s
 	return &GRPCServer{runner: runner, rootRunner: rootRunner, files: files, clientSDKVersion: sdkVersion}
 }
 
+// LastRule returns the name of the last rule that accessed the server.
+// Rules that don't read their config or emit issues are not recorded.
+func (s *GRPCServer) LastRule() string {
+	if rule, ok := s.lastRule.Load().(string); ok {
+		return rule
+	}
+	return ""
+}
+
 // GetOriginalwd returns the original working directory.
 func (s *GRPCServer) GetOriginalwd() string {
 	return s.runner.Ctx.Meta.OriginalWorkingDir
@@ -104,6 +118,8 @@ func (s *GRPCServer) GetFiles(ty sdk.ModuleCtxType) map[string][]byte {
 // It returns an extracted body content and sources.
 // The reason for returning sources is to encode the expression, and there is room for improvement here.
 func (s *GRPCServer) GetRuleConfigContent(name string, bodyS *hclext.BodySchema) (*hclext.BodyContent, map[string][]byte, error) {
+	s.lastRule.Store(name)
+
 	config := s.runner.RuleConfig(name)
 	if config == nil {
 		return &hclext.BodyContent{}, s.runner.ConfigSources(), nil
@@ -167,6 +183,8 @@ func (s *GRPCServer) EvaluateExpr(expr hcl.Expression, opts sdk.EvaluateExprOpti
 // However, some ranges may be syntactically valid but not actually represent an expression.
 // In these cases, the "expression" is still provided as context and the client should ignore any errors when attempting to evaluate it.
 func (s *GRPCServer) EmitIssue(rule sdk.Rule, message string, location hcl.Range, fixable bool) (bool, error) {
+	s.lastRule.Store(rule.Name())
+
 	// If the issue range represents an expression, it is emitted based on that context.
 	// This is required to emit issues in called modules.
 	expr, err := s.getExprFromRange(location)
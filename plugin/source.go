@@ -0,0 +1,309 @@
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/hashicorp/go-version"
+	"github.com/mitchellh/go-homedir"
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+// releaseSource is where plugin releases are fetched from.
+type releaseSource interface {
+	// String returns a description of the source used in error messages.
+	String() string
+	// versions returns versions of available releases to resolve version constraints.
+	versions() ([]*version.Version, error)
+	// fetch fetches the release of the resolved version.
+	fetch() error
+	// download downloads a file in the release to a temp file.
+	// It is the caller's responsibility to delete the generated the temp file.
+	download(filename string) (*os.File, error)
+}
+
+// releaseSource returns the source to install the plugin from.
+// If the plugin mirror is set, releases are fetched from the mirror instead of
+// GitHub, GitLab, or the plugin registry.
+// If the plugin cache directory is set, the source is wrapped by the cache.
+func (c *InstallConfig) releaseSource() (releaseSource, error) {
+	var source releaseSource
+
+	mirror, err := getPluginMirror(c.globalConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get plugin mirror: %w", err)
+	}
+	switch {
+	case mirror != "":
+		source = &mirrorSource{config: c, dir: mirror}
+	case c.SourceRegistry:
+		source = &registrySource{config: c}
+	case c.SourceType == "gitlab":
+		source = &gitlabSource{config: c}
+	default:
+		source = &githubSource{config: c}
+	}
+
+	cacheDir, err := getPluginCacheDir(c.globalConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get plugin cache dir: %w", err)
+	}
+	if cacheDir != "" {
+		return &cacheSource{releaseSource: source, config: c, dir: cacheDir, pending: map[string]string{}}, nil
+	}
+	return source, nil
+}
+
+// getPluginMirror returns the plugin mirror directory.
+// Adopted with the following priorities:
+//
+//  1. `plugin_mirror` in a global config
+//  2. `TFLINT_PLUGIN_MIRROR` environment variable
+//
+// The mirror can be a local directory or a file URL like file:///path/to/mirror.
+// If neither is set, an empty string is returned.
+func getPluginMirror(cfg *tflint.Config) (string, error) {
+	mirror := cfg.PluginMirror
+	if mirror == "" {
+		mirror = os.Getenv("TFLINT_PLUGIN_MIRROR")
+	}
+	if mirror == "" {
+		return "", nil
+	}
+
+	if strings.HasPrefix(mirror, "file://") {
+		u, err := url.Parse(mirror)
+		if err != nil {
+			return "", err
+		}
+		return filepath.FromSlash(u.Path), nil
+	}
+	return homedir.Expand(mirror)
+}
+
+// isGitHubSource returns whether the source fetches releases from GitHub, including through the cache.
+func isGitHubSource(source releaseSource) bool {
+	if cache, ok := source.(*cacheSource); ok {
+		source = cache.releaseSource
+	}
+	_, ok := source.(*githubSource)
+	return ok
+}
+
+// getPluginCacheDir returns the plugin cache directory.
+// Adopted with the following priorities:
+//
+//  1. `plugin_cache_dir` in a global config
+//  2. `TFLINT_PLUGIN_CACHE_DIR` environment variable
+//
+// If neither is set, an empty string is returned.
+func getPluginCacheDir(cfg *tflint.Config) (string, error) {
+	if cfg.PluginCacheDir != "" {
+		return homedir.Expand(cfg.PluginCacheDir)
+	}
+	return homedir.Expand(os.Getenv("TFLINT_PLUGIN_CACHE_DIR"))
+}
+
+// githubSource fetches releases from GitHub.
+type githubSource struct {
+	config *InstallConfig
+	assets map[string]*github.ReleaseAsset
+}
+
+func (s *githubSource) String() string {
+	return "GitHub releases"
+}
+
+func (s *githubSource) versions() ([]*version.Version, error) {
+	return s.config.fetchReleaseVersions()
+}
+
+func (s *githubSource) fetch() error {
+	assets, err := s.config.fetchReleaseAssets()
+	s.assets = assets
+	return err
+}
+
+func (s *githubSource) download(filename string) (*os.File, error) {
+	return s.config.downloadToTempFile(s.assets[filename])
+}
+
+// mirrorSource fetches releases from a local directory that mirrors them.
+// The directory must be laid out like the following:
+//
+//	{mirror}/{source}/{version}/checksums.txt
+//	{mirror}/{source}/{version}/checksums.txt.sig (optional)
+//	{mirror}/{source}/{version}/tflint-ruleset-{name}_{GOOS}_{GOARCH}.zip
+//
+// For instance, {mirror}/github.com/terraform-linters/tflint-ruleset-aws/0.30.0/checksums.txt.
+// For registry sources, the scheme is omitted like {mirror}/registry.example.com/ns/name/0.1.0/checksums.txt.
+type mirrorSource struct {
+	config *InstallConfig
+	dir    string
+}
+
+func (s *mirrorSource) String() string {
+	return fmt.Sprintf("plugin mirror %s", s.dir)
+}
+
+func (s *mirrorSource) versions() ([]*version.Version, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, filepath.FromSlash(s.config.sourcePath())))
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []*version.Version{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		v, err := version.NewVersion(entry.Name())
+		if err != nil {
+			log.Printf("[DEBUG] Ignore %s in the plugin mirror: %s", entry.Name(), err)
+			continue
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+func (s *mirrorSource) fetch() error {
+	_, err := os.Stat(s.releaseDir())
+	return err
+}
+
+func (s *mirrorSource) download(filename string) (*os.File, error) {
+	file, err := copyToTempFile(filepath.Join(s.releaseDir(), filename))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("file not found in the plugin mirror. Does %s contain the file with the correct name ?", s.releaseDir())
+	}
+	return file, err
+}
+
+func (s *mirrorSource) releaseDir() string {
+	return releaseDir(s.dir, s.config)
+}
+
+// cacheSource wraps a release source and caches downloaded files in the plugin cache directory.
+// Files are laid out in the same way as the plugin mirror, so that the cache can also be used as a mirror.
+//
+// Cached files are not trusted as-is. Like downloaded files, their checksums and signatures
+// are verified every time the plugin is installed from the cache.
+type cacheSource struct {
+	releaseSource
+
+	config *InstallConfig
+	dir    string
+
+	fetched bool
+	// pending is a map of filenames to temp files that have been downloaded
+	// but not yet saved in the cache.
+	pending map[string]string
+}
+
+// fetch fetches the release only if it is not cached.
+func (s *cacheSource) fetch() error {
+	if _, err := os.Stat(filepath.Join(s.releaseDir(), "checksums.txt")); err == nil {
+		log.Printf("[DEBUG] Found the release in the plugin cache: %s", s.releaseDir())
+		return nil
+	}
+	s.fetched = true
+	return s.releaseSource.fetch()
+}
+
+func (s *cacheSource) download(filename string) (*os.File, error) {
+	file, err := copyToTempFile(filepath.Join(s.releaseDir(), filename))
+	if err == nil || !os.IsNotExist(err) {
+		return file, err
+	}
+
+	if !s.fetched {
+		s.fetched = true
+		if err := s.releaseSource.fetch(); err != nil {
+			return nil, err
+		}
+	}
+	file, err = s.releaseSource.download(filename)
+	if err != nil {
+		return file, err
+	}
+	s.pending[filename] = file.Name()
+	return file, nil
+}
+
+// save saves the downloaded files in the cache. This should be called after the files are verified
+// so that tampered or corrupted files are not cached.
+func (s *cacheSource) save() error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(s.releaseDir(), 0755); err != nil {
+		return err
+	}
+
+	for filename, tempPath := range s.pending {
+		src, err := os.ReadFile(tempPath)
+		if err != nil {
+			return err
+		}
+		// Write to a temp file and rename it so that other processes do not read incomplete files
+		file, err := os.CreateTemp(s.releaseDir(), filename+".*")
+		if err != nil {
+			return err
+		}
+		_, err = file.Write(src)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(file.Name(), filepath.Join(s.releaseDir(), filename))
+		}
+		if err != nil {
+			os.Remove(file.Name())
+			return err
+		}
+		log.Printf("[DEBUG] Saved %s in the plugin cache: %s", filename, s.releaseDir())
+	}
+	s.pending = map[string]string{}
+	return nil
+}
+
+func (s *cacheSource) releaseDir() string {
+	return releaseDir(s.dir, s.config)
+}
+
+// releaseDir returns the directory of the release in the plugin mirror or cache,
+// like {dir}/github.com/terraform-linters/tflint-ruleset-aws/0.30.0.
+func releaseDir(dir string, config *InstallConfig) string {
+	return filepath.Join(dir, filepath.FromSlash(config.sourcePath()), config.resolvedVersion)
+}
+
+// copyToTempFile copies the file to a temp file.
+// It is the caller's responsibility to delete the generated the temp file.
+func copyToTempFile(path string) (*os.File, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	file, err := os.CreateTemp("", "tflint-download-temp-file-*")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.Copy(file, src); err != nil {
+		return file, err
+	}
+	if _, err := file.Seek(0, 0); err != nil {
+		return file, err
+	}
+
+	log.Printf("[DEBUG] Copied %s to %s", src.Name(), file.Name())
+	return file, nil
+}
@@ -0,0 +1,357 @@
+package plugin
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+	"github.com/terraform-linters/tflint/tflint"
+)
+
+func Test_Install_mirror(t *testing.T) {
+	original := PluginRoot
+	PluginRoot = t.TempDir()
+	defer func() { PluginRoot = original }()
+
+	mirror := t.TempDir()
+	writeMirrorRelease(t, mirror, "0.1.0")
+
+	globalConfig := tflint.EmptyConfig()
+	globalConfig.PluginMirror = mirror
+	config := NewInstallConfig(globalConfig, newMirrorTestPluginConfig("0.1.0"))
+
+	// Signature verification is skipped as no signing key is configured
+	path, err := config.Install(nil)
+	if !errors.Is(err, ErrPluginNotVerified) {
+		t.Fatalf("Expected ErrPluginNotVerified, but got %v", err)
+	}
+
+	expected := filepath.Join(PluginRoot, "example.com/org/tflint-ruleset-foo", "0.1.0", "tflint-ruleset-foo"+fileExt())
+	if path != expected {
+		t.Fatalf("Installed path is invalid: expected=%s, got=%s", expected, path)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "0.1.0" {
+		t.Fatalf("Installed binary is invalid: got=%s", got)
+	}
+}
+
+func Test_Install_mirror_verificationPolicy(t *testing.T) {
+	mirror := t.TempDir()
+	writeMirrorRelease(t, mirror, "0.1.0")
+
+	tests := []struct {
+		policy string
+		err    string
+	}{
+		{
+			policy: tflint.PluginVerificationRequired,
+			err:    `Failed to verify the signature of checksums.txt; "plugin_verification" is "required", but no signing key is configured and artifact attestations are not available`,
+		},
+		{
+			policy: tflint.PluginVerificationOff,
+			err:    "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.policy, func(t *testing.T) {
+			original := PluginRoot
+			PluginRoot = t.TempDir()
+			defer func() { PluginRoot = original }()
+
+			globalConfig := tflint.EmptyConfig()
+			globalConfig.PluginMirror = mirror
+			globalConfig.PluginVerification = test.policy
+			config := NewInstallConfig(globalConfig, newMirrorTestPluginConfig("0.1.0"))
+
+			path, err := config.Install(nil)
+			if err == nil {
+				if test.err != "" {
+					t.Fatalf("expected error %q, but got nil", test.err)
+				}
+				if _, err := os.Stat(path); err != nil {
+					t.Fatalf("The plugin should be installed, but got %s", err)
+				}
+				return
+			}
+			if err.Error() != test.err {
+				t.Fatalf("want=%s, got=%s", test.err, err)
+			}
+			if _, err := os.Stat(filepath.Join(PluginRoot, "example.com/org/tflint-ruleset-foo", "0.1.0", "tflint-ruleset-foo"+fileExt())); !os.IsNotExist(err) {
+				t.Fatalf("The plugin should not be installed, but got %v", err)
+			}
+		})
+	}
+}
+
+func Test_Install_mirror_checksumMismatch(t *testing.T) {
+	original := PluginRoot
+	PluginRoot = t.TempDir()
+	defer func() { PluginRoot = original }()
+
+	mirror := t.TempDir()
+	dir := writeMirrorRelease(t, mirror, "0.1.0")
+	if err := os.WriteFile(filepath.Join(dir, "checksums.txt"), []byte(fmt.Sprintf("%x  %s\n", sha256.Sum256([]byte("foo")), mirrorTestAssetName())), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	globalConfig := tflint.EmptyConfig()
+	globalConfig.PluginMirror = "file://" + filepath.ToSlash(mirror)
+	config := NewInstallConfig(globalConfig, newMirrorTestPluginConfig("0.1.0"))
+
+	_, err := config.Install(nil)
+	if err == nil {
+		t.Fatal("An error should have occurred, but it did not occur")
+	}
+	expected := fmt.Sprintf("Failed to verify checksums: Failed to match checksums: expected=%x", sha256.Sum256([]byte("foo")))
+	if !strings.HasPrefix(err.Error(), expected) {
+		t.Fatalf("Error message not matched: want=%s, got=%s", expected, err)
+	}
+}
+
+func Test_ResolveVersion_mirror(t *testing.T) {
+	mirror := t.TempDir()
+	for _, v := range []string{"0.1.0", "0.2.0", "0.3.0-beta", "1.0.0"} {
+		writeMirrorRelease(t, mirror, v)
+	}
+
+	tests := []struct {
+		constraint string
+		want       string
+		err        string
+	}{
+		{
+			constraint: "~> 0.1",
+			want:       "0.2.0",
+		},
+		{
+			constraint: ">= 0.1",
+			want:       "1.0.0",
+		},
+		{
+			constraint: "> 1.0",
+			err:        `No release of "foo" plugin satisfies the version constraint "> 1.0"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.constraint, func(t *testing.T) {
+			t.Setenv("TFLINT_PLUGIN_MIRROR", mirror)
+
+			pluginCfg := newMirrorTestPluginConfig(test.constraint)
+			pluginCfg.VersionConstraints = version.MustConstraints(version.NewConstraint(test.constraint))
+			config := NewInstallConfig(tflint.EmptyConfig(), pluginCfg)
+
+			err := config.ResolveVersion()
+			if err != nil {
+				if err.Error() != test.err {
+					t.Fatalf("want=%s, got=%s", test.err, err)
+				}
+				return
+			}
+			if test.err != "" {
+				t.Fatalf("expected error %q, but got nil", test.err)
+			}
+			if config.ResolvedVersion() != test.want {
+				t.Fatalf("want=%s, got=%s", test.want, config.ResolvedVersion())
+			}
+		})
+	}
+}
+
+func Test_getPluginMirror(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		env    string
+		want   string
+	}{
+		{
+			name: "not set",
+			want: "",
+		},
+		{
+			name:   "config",
+			config: "/opt/mirror",
+			env:    "/opt/env-mirror",
+			want:   filepath.FromSlash("/opt/mirror"),
+		},
+		{
+			name: "environment variable",
+			env:  "/opt/env-mirror",
+			want: filepath.FromSlash("/opt/env-mirror"),
+		},
+		{
+			name:   "file URL",
+			config: "file:///opt/mirror",
+			want:   filepath.FromSlash("/opt/mirror"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("TFLINT_PLUGIN_MIRROR", test.env)
+
+			config := tflint.EmptyConfig()
+			config.PluginMirror = test.config
+
+			got, err := getPluginMirror(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Fatalf("want=%s, got=%s", test.want, got)
+			}
+		})
+	}
+}
+
+func Test_Install_cache(t *testing.T) {
+	original := PluginRoot
+	defer func() { PluginRoot = original }()
+
+	mirror := t.TempDir()
+	writeMirrorRelease(t, mirror, "0.1.0")
+	cacheDir := t.TempDir()
+
+	globalConfig := tflint.EmptyConfig()
+	globalConfig.PluginMirror = mirror
+	globalConfig.PluginCacheDir = cacheDir
+
+	// The first installation populates the cache
+	PluginRoot = t.TempDir()
+	if _, err := NewInstallConfig(globalConfig, newMirrorTestPluginConfig("0.1.0")).Install(nil); !errors.Is(err, ErrPluginNotVerified) {
+		t.Fatalf("Expected ErrPluginNotVerified, but got %v", err)
+	}
+	cached := filepath.Join(cacheDir, "example.com", "org", "tflint-ruleset-foo", "0.1.0")
+	for _, filename := range []string{"checksums.txt", mirrorTestAssetName()} {
+		if _, err := os.Stat(filepath.Join(cached, filename)); err != nil {
+			t.Fatalf("%s should be cached, but got %s", filename, err)
+		}
+	}
+
+	// Subsequent installations use the cache without the mirror
+	if err := os.RemoveAll(mirror); err != nil {
+		t.Fatal(err)
+	}
+	PluginRoot = t.TempDir()
+	path, err := NewInstallConfig(globalConfig, newMirrorTestPluginConfig("0.1.0")).Install(nil)
+	if !errors.Is(err, ErrPluginNotVerified) {
+		t.Fatalf("Expected ErrPluginNotVerified, but got %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "0.1.0" {
+		t.Fatalf("Installed binary is invalid: got=%s", got)
+	}
+
+	// Cached files are verified
+	if err := os.WriteFile(filepath.Join(cached, mirrorTestAssetName()), []byte("foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	PluginRoot = t.TempDir()
+	_, err = NewInstallConfig(globalConfig, newMirrorTestPluginConfig("0.1.0")).Install(nil)
+	if err == nil {
+		t.Fatal("An error should have occurred, but it did not occur")
+	}
+	if !strings.HasPrefix(err.Error(), "Failed to verify checksums: Failed to match checksums") {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
+
+func Test_Install_cache_notVerified(t *testing.T) {
+	original := PluginRoot
+	PluginRoot = t.TempDir()
+	defer func() { PluginRoot = original }()
+
+	mirror := t.TempDir()
+	dir := writeMirrorRelease(t, mirror, "0.1.0")
+	if err := os.WriteFile(filepath.Join(dir, "checksums.txt"), []byte(fmt.Sprintf("%x  %s\n", sha256.Sum256([]byte("foo")), mirrorTestAssetName())), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cacheDir := t.TempDir()
+
+	globalConfig := tflint.EmptyConfig()
+	globalConfig.PluginMirror = mirror
+	globalConfig.PluginCacheDir = cacheDir
+
+	if _, err := NewInstallConfig(globalConfig, newMirrorTestPluginConfig("0.1.0")).Install(nil); err == nil {
+		t.Fatal("An error should have occurred, but it did not occur")
+	}
+
+	// Files that failed verification are not cached
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("The cache should be empty, but got %d entries", len(entries))
+	}
+}
+
+func newMirrorTestPluginConfig(version string) *tflint.PluginConfig {
+	return &tflint.PluginConfig{
+		Name:        "foo",
+		Enabled:     true,
+		Version:     version,
+		Source:      "example.com/org/tflint-ruleset-foo",
+		SourceHost:  "example.com",
+		SourceOwner: "org",
+		SourceRepo:  "tflint-ruleset-foo",
+	}
+}
+
+func mirrorTestAssetName() string {
+	return fmt.Sprintf("tflint-ruleset-foo_%s_%s.zip", runtime.GOOS, runtime.GOARCH)
+}
+
+// writeMirrorRelease writes a release whose binary content is the version.
+func writeMirrorRelease(t *testing.T, mirror string, version string) string {
+	dir := filepath.Join(mirror, "example.com", "org", "tflint-ruleset-foo", version)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	zipPath := filepath.Join(dir, mirrorTestAssetName())
+	f, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	binary, err := w.Create("tflint-ruleset-foo" + fileExt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := binary.Write([]byte(version)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := os.ReadFile(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksums := fmt.Sprintf("%x  %s\n", sha256.Sum256(src), mirrorTestAssetName())
+	if err := os.WriteFile(filepath.Join(dir, "checksums.txt"), []byte(checksums), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"os"
+
+	"github.com/terraform-linters/tflint-plugin-sdk/plugin"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// UnstableRule hangs, crashes, or fails depending on TFLINT_RULESET_UNSTABLE_MODE
+type UnstableRule struct {
+	tflint.DefaultRule
+}
+
+type unstableRuleConfig struct{}
+
+// Name returns the rule name
+func (r *UnstableRule) Name() string {
+	return "unstable_rule"
+}
+
+// Enabled returns whether the rule is enabled by default
+func (r *UnstableRule) Enabled() bool {
+	return true
+}
+
+// Severity returns the rule severity
+func (r *UnstableRule) Severity() tflint.Severity {
+	return tflint.ERROR
+}
+
+// Link returns the rule reference link
+func (r *UnstableRule) Link() string {
+	return ""
+}
+
+// Check hangs, crashes, or fails after reading the rule config, so that the host knows the last rule
+func (r *UnstableRule) Check(runner tflint.Runner) error {
+	if err := runner.DecodeRuleConfig(r.Name(), &unstableRuleConfig{}); err != nil {
+		return err
+	}
+
+	switch os.Getenv("TFLINT_RULESET_UNSTABLE_MODE") {
+	case "hang":
+		select {}
+	case "crash":
+		panic("unstable_rule crashed")
+	case "error":
+		return errors.New("unstable_rule failed")
+	}
+	return nil
+}
+
+func main() {
+	plugin.Serve(&plugin.ServeOpts{
+		RuleSet: &tflint.BuiltinRuleSet{
+			Name:    "unstable",
+			Version: "0.1.0",
+			Rules:   []tflint.Rule{&UnstableRule{}},
+		},
+	})
+}
@@ -21,6 +21,9 @@ import (
 type ContextMeta struct {
 	Env                string
 	OriginalWorkingDir string
+	// WorkingDir is the directory from which functions resolve relative paths
+	// instead of the current directory. If empty, the current directory is used.
+	WorkingDir string
 }
 
 type Evaluator struct {
@@ -38,6 +41,18 @@ func (e *Evaluator) EvaluateExpr(expr hcl.Expression, wantType cty.Type) (cty.Va
 	return e.scope().EvalExpr(expr, wantType)
 }
 
+// EvaluateExprWithSelf is similar to EvaluateExpr, but the "self" object
+// in the expression refers to the passed value instead of an unknown value.
+// This is useful for evaluating expressions against a specific block.
+func (e *Evaluator) EvaluateExprWithSelf(expr hcl.Expression, self cty.Value, wantType cty.Type) (cty.Value, hcl.Diagnostics) {
+	if e == nil {
+		panic("evaluator must not be nil")
+	}
+	scope := e.scope()
+	scope.SelfValue = self
+	return scope.EvalExpr(expr, wantType)
+}
+
 // ExpandBlock expands "dynamic" blocks and resources/modules with count/for_each.
 //
 // In the expanded body, the content can be retrieved with the HCL API without
@@ -58,6 +73,10 @@ func (e *Evaluator) scope() *lang.Scope {
 		CallStack:           lang.NewCallStack(),
 		ResolvedLocalValues: map[string]cty.Value{},
 	}
+	if e.Meta != nil {
+		scope.BaseDir = e.Meta.WorkingDir
+		scope.WorkingDir = e.Meta.WorkingDir
+	}
 	scope.Data = &evaluationData{
 		Scope:          scope,
 		Meta:           e.Meta,
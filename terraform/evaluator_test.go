@@ -869,6 +869,84 @@ variable "foo" {
 	}
 }
 
+func TestEvaluateExprWithSelf(t *testing.T) {
+	fs := afero.Afero{Fs: afero.NewMemMapFs()}
+	if err := fs.WriteFile("main.tf", []byte(`
+variable "owner" {
+  default = "platform"
+}`), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	parser := NewParser(fs)
+	mod, diags := parser.LoadConfigDir(".", ".")
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	config, diags := BuildConfig(mod, ModuleWalkerFunc(func(req *ModuleRequest) (*Module, *version.Version, hcl.Diagnostics) { return nil, nil, nil }))
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	variableValues, diags := VariableValues(config)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+	evaluator := &Evaluator{
+		Meta:           &ContextMeta{Env: Workspace()},
+		ModulePath:     config.Path.UnkeyedInstanceShim(),
+		Config:         config,
+		VariableValues: variableValues,
+	}
+
+	self := cty.ObjectVal(map[string]cty.Value{
+		"tags": cty.MapVal(map[string]cty.Value{"owner": cty.StringVal("platform")}),
+	})
+
+	tests := []struct {
+		name string
+		expr string
+		self cty.Value
+		want string
+	}{
+		{
+			name: "bound self",
+			expr: `self.tags.owner == var.owner`,
+			self: self,
+			want: `cty.True`,
+		},
+		{
+			name: "function call with self",
+			expr: `can(self.tags.team)`,
+			self: self,
+			want: `cty.False`,
+		},
+		{
+			name: "unbound self",
+			expr: `self.tags.owner == var.owner`,
+			self: cty.NilVal,
+			want: `cty.UnknownVal(cty.Bool).RefineNotNull()`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(test.expr), "", hcl.InitialPos)
+			if diags.HasErrors() {
+				t.Fatal(diags)
+			}
+
+			got, diags := evaluator.EvaluateExprWithSelf(expr, test.self, cty.Bool)
+			if diags.HasErrors() {
+				t.Fatal(diags)
+			}
+
+			if test.want != got.GoString() {
+				t.Errorf("want: %s, got: %s", test.want, got.GoString())
+			}
+		})
+	}
+}
+
 func TestExpandBlock(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -216,6 +216,11 @@ func (s *Scope) evalContext(refs []*addrs.Reference, selfAddr addrs.Referenceabl
 	vals["module"] = cty.UnknownVal(cty.DynamicPseudoType)
 	vals["self"] = cty.UnknownVal(cty.DynamicPseudoType)
 
+	// The "self" object can be bound to a value by the caller (e.g. custom rules).
+	if s.SelfValue.Type() != cty.NilType {
+		vals["self"] = s.SelfValue
+	}
+
 	return ctx, diags
 }
 
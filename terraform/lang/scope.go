@@ -22,10 +22,18 @@ type Scope struct {
 	// or nil if the "self" object should not be available at all.
 	SelfAddr addrs.Referenceable
 
+	// SelfValue is the value of the "self" object. If it is cty.NilVal,
+	// the "self" object is always evaluated as an unknown value.
+	SelfValue cty.Value
+
 	// BaseDir is the base directory used by any interpolation functions that
 	// accept filesystem paths as arguments.
 	BaseDir string
 
+	// WorkingDir is the directory used by abspath instead of the current
+	// directory. If empty, the current directory is used.
+	WorkingDir string
+
 	// PureOnly can be set to true to request that any non-pure functions
 	// produce unknown value results rather than actually executing. This is
 	// important during a plan phase to avoid generating results that could
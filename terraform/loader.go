@@ -36,12 +36,19 @@ type Loader struct {
 // If an original working dir is passed, the paths of the loaded files will
 // be relative to that directory.
 func NewLoader(fs afero.Afero, originalWd string) (*Loader, error) {
-	log.Print("[INFO] Initialize new loader")
-
 	wd, err := os.Getwd()
 	if err != nil {
 		return nil, fmt.Errorf("failed to determine current working directory: %s", err)
 	}
+	return NewLoaderAt(fs, originalWd, wd)
+}
+
+// NewLoaderAt is similar to NewLoader, but takes the working directory instead
+// of using the current directory. The filesystem should resolve relative paths
+// from the working directory.
+func NewLoaderAt(fs afero.Afero, originalWd string, wd string) (*Loader, error) {
+	log.Print("[INFO] Initialize new loader")
+
 	baseDir, err := filepath.Rel(originalWd, wd)
 	if err != nil {
 		return nil, fmt.Errorf("failed to determine base dir: %s", err)
@@ -166,7 +173,7 @@ func (l *Loader) LoadValuesFiles(dir string, files ...string) ([]InputValues, hc
 		return nil, diags
 	}
 	defaultVarsFile := filepath.Join(dir, defaultVarsFilename)
-	if _, err := os.Stat(defaultVarsFile); err == nil {
+	if _, err := l.parser.fs.Stat(defaultVarsFile); err == nil {
 		autoLoadFiles = append([]string{defaultVarsFile}, autoLoadFiles...)
 	}
 
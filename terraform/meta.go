@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+
+	"github.com/spf13/afero"
 )
 
 func dataDir() string {
@@ -19,13 +21,25 @@ func dataDir() string {
 	return dir
 }
 
+// Workspace returns the name of the selected workspace.
+// The environment file is read from the current directory.
 func Workspace() string {
+	return workspace(afero.Afero{Fs: afero.OsFs{}})
+}
+
+// Workspace is similar to terraform.Workspace, but reads the environment file
+// through the filesystem of the loader.
+func (l *Loader) Workspace() string {
+	return workspace(l.modules.fs)
+}
+
+func workspace(fs afero.Afero) string {
 	if envVar := os.Getenv("TF_WORKSPACE"); envVar != "" {
 		log.Printf("[INFO] TF_WORKSPACE environment variable found: %s", envVar)
 		return envVar
 	}
 
-	envData, _ := os.ReadFile(filepath.Join(dataDir(), "environment"))
+	envData, _ := fs.ReadFile(filepath.Join(dataDir(), "environment"))
 	current := string(bytes.TrimSpace(envData))
 	if current != "" {
 		log.Printf("[INFO] environment file found: %s", current)
@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/spf13/afero"
 )
 
 func TestWorkspace(t *testing.T) {
@@ -57,3 +59,20 @@ func TestWorkspace(t *testing.T) {
 		})
 	}
 }
+
+func TestLoader_Workspace(t *testing.T) {
+	fs := afero.Afero{Fs: afero.NewMemMapFs()}
+	if err := fs.WriteFile(filepath.Join(".terraform", "environment"), []byte("staging\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader, err := NewLoaderAt(fs, "/work", "/work")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The environment file is read from the filesystem of the loader, not the current directory
+	if got := loader.Workspace(); got != "staging" {
+		t.Errorf("want: staging, got: %s", got)
+	}
+}
@@ -4,9 +4,14 @@ import (
 	"fmt"
 	"log"
 	"maps"
+	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/bmatcuk/doublestar"
 	"github.com/hashicorp/go-version"
 	hcl "github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
@@ -19,8 +24,8 @@ import (
 	"github.com/terraform-linters/tflint/terraform"
 )
 
-var defaultConfigFile = ".tflint.hcl"
-var fallbackConfigFile = "~/.tflint.hcl"
+var defaultConfigFiles = []string{".tflint.hcl", ".tflint.hcl.json", ".tflint.json"}
+var fallbackConfigFiles = []string{"~/.tflint.hcl", "~/.tflint.hcl.json", "~/.tflint.json"}
 
 var configSchema = &hcl.BodySchema{
 	Blocks: []hcl.BlockHeaderSchema{
@@ -38,6 +43,18 @@ var configSchema = &hcl.BodySchema{
 			Type:       "plugin",
 			LabelNames: []string{"name"},
 		},
+		{
+			Type:       "override",
+			LabelNames: []string{"pattern"},
+		},
+		{
+			Type:       "preset",
+			LabelNames: []string{"name"},
+		},
+		{
+			Type:       "custom_rule",
+			LabelNames: []string{"name"},
+		},
 	},
 }
 
@@ -50,6 +67,10 @@ var innerConfigSchema = &hcl.BodySchema{
 		{Name: "variables"},
 		{Name: "disabled_by_default"},
 		{Name: "plugin_dir"},
+		{Name: "plugin_mirror"},
+		{Name: "plugin_cache_dir"},
+		{Name: "plugin_verification"},
+		{Name: "policy_dir"},
 		{Name: "format"},
 
 		// Removed attributes
@@ -66,6 +87,22 @@ var validFormats = []string{
 	"sarif",
 }
 
+// Plugin verification policies
+const (
+	// PluginVerificationRequired refuses plugins whose release signature is not verified
+	PluginVerificationRequired = "required"
+	// PluginVerificationWarn warns about plugins whose release signature is not verified
+	PluginVerificationWarn = "warn"
+	// PluginVerificationOff does not warn about plugins whose release signature is not verified
+	PluginVerificationOff = "off"
+)
+
+var validPluginVerifications = []string{
+	PluginVerificationRequired,
+	PluginVerificationWarn,
+	PluginVerificationOff,
+}
+
 // Config describes the behavior of TFLint
 type Config struct {
 	CallModuleType    terraform.CallModuleType
@@ -80,6 +117,18 @@ type Config struct {
 	PluginDir    string
 	PluginDirSet bool
 
+	PluginMirror    string
+	PluginMirrorSet bool
+
+	PluginCacheDir    string
+	PluginCacheDirSet bool
+
+	PluginVerification    string
+	PluginVerificationSet bool
+
+	PolicyDir    string
+	PolicyDirSet bool
+
 	Format    string
 	FormatSet bool
 
@@ -89,15 +138,52 @@ type Config struct {
 	IgnoreModules map[string]bool
 	Rules         map[string]*RuleConfig
 	Plugins       map[string]*PluginConfig
+	Overrides     []*OverrideConfig
+
+	Presets        map[string]*PresetConfig
+	EnabledPresets []string
+
+	// PluginLogDir is set only by the --plugin-log-dir option
+	PluginLogDir string
 
+	CustomRules []*CustomRuleConfig
+
+	file    string
 	sources map[string][]byte
+	// disabledRules is a set of rules disabled by the --disable-rule option.
+	// They take precedence over override blocks.
+	disabledRules map[string]bool
 }
 
 // RuleConfig is a TFLint's rule config
 type RuleConfig struct {
+	Name         string   `hcl:"name,label"`
+	Enabled      bool     `hcl:"enabled"`
+	IncludePaths []string `hcl:"include_paths,optional"`
+	ExcludePaths []string `hcl:"exclude_paths,optional"`
+	Body         hcl.Body `hcl:",remain"`
+}
+
+// PresetConfig is a TFLint's preset config.
+// A preset is a named group of rules across plugins that can be enabled or disabled at once.
+// If Enabled is nil, the preset is only applied when selected by the --preset option.
+type PresetConfig struct {
 	Name    string   `hcl:"name,label"`
-	Enabled bool     `hcl:"enabled"`
-	Body    hcl.Body `hcl:",remain"`
+	Enabled *bool    `hcl:"enabled,optional"`
+	Rules   []string `hcl:"rules"`
+}
+
+// OverrideConfig is a TFLint's override config.
+// Rule configs in the block are applied only to files matching the pattern.
+type OverrideConfig struct {
+	Pattern string                `hcl:"pattern,label"`
+	Rules   []*OverrideRuleConfig `hcl:"rule,block"`
+}
+
+// OverrideRuleConfig is a rule config in the override block
+type OverrideRuleConfig struct {
+	Name    string `hcl:"name,label"`
+	Enabled bool   `hcl:"enabled"`
 }
 
 // PluginConfig is a TFLint's plugin config
@@ -106,7 +192,9 @@ type PluginConfig struct {
 	Enabled    bool   `hcl:"enabled"`
 	Version    string `hcl:"version,optional"`
 	Source     string `hcl:"source,optional"`
+	SourceType string `hcl:"source_type,optional"`
 	SigningKey string `hcl:"signing_key,optional"`
+	Timeout    string `hcl:"timeout,optional"`
 
 	Body hcl.Body `hcl:",remain"`
 
@@ -114,6 +202,15 @@ type PluginConfig struct {
 	SourceHost  string
 	SourceOwner string
 	SourceRepo  string
+	// SourceRegistry is true if the source is a plugin registry URL
+	// like `https://registry.example.com/ns/name` instead of a GitHub reference.
+	SourceRegistry bool
+
+	// Parsed version constraints. This is nil if the version is an exact version.
+	VersionConstraints version.Constraints
+
+	// Parsed timeout for each call to the plugin. This is zero if the timeout is omitted, and calls do not time out.
+	CallTimeout time.Duration
 }
 
 // EmptyConfig returns default config
@@ -136,11 +233,12 @@ func EmptyConfig() *Config {
 //
 // 1. file passed by the --config option
 // 2. file set by the TFLINT_CONFIG_FILE environment variable
-// 3. current directory (./.tflint.hcl)
-// 4. home directory (~/.tflint.hcl)
+// 3. current directory (./.tflint.hcl, ./.tflint.hcl.json, ./.tflint.json)
+// 4. home directory (~/.tflint.hcl, ~/.tflint.hcl.json, ~/.tflint.json)
 //
 // For 1 and 2, if the file does not exist, an error will be returned immediately.
 // If 3 fails, fallback to 4, and If it fails, an empty configuration is returned.
+// Files with the ".json" extension are parsed as HCL's JSON syntax.
 //
 // It also automatically enables bundled plugin if the "terraform"
 // plugin block is not explicitly declared.
@@ -175,30 +273,34 @@ func LoadConfig(fs afero.Afero, file string) (*Config, error) {
 	}
 
 	// Load the default config file
-	log.Printf("[INFO] Load config: %s", defaultConfigFile)
-	if f, err := fs.Open(defaultConfigFile); err == nil {
-		cfg, err := loadConfig(f)
-		if err != nil {
-			return nil, err
+	for _, defaultConfigFile := range defaultConfigFiles {
+		log.Printf("[INFO] Load config: %s", defaultConfigFile)
+		if f, err := fs.Open(defaultConfigFile); err == nil {
+			cfg, err := loadConfig(f)
+			if err != nil {
+				return nil, err
+			}
+			return cfg.enableBundledPlugin(), nil
 		}
-		return cfg.enableBundledPlugin(), nil
+		log.Printf("[INFO] file not found")
 	}
-	log.Printf("[INFO] file not found")
 
 	// Load the fallback config file
-	fallback, err := homedir.Expand(fallbackConfigFile)
-	if err != nil {
-		return nil, err
-	}
-	log.Printf("[INFO] Load config: %s", fallback)
-	if f, err := fs.Open(fallback); err == nil {
-		cfg, err := loadConfig(f)
+	for _, fallbackConfigFile := range fallbackConfigFiles {
+		fallback, err := homedir.Expand(fallbackConfigFile)
 		if err != nil {
 			return nil, err
 		}
-		return cfg.enableBundledPlugin(), nil
+		log.Printf("[INFO] Load config: %s", fallback)
+		if f, err := fs.Open(fallback); err == nil {
+			cfg, err := loadConfig(f)
+			if err != nil {
+				return nil, err
+			}
+			return cfg.enableBundledPlugin(), nil
+		}
+		log.Printf("[INFO] file not found")
 	}
-	log.Printf("[INFO] file not found")
 
 	// Use the default config
 	log.Print("[INFO] Use default config")
@@ -212,7 +314,13 @@ func loadConfig(file afero.File) (*Config, error) {
 	}
 
 	parser := hclparse.NewParser()
-	f, diags := parser.ParseHCL(src, file.Name())
+	var f *hcl.File
+	var diags hcl.Diagnostics
+	if isJSONConfigFile(file.Name()) {
+		f, diags = parser.ParseJSON(src, file.Name())
+	} else {
+		f, diags = parser.ParseHCL(src, file.Name())
+	}
 	if diags.HasErrors() {
 		return nil, diags
 	}
@@ -226,9 +334,25 @@ func loadConfig(file afero.File) (*Config, error) {
 		return nil, diags
 	}
 
+	// Plugins parse JSON expressions only in files with the ".tf.json" extension,
+	// so bodies of JSON configs are passed to plugins under a pseudo file name.
+	pluginBlocks := content.Blocks
+	if isJSONConfigFile(file.Name()) {
+		f, diags := parser.ParseJSON(src, jsonPluginConfigFilename(file.Name()))
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		content, diags := f.Body.Content(configSchema)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		pluginBlocks = content.Blocks
+	}
+
 	config := EmptyConfig()
+	config.file = file.Name()
 	config.sources = parser.Sources()
-	for _, block := range content.Blocks {
+	for i, block := range content.Blocks {
 		switch block.Type {
 		case "tflint":
 			// The "tflint" block is already handled by checkVersionRequirement and is therefore ignored
@@ -285,6 +409,33 @@ func loadConfig(file afero.File) (*Config, error) {
 						return config, err
 					}
 
+				case "plugin_mirror":
+					config.PluginMirrorSet = true
+					if err := gohcl.DecodeExpression(attr.Expr, nil, &config.PluginMirror); err != nil {
+						return config, err
+					}
+
+				case "plugin_cache_dir":
+					config.PluginCacheDirSet = true
+					if err := gohcl.DecodeExpression(attr.Expr, nil, &config.PluginCacheDir); err != nil {
+						return config, err
+					}
+
+				case "plugin_verification":
+					config.PluginVerificationSet = true
+					if err := gohcl.DecodeExpression(attr.Expr, nil, &config.PluginVerification); err != nil {
+						return config, err
+					}
+					if !slices.Contains(validPluginVerifications, config.PluginVerification) {
+						return config, fmt.Errorf(`%s is invalid plugin_verification. Allowed values are: %s`, config.PluginVerification, strings.Join(validPluginVerifications, ", "))
+					}
+
+				case "policy_dir":
+					config.PolicyDirSet = true
+					if err := gohcl.DecodeExpression(attr.Expr, nil, &config.PolicyDir); err != nil {
+						return config, err
+					}
+
 				case "format":
 					config.FormatSet = true
 					if err := gohcl.DecodeExpression(attr.Expr, nil, &config.Format); err != nil {
@@ -315,8 +466,47 @@ func loadConfig(file afero.File) (*Config, error) {
 			if err := gohcl.DecodeBody(block.Body, nil, ruleConfig); err != nil {
 				return config, err
 			}
+			if err := validatePathPatterns(append(ruleConfig.IncludePaths, ruleConfig.ExcludePaths...)...); err != nil {
+				return config, fmt.Errorf(`rule "%s": %w`, ruleConfig.Name, err)
+			}
+			ruleConfig.Body = pluginBody(pluginBlocks[i], ruleConfig)
 			config.Rules[block.Labels[0]] = ruleConfig
 
+		case "override":
+			overrideConfig := &OverrideConfig{Pattern: block.Labels[0]}
+			if err := gohcl.DecodeBody(block.Body, nil, overrideConfig); err != nil {
+				return config, err
+			}
+			if err := validatePathPatterns(overrideConfig.Pattern); err != nil {
+				return config, fmt.Errorf(`override "%s": %w`, overrideConfig.Pattern, err)
+			}
+			config.Overrides = append(config.Overrides, overrideConfig)
+
+		case "custom_rule":
+			customRuleConfig := &CustomRuleConfig{Name: block.Labels[0], Enabled: true, Severity: "warning"}
+			if err := gohcl.DecodeBody(block.Body, nil, customRuleConfig); err != nil {
+				return config, err
+			}
+			if err := customRuleConfig.validate(); err != nil {
+				return config, err
+			}
+			for _, rule := range config.CustomRules {
+				if rule.Name == customRuleConfig.Name {
+					return config, fmt.Errorf(`custom_rule "%s" is duplicated`, customRuleConfig.Name)
+				}
+			}
+			config.CustomRules = append(config.CustomRules, customRuleConfig)
+
+		case "preset":
+			presetConfig := &PresetConfig{Name: block.Labels[0]}
+			if err := gohcl.DecodeBody(block.Body, nil, presetConfig); err != nil {
+				return config, err
+			}
+			if config.Presets == nil {
+				config.Presets = map[string]*PresetConfig{}
+			}
+			config.Presets[block.Labels[0]] = presetConfig
+
 		case "plugin":
 			pluginConfig := &PluginConfig{Name: block.Labels[0]}
 			if err := gohcl.DecodeBody(block.Body, nil, pluginConfig); err != nil {
@@ -325,6 +515,7 @@ func loadConfig(file afero.File) (*Config, error) {
 			if err := pluginConfig.validate(); err != nil {
 				return config, err
 			}
+			pluginConfig.Body = pluginBody(pluginBlocks[i], pluginConfig)
 			config.Plugins[block.Labels[0]] = pluginConfig
 
 		default:
@@ -332,6 +523,34 @@ func loadConfig(file afero.File) (*Config, error) {
 		}
 	}
 
+	// Presets enabled in the config file are expanded into rules.
+	// Rule blocks take precedence over presets. If presets disagree on a rule,
+	// the preset that disables it wins regardless of the order.
+	fromPresets := map[string]bool{}
+	for _, name := range slices.Sorted(maps.Keys(config.Presets)) {
+		preset := config.Presets[name]
+		if preset.Enabled == nil {
+			continue
+		}
+		for _, rule := range preset.Rules {
+			if fromPresets[rule] {
+				if !*preset.Enabled {
+					config.Rules[rule].Enabled = false
+				}
+				continue
+			}
+			if _, exists := config.Rules[rule]; exists {
+				continue
+			}
+			config.Rules[rule] = &RuleConfig{
+				Name:    rule,
+				Enabled: *preset.Enabled,
+				Body:    hcl.EmptyBody(),
+			}
+			fromPresets[rule] = true
+		}
+	}
+
 	log.Printf("[DEBUG] Config loaded")
 	log.Printf("[DEBUG]   CallModuleType: %s", config.CallModuleType)
 	log.Printf("[DEBUG]   CallModuleTypeSet: %t", config.CallModuleTypeSet)
@@ -341,6 +560,14 @@ func loadConfig(file afero.File) (*Config, error) {
 	log.Printf("[DEBUG]   DisabledByDefaultSet: %t", config.DisabledByDefaultSet)
 	log.Printf("[DEBUG]   PluginDir: %s", config.PluginDir)
 	log.Printf("[DEBUG]   PluginDirSet: %t", config.PluginDirSet)
+	log.Printf("[DEBUG]   PluginMirror: %s", config.PluginMirror)
+	log.Printf("[DEBUG]   PluginMirrorSet: %t", config.PluginMirrorSet)
+	log.Printf("[DEBUG]   PluginCacheDir: %s", config.PluginCacheDir)
+	log.Printf("[DEBUG]   PluginCacheDirSet: %t", config.PluginCacheDirSet)
+	log.Printf("[DEBUG]   PluginVerification: %s", config.PluginVerification)
+	log.Printf("[DEBUG]   PluginVerificationSet: %t", config.PluginVerificationSet)
+	log.Printf("[DEBUG]   PolicyDir: %s", config.PolicyDir)
+	log.Printf("[DEBUG]   PolicyDirSet: %t", config.PolicyDirSet)
 	log.Printf("[DEBUG]   Format: %s", config.Format)
 	log.Printf("[DEBUG]   FormatSet: %t", config.FormatSet)
 	log.Printf("[DEBUG]   Varfiles: %s", strings.Join(config.Varfiles, ", "))
@@ -356,12 +583,50 @@ func loadConfig(file afero.File) (*Config, error) {
 	}
 	log.Printf("[DEBUG]   Plugins:")
 	for name, plugin := range config.Plugins {
-		log.Printf("[DEBUG]     %s: enabled=%t, version=%s, source=%s", name, plugin.Enabled, plugin.Version, plugin.Source)
+		log.Printf("[DEBUG]     %s: enabled=%t, version=%s, source=%s, timeout=%s", name, plugin.Enabled, plugin.Version, plugin.Source, plugin.Timeout)
+	}
+	log.Printf("[DEBUG]   Presets:")
+	for name, preset := range config.Presets {
+		log.Printf("[DEBUG]     %s: %s", name, strings.Join(preset.Rules, ", "))
+	}
+	log.Printf("[DEBUG]   CustomRules:")
+	for _, rule := range config.CustomRules {
+		log.Printf("[DEBUG]     %s: enabled=%t, resource_type=%s, severity=%s", rule.Name, rule.Enabled, rule.ResourceType, rule.Severity)
+	}
+	log.Printf("[DEBUG]   Overrides:")
+	for _, override := range config.Overrides {
+		for _, rule := range override.Rules {
+			log.Printf("[DEBUG]     %s: %s: %t", override.Pattern, rule.Name, rule.Enabled)
+		}
 	}
 
 	return config, nil
 }
 
+// IsDefaultConfigFile returns true if the base name of the path is one of the default config file names.
+func IsDefaultConfigFile(path string) bool {
+	return slices.Contains(defaultConfigFiles, filepath.Base(path))
+}
+
+// isJSONConfigFile returns true if the passed file should be parsed as JSON syntax.
+func isJSONConfigFile(name string) bool {
+	return strings.HasSuffix(name, ".json")
+}
+
+// jsonPluginConfigFilename returns the pseudo file name under which the JSON config file is passed to plugins.
+// For example, ".tflint.json" is passed as ".tflint.tf.json".
+func jsonPluginConfigFilename(name string) string {
+	return strings.TrimSuffix(name, ".json") + ".tf.json"
+}
+
+// pluginBody returns the body of the block passed to plugins,
+// excluding attributes decoded into the passed value.
+func pluginBody(block *hcl.Block, val any) hcl.Body {
+	schema, _ := gohcl.ImpliedBodySchema(val)
+	_, remain, _ := block.Body.PartialContent(schema)
+	return remain
+}
+
 // checkVersionRequirement checks whether the TFLint version satisfy the "required_version".
 // At the time of this check, we do not know if other schema meet our requirements,
 // so we only extract the minimal schema. Note that it therefore needs to be independent of loadConfig.
@@ -477,6 +742,12 @@ func (c *Config) enableBundledPlugin() *Config {
 	return c
 }
 
+// File returns the path of the loaded config file.
+// If the default config is used, an empty string is returned.
+func (c *Config) File() string {
+	return c.file
+}
+
 // Sources returns parsed config file sources.
 // To support bundle plugin config, this function returns c.sources
 // with a merge of the pseudo config file.
@@ -508,6 +779,22 @@ func (c *Config) Merge(other *Config) {
 		c.PluginDirSet = true
 		c.PluginDir = other.PluginDir
 	}
+	if other.PluginMirrorSet {
+		c.PluginMirrorSet = true
+		c.PluginMirror = other.PluginMirror
+	}
+	if other.PluginCacheDirSet {
+		c.PluginCacheDirSet = true
+		c.PluginCacheDir = other.PluginCacheDir
+	}
+	if other.PluginVerificationSet {
+		c.PluginVerificationSet = true
+		c.PluginVerification = other.PluginVerification
+	}
+	if other.PolicyDirSet {
+		c.PolicyDirSet = true
+		c.PolicyDir = other.PolicyDir
+	}
 	if other.FormatSet {
 		c.FormatSet = true
 		c.Format = other.Format
@@ -519,6 +806,9 @@ func (c *Config) Merge(other *Config) {
 
 	maps.Copy(c.IgnoreModules, other.IgnoreModules)
 
+	c.Overrides = append(c.Overrides, other.Overrides...)
+	c.CustomRules = append(c.CustomRules, other.CustomRules...)
+
 	for name, rule := range other.Rules {
 		// HACK: If you enable the rule through the CLI instead of the file, its hcl.Body will be nil.
 		//       In this case, only override Enabled flag
@@ -527,6 +817,12 @@ func (c *Config) Merge(other *Config) {
 		} else {
 			c.Rules[name] = rule
 		}
+		if rule.Body == nil && !rule.Enabled {
+			if c.disabledRules == nil {
+				c.disabledRules = map[string]bool{}
+			}
+			c.disabledRules[name] = true
+		}
 	}
 
 	for name, plugin := range other.Plugins {
@@ -538,6 +834,38 @@ func (c *Config) Merge(other *Config) {
 			c.Plugins[name] = plugin
 		}
 	}
+
+	for name, preset := range other.Presets {
+		if c.Presets == nil {
+			c.Presets = map[string]*PresetConfig{}
+		}
+		c.Presets[name] = preset
+	}
+
+	if other.PluginLogDir != "" {
+		c.PluginLogDir = other.PluginLogDir
+	}
+
+	// Presets selected by the --preset option are expanded into rules.
+	// Rules passed by the --enable-rule and --disable-rule options take precedence over presets.
+	// Unknown presets are reported by ValidateRules.
+	c.EnabledPresets = append(c.EnabledPresets, other.EnabledPresets...)
+	for _, name := range other.EnabledPresets {
+		preset, exists := c.Presets[name]
+		if !exists {
+			continue
+		}
+		for _, rule := range preset.Rules {
+			if _, exists := other.Rules[rule]; exists {
+				continue
+			}
+			if _, exists := c.Rules[rule]; exists {
+				c.Rules[rule].Enabled = true
+			} else {
+				c.Rules[rule] = &RuleConfig{Name: rule, Enabled: true, Body: nil}
+			}
+		}
+	}
 }
 
 // ToPluginConfig converts self into the plugin configuration format
@@ -553,9 +881,137 @@ func (c *Config) ToPluginConfig() *sdk.Config {
 			Enabled: rule.Enabled,
 		}
 	}
+	// Rules enabled by override blocks must be run by plugins.
+	// Issues in files that do not match the pattern are ignored by the runner.
+	for _, override := range c.Overrides {
+		for _, rule := range override.Rules {
+			if !rule.Enabled || c.disabledRules[rule.Name] {
+				continue
+			}
+			cfg.Rules[rule.Name] = &sdk.RuleConfig{
+				Name:    rule.Name,
+				Enabled: true,
+			}
+		}
+	}
 	return cfg
 }
 
+// IsRuleEnabledFor returns whether the rule is enabled for the passed file.
+// The rule config is resolved in the following order:
+//
+// 1. "enabled" attribute in the rule block
+// 2. "include_paths" and "exclude_paths" attributes in the rule block
+// 3. override blocks matching the file (the last one wins)
+// 4. --disable-rule option (always disables the rule)
+//
+// Rules without rule blocks are considered enabled because they are
+// run by plugins only if enabled by default. If disabled_by_default is set,
+// they are disabled, as plugins run them only if enabled in override blocks.
+func (c *Config) IsRuleEnabledFor(name string, filename string) bool {
+	if c.disabledRules[name] {
+		return false
+	}
+	path := filepath.ToSlash(filename)
+
+	enabled := true
+	if rule, exists := c.Rules[name]; exists {
+		enabled = rule.Enabled
+		if len(rule.IncludePaths) > 0 && !matchPathPatterns(rule.IncludePaths, path) {
+			enabled = false
+		}
+		if matchPathPatterns(rule.ExcludePaths, path) {
+			enabled = false
+		}
+	} else if c.DisabledByDefault {
+		enabled = false
+	}
+
+	for _, override := range c.Overrides {
+		if !matchPathPatterns([]string{override.Pattern}, path) {
+			continue
+		}
+		for _, rule := range override.Rules {
+			if rule.Name == name {
+				enabled = rule.Enabled
+			}
+		}
+	}
+
+	return enabled
+}
+
+// IsRuleEnabled returns whether the rule is enabled regardless of paths.
+// Plugins don't expose whether rules are enabled by default, so the caller
+// passes it. Use IsRuleEnabledFor for path-based rule configs.
+func (c *Config) IsRuleEnabled(name string, enabledByDefault bool) bool {
+	return c.isBuiltinRuleEnabled(name, enabledByDefault)
+}
+
+// isCustomRuleEnabled returns whether the custom rule should be run.
+// The "enabled" attribute of the custom_rule block is treated as the default.
+func (c *Config) isCustomRuleEnabled(name string) bool {
+	for _, rule := range c.CustomRules {
+		if rule.Name == name {
+			return c.isBuiltinRuleEnabled(name, rule.Enabled)
+		}
+	}
+	return false
+}
+
+// isBuiltinRuleEnabled returns whether the rule run by TFLint itself
+// (custom rules and policies) should be run. The priority is the same
+// as plugin rules, and the passed value is treated as the default.
+func (c *Config) isBuiltinRuleEnabled(name string, enabledByDefault bool) bool {
+	if len(c.Only) > 0 {
+		return slices.Contains(c.Only, name)
+	}
+	if c.disabledRules[name] {
+		return false
+	}
+	if c.enabledByOverride(name) {
+		return true
+	}
+	if rule, exists := c.Rules[name]; exists {
+		return rule.Enabled
+	}
+	if c.DisabledByDefault {
+		return false
+	}
+	return enabledByDefault
+}
+
+func (c *Config) enabledByOverride(name string) bool {
+	for _, override := range c.Overrides {
+		for _, rule := range override.Rules {
+			if rule.Name == name && rule.Enabled {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchPathPatterns(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		// Patterns are validated on loading, so errors are ignored
+		if matched, _ := doublestar.Match(pattern, path); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func validatePathPatterns(patterns ...string) error {
+	for _, pattern := range patterns {
+		// Match the pattern against itself to make sure that all parts of the pattern are parsed
+		if _, err := doublestar.Match(pattern, pattern); err != nil {
+			return fmt.Errorf(`"%s" is invalid glob pattern: %w`, pattern, err)
+		}
+	}
+	return nil
+}
+
 // Content extracts a plugin config based on the passed schema.
 func (c *PluginConfig) Content(schema *hclext.BodySchema) (*hclext.BodyContent, hcl.Diagnostics) {
 	if schema == nil {
@@ -596,16 +1052,50 @@ func (c *Config) ValidateRules(rulesets ...RuleSet) error {
 		}
 	}
 
+	for _, rule := range c.CustomRules {
+		if existsName, exists := rulesMap[rule.Name]; exists {
+			return fmt.Errorf(`"%s" is duplicated in %s and custom rules`, rule.Name, existsName)
+		}
+		rulesMap[rule.Name] = "custom rules"
+	}
+
 	for _, rule := range c.Rules {
 		if _, exists := rulesMap[rule.Name]; !exists {
 			return fmt.Errorf("Rule not found: %s", rule.Name)
 		}
 	}
+	for _, override := range c.Overrides {
+		for _, rule := range override.Rules {
+			if _, exists := rulesMap[rule.Name]; !exists {
+				return fmt.Errorf("Rule not found: %s", rule.Name)
+			}
+		}
+	}
+	for _, name := range c.EnabledPresets {
+		if _, exists := c.Presets[name]; !exists {
+			return fmt.Errorf("Preset not found: %s", name)
+		}
+	}
+	for _, preset := range c.Presets {
+		for _, rule := range preset.Rules {
+			if _, exists := rulesMap[rule]; !exists {
+				return fmt.Errorf(`Rule not found: %s (in preset "%s")`, rule, preset.Name)
+			}
+		}
+	}
 
 	return nil
 }
 
 func (c *PluginConfig) validate() error {
+	if c.Timeout != "" {
+		timeout, err := time.ParseDuration(c.Timeout)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf(`plugin "%s": "timeout" must be a positive duration like "5m", but got "%s"`, c.Name, c.Timeout)
+		}
+		c.CallTimeout = timeout
+	}
+
 	if c.Version != "" && c.Source == "" {
 		return fmt.Errorf(`plugin "%s": "source" attribute cannot be omitted when specifying "version"`, c.Name)
 	}
@@ -615,15 +1105,59 @@ func (c *PluginConfig) validate() error {
 			return fmt.Errorf(`plugin "%s": "version" attribute cannot be omitted when specifying "source"`, c.Name)
 		}
 
-		parts := strings.Split(c.Source, "/")
-		// Expected `github.com/owner/repo` format
-		if len(parts) != 3 {
-			return fmt.Errorf(`plugin "%s": "source" is invalid. Must be a GitHub reference in the format "${host}/${owner}/${repo}"`, c.Name)
+		if strings.HasPrefix(c.Source, "https://") {
+			if c.SourceType != "" {
+				return fmt.Errorf(`plugin "%s": "source_type" cannot be set for registry URLs`, c.Name)
+			}
+
+			// Expected `https://registry.example.com/ns/name` format
+			u, err := url.Parse(c.Source)
+			if err != nil || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+				return fmt.Errorf(`plugin "%s": "source" is invalid. Must be a registry URL in the format "https://${host}/${namespace}/${name}"`, c.Name)
+			}
+			parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+			if len(parts) < 2 || slices.Contains(parts, "") {
+				return fmt.Errorf(`plugin "%s": "source" is invalid. Must be a registry URL in the format "https://${host}/${namespace}/${name}"`, c.Name)
+			}
+
+			c.SourceRegistry = true
+			c.SourceHost = u.Host
+			c.SourceOwner = strings.Join(parts[:len(parts)-1], "/")
+			c.SourceRepo = parts[len(parts)-1]
+		} else {
+			parts := strings.Split(c.Source, "/")
+			if c.SourceType == "" && parts[0] == "gitlab.com" {
+				c.SourceType = "gitlab"
+			}
+
+			switch c.SourceType {
+			case "", "github":
+				// Expected `github.com/owner/repo` format
+				if len(parts) != 3 {
+					return fmt.Errorf(`plugin "%s": "source" is invalid. Must be a GitHub reference in the format "${host}/${owner}/${repo}"`, c.Name)
+				}
+			case "gitlab":
+				// Expected `gitlab.com/group/project` format. Groups can be nested like `gitlab.com/group/subgroup/project`
+				if len(parts) < 3 || slices.Contains(parts, "") {
+					return fmt.Errorf(`plugin "%s": "source" is invalid. Must be a GitLab reference in the format "${host}/${group}/${project}"`, c.Name)
+				}
+			default:
+				return fmt.Errorf(`plugin "%s": "source_type" must be "github" or "gitlab", but got "%s"`, c.Name, c.SourceType)
+			}
+
+			c.SourceHost = parts[0]
+			c.SourceOwner = strings.Join(parts[1:len(parts)-1], "/")
+			c.SourceRepo = parts[len(parts)-1]
 		}
 
-		c.SourceHost = parts[0]
-		c.SourceOwner = parts[1]
-		c.SourceRepo = parts[2]
+		// Exact versions like "0.30.0" are also valid constraints, but are treated as versions
+		if _, err := version.NewVersion(c.Version); err != nil {
+			constraints, err := version.NewConstraint(c.Version)
+			if err != nil {
+				return fmt.Errorf(`plugin "%s": "version" must be a version or a version constraint; %w`, c.Name, err)
+			}
+			c.VersionConstraints = constraints
+		}
 	}
 
 	return nil
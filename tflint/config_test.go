@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -40,6 +42,9 @@ tflint {
 config {
 	format = "compact"
 	plugin_dir = "~/.tflint.d/plugins"
+	plugin_mirror = "/opt/tflint-mirror"
+	plugin_cache_dir = "~/.tflint.d/plugin-cache"
+	plugin_verification = "required"
 
 	call_module_type = "all"
 	force = true
@@ -86,13 +91,19 @@ plugin "baz" {
 				IgnoreModules: map[string]bool{
 					"github.com/terraform-linters/example-module": true,
 				},
-				Varfiles:          []string{"example1.tfvars", "example2.tfvars"},
-				Variables:         []string{"foo=bar", "bar=['foo']"},
-				DisabledByDefault: false,
-				PluginDir:         "~/.tflint.d/plugins",
-				PluginDirSet:      true,
-				Format:            "compact",
-				FormatSet:         true,
+				Varfiles:              []string{"example1.tfvars", "example2.tfvars"},
+				Variables:             []string{"foo=bar", "bar=['foo']"},
+				DisabledByDefault:     false,
+				PluginDir:             "~/.tflint.d/plugins",
+				PluginDirSet:          true,
+				PluginMirror:          "/opt/tflint-mirror",
+				PluginMirrorSet:       true,
+				PluginCacheDir:        "~/.tflint.d/plugin-cache",
+				PluginCacheDirSet:     true,
+				PluginVerification:    "required",
+				PluginVerificationSet: true,
+				Format:                "compact",
+				FormatSet:             true,
 				Rules: map[string]*RuleConfig{
 					"aws_instance_invalid_type": {
 						Name:    "aws_instance_invalid_type",
@@ -326,6 +337,35 @@ plugin "foo" {
 				return err == nil || err.Error() != `plugin "foo": "version" attribute cannot be omitted when specifying "source"`
 			},
 		},
+		{
+			name: "plugin with invalid version",
+			file: "plugin_with_invalid_version.hcl",
+			files: map[string]string{
+				"plugin_with_invalid_version.hcl": `
+plugin "foo" {
+	enabled = true
+
+	source  = "github.com/foo/bar"
+	version = "latest"
+}`,
+			},
+			errCheck: func(err error) bool {
+				return err == nil || err.Error() != `plugin "foo": "version" must be a version or a version constraint; Malformed constraint: latest`
+			},
+		},
+		{
+			name: "invalid plugin_verification",
+			file: "invalid_plugin_verification.hcl",
+			files: map[string]string{
+				"invalid_plugin_verification.hcl": `
+config {
+	plugin_verification = "strict"
+}`,
+			},
+			errCheck: func(err error) bool {
+				return err == nil || err.Error() != "strict is invalid plugin_verification. Allowed values are: required, warn, off"
+			},
+		},
 		{
 			name: "plugin with invalid source",
 			file: "plugin_with_invalid_source.hcl",
@@ -342,6 +382,22 @@ plugin "foo" {
 				return err == nil || err.Error() != `plugin "foo": "source" is invalid. Must be a GitHub reference in the format "${host}/${owner}/${repo}"`
 			},
 		},
+		{
+			name: "plugin with invalid registry source",
+			file: "plugin_with_invalid_registry_source.hcl",
+			files: map[string]string{
+				"plugin_with_invalid_registry_source.hcl": `
+plugin "foo" {
+	enabled = true
+
+	version = "0.1.0"
+	source = "https://registry.example.com/foo"
+}`,
+			},
+			errCheck: func(err error) bool {
+				return err == nil || err.Error() != `plugin "foo": "source" is invalid. Must be a registry URL in the format "https://${host}/${namespace}/${name}"`
+			},
+		},
 		{
 			name: "plugin with GHES source host",
 			file: "plugin_with_ghes_source_host.hcl",
@@ -380,6 +436,166 @@ plugin "foo" {
 			},
 			errCheck: neverHappend,
 		},
+		{
+			name: "plugin with registry source",
+			file: "plugin_with_registry_source.hcl",
+			files: map[string]string{
+				"plugin_with_registry_source.hcl": `
+plugin "foo" {
+	enabled = true
+
+	version = "0.1.0"
+	source = "https://registry.example.com/foo/bar"
+}`,
+			},
+			want: &Config{
+				CallModuleType:    terraform.CallLocalModule,
+				Force:             false,
+				IgnoreModules:     map[string]bool{},
+				Varfiles:          []string{},
+				Variables:         []string{},
+				DisabledByDefault: false,
+				Rules:             map[string]*RuleConfig{},
+				Plugins: map[string]*PluginConfig{
+					"foo": {
+						Name:           "foo",
+						Enabled:        true,
+						Version:        "0.1.0",
+						Source:         "https://registry.example.com/foo/bar",
+						SourceHost:     "registry.example.com",
+						SourceOwner:    "foo",
+						SourceRepo:     "bar",
+						SourceRegistry: true,
+					},
+					"terraform": {
+						Name:    "terraform",
+						Enabled: true,
+					},
+				},
+			},
+			errCheck: neverHappend,
+		},
+		{
+			name: "plugin with GitLab source",
+			file: "plugin_with_gitlab_source.hcl",
+			files: map[string]string{
+				"plugin_with_gitlab_source.hcl": `
+plugin "foo" {
+	enabled = true
+
+	version = "0.1.0"
+	source = "gitlab.com/foo/bar/baz"
+}
+
+plugin "bar" {
+	enabled = true
+
+	version = "0.1.0"
+	source = "gitlab.example.com/foo/bar"
+	source_type = "gitlab"
+}`,
+			},
+			want: &Config{
+				CallModuleType:    terraform.CallLocalModule,
+				Force:             false,
+				IgnoreModules:     map[string]bool{},
+				Varfiles:          []string{},
+				Variables:         []string{},
+				DisabledByDefault: false,
+				Rules:             map[string]*RuleConfig{},
+				Plugins: map[string]*PluginConfig{
+					"foo": {
+						Name:        "foo",
+						Enabled:     true,
+						Version:     "0.1.0",
+						Source:      "gitlab.com/foo/bar/baz",
+						SourceType:  "gitlab",
+						SourceHost:  "gitlab.com",
+						SourceOwner: "foo/bar",
+						SourceRepo:  "baz",
+					},
+					"bar": {
+						Name:        "bar",
+						Enabled:     true,
+						Version:     "0.1.0",
+						Source:      "gitlab.example.com/foo/bar",
+						SourceType:  "gitlab",
+						SourceHost:  "gitlab.example.com",
+						SourceOwner: "foo",
+						SourceRepo:  "bar",
+					},
+					"terraform": {
+						Name:    "terraform",
+						Enabled: true,
+					},
+				},
+			},
+			errCheck: neverHappend,
+		},
+		{
+			name: "plugin with invalid source type",
+			file: "plugin_with_invalid_source_type.hcl",
+			files: map[string]string{
+				"plugin_with_invalid_source_type.hcl": `
+plugin "foo" {
+	enabled = true
+
+	version = "0.1.0"
+	source = "example.com/foo/bar"
+	source_type = "bitbucket"
+}`,
+			},
+			errCheck: func(err error) bool {
+				return err == nil || err.Error() != `plugin "foo": "source_type" must be "github" or "gitlab", but got "bitbucket"`
+			},
+		},
+		{
+			name: "plugin with timeout",
+			file: "plugin_with_timeout.hcl",
+			files: map[string]string{
+				"plugin_with_timeout.hcl": `
+plugin "foo" {
+	enabled = true
+	timeout = "90s"
+}`,
+			},
+			want: &Config{
+				CallModuleType:    terraform.CallLocalModule,
+				Force:             false,
+				IgnoreModules:     map[string]bool{},
+				Varfiles:          []string{},
+				Variables:         []string{},
+				DisabledByDefault: false,
+				Rules:             map[string]*RuleConfig{},
+				Plugins: map[string]*PluginConfig{
+					"foo": {
+						Name:        "foo",
+						Enabled:     true,
+						Timeout:     "90s",
+						CallTimeout: 90 * time.Second,
+					},
+					"terraform": {
+						Name:    "terraform",
+						Enabled: true,
+					},
+				},
+			},
+			errCheck: neverHappend,
+		},
+		{
+			name: "plugin with invalid timeout",
+			file: "plugin_with_invalid_timeout.hcl",
+			files: map[string]string{
+				"plugin_with_invalid_timeout.hcl": `
+plugin "foo" {
+	enabled = true
+	timeout = "0s"
+}`,
+			},
+			errCheck: func(err error) bool {
+				return err == nil || err.Error() != `plugin "foo": "timeout" must be a positive duration like "5m", but got "0s"`
+			},
+		},
 		{
 			name: "prefer the passed file over TFLINT_CONFIG_FILE",
 			file: "cli.hcl",
@@ -459,6 +675,317 @@ tflint {
 				return err == nil || err.Error() != `config.hcl:6,1-7: Multiple "tflint" blocks are not allowed; The "tflint" block is already found in config.hcl:2,1-7, but found the second one.`
 			},
 		},
+		{
+			name: "load JSON file",
+			file: "config.json",
+			files: map[string]string{
+				"config.json": `
+{
+  "tflint": {
+    "required_version": ">= 0"
+  },
+  "config": {
+    "format": "compact",
+    "call_module_type": "all",
+    "force": true,
+    "ignore_module": {
+      "github.com/terraform-linters/example-module": true
+    },
+    "varfile": ["example1.tfvars"],
+    "variables": ["foo=bar"]
+  },
+  "rule": {
+    "aws_instance_invalid_type": {
+      "enabled": false
+    }
+  },
+  "plugin": {
+    "bar": {
+      "enabled": false,
+      "version": "0.1.0",
+      "source": "github.com/foo/bar"
+    }
+  }
+}`,
+			},
+			want: &Config{
+				CallModuleType:    terraform.CallAllModule,
+				CallModuleTypeSet: true,
+				Force:             true,
+				ForceSet:          true,
+				IgnoreModules: map[string]bool{
+					"github.com/terraform-linters/example-module": true,
+				},
+				Varfiles:  []string{"example1.tfvars"},
+				Variables: []string{"foo=bar"},
+				Format:    "compact",
+				FormatSet: true,
+				Rules: map[string]*RuleConfig{
+					"aws_instance_invalid_type": {
+						Name:    "aws_instance_invalid_type",
+						Enabled: false,
+					},
+				},
+				Plugins: map[string]*PluginConfig{
+					"bar": {
+						Name:        "bar",
+						Enabled:     false,
+						Version:     "0.1.0",
+						Source:      "github.com/foo/bar",
+						SourceHost:  "github.com",
+						SourceOwner: "foo",
+						SourceRepo:  "bar",
+					},
+					"terraform": {
+						Name:    "terraform",
+						Enabled: true,
+					},
+				},
+			},
+			errCheck: neverHappend,
+		},
+		{
+			name: "default JSON config",
+			file: "",
+			files: map[string]string{
+				".tflint.json": `{"config": {"force": true}}`,
+			},
+			want: &Config{
+				CallModuleType: terraform.CallLocalModule,
+				Force:          true,
+				ForceSet:       true,
+				IgnoreModules:  map[string]bool{},
+				Varfiles:       []string{},
+				Variables:      []string{},
+				Rules:          map[string]*RuleConfig{},
+				Plugins: map[string]*PluginConfig{
+					"terraform": {
+						Name:    "terraform",
+						Enabled: true,
+					},
+				},
+			},
+			errCheck: neverHappend,
+		},
+		{
+			name: "prefer HCL config over JSON config",
+			file: "",
+			files: map[string]string{
+				".tflint.hcl": `
+config {
+	force = true
+}`,
+				".tflint.hcl.json": `{"config": {"force": false}}`,
+			},
+			want: &Config{
+				CallModuleType: terraform.CallLocalModule,
+				Force:          true,
+				ForceSet:       true,
+				IgnoreModules:  map[string]bool{},
+				Varfiles:       []string{},
+				Variables:      []string{},
+				Rules:          map[string]*RuleConfig{},
+				Plugins: map[string]*PluginConfig{
+					"terraform": {
+						Name:    "terraform",
+						Enabled: true,
+					},
+				},
+			},
+			errCheck: neverHappend,
+		},
+		{
+			name: "JSON syntax error",
+			file: "syntax_error.json",
+			files: map[string]string{
+				"syntax_error.json": `{`,
+			},
+			errCheck: func(err error) bool {
+				return err == nil || !strings.Contains(err.Error(), "syntax_error.json:1,2-2")
+			},
+		},
+		{
+			name: "rule paths and overrides",
+			file: "config.hcl",
+			files: map[string]string{
+				"config.hcl": `
+rule "terraform_naming_convention" {
+	enabled = true
+	include_paths = ["modules/**"]
+	exclude_paths = ["modules/legacy/**"]
+}
+
+override "examples/**" {
+	rule "terraform_documented_variables" {
+		enabled = false
+	}
+}`,
+			},
+			want: &Config{
+				CallModuleType: terraform.CallLocalModule,
+				IgnoreModules:  map[string]bool{},
+				Varfiles:       []string{},
+				Variables:      []string{},
+				Rules: map[string]*RuleConfig{
+					"terraform_naming_convention": {
+						Name:         "terraform_naming_convention",
+						Enabled:      true,
+						IncludePaths: []string{"modules/**"},
+						ExcludePaths: []string{"modules/legacy/**"},
+					},
+				},
+				Plugins: map[string]*PluginConfig{
+					"terraform": {
+						Name:    "terraform",
+						Enabled: true,
+					},
+				},
+				Overrides: []*OverrideConfig{
+					{
+						Pattern: "examples/**",
+						Rules: []*OverrideRuleConfig{
+							{Name: "terraform_documented_variables", Enabled: false},
+						},
+					},
+				},
+			},
+			errCheck: neverHappend,
+		},
+		{
+			name: "invalid override pattern",
+			file: "config.hcl",
+			files: map[string]string{
+				"config.hcl": `
+override "[" {
+	rule "terraform_documented_variables" {
+		enabled = false
+	}
+}`,
+			},
+			errCheck: func(err error) bool {
+				return err == nil || err.Error() != `override "[": "[" is invalid glob pattern: syntax error in pattern`
+			},
+		},
+		{
+			name: "unsupported attribute in override",
+			file: "config.hcl",
+			files: map[string]string{
+				"config.hcl": `
+override "examples/**" {
+	rule "terraform_naming_convention" {
+		enabled = true
+		format = "snake_case"
+	}
+}`,
+			},
+			errCheck: func(err error) bool {
+				return err == nil || !strings.Contains(err.Error(), `An argument named "format" is not expected here.`)
+			},
+		},
+		{
+			name: "presets",
+			file: "config.hcl",
+			files: map[string]string{
+				"config.hcl": `
+rule "aws_instance_invalid_type" {
+	enabled = false
+}
+
+preset "security" {
+	enabled = true
+	rules = ["aws_instance_invalid_type", "aws_instance_invalid_ami"]
+}
+
+preset "naming" {
+	rules = ["terraform_naming_convention"]
+}`,
+			},
+			want: &Config{
+				CallModuleType: terraform.CallLocalModule,
+				IgnoreModules:  map[string]bool{},
+				Varfiles:       []string{},
+				Variables:      []string{},
+				Rules: map[string]*RuleConfig{
+					"aws_instance_invalid_type": {
+						Name:    "aws_instance_invalid_type",
+						Enabled: false,
+					},
+					"aws_instance_invalid_ami": {
+						Name:    "aws_instance_invalid_ami",
+						Enabled: true,
+					},
+				},
+				Plugins: map[string]*PluginConfig{
+					"terraform": {
+						Name:    "terraform",
+						Enabled: true,
+					},
+				},
+				Presets: map[string]*PresetConfig{
+					"security": {
+						Name:    "security",
+						Enabled: func() *bool { b := true; return &b }(),
+						Rules:   []string{"aws_instance_invalid_type", "aws_instance_invalid_ami"},
+					},
+					"naming": {
+						Name:  "naming",
+						Rules: []string{"terraform_naming_convention"},
+					},
+				},
+			},
+			errCheck: neverHappend,
+		},
+		{
+			name: "conflicting presets",
+			file: "config.hcl",
+			files: map[string]string{
+				"config.hcl": `
+preset "all" {
+	enabled = true
+	rules = ["aws_instance_invalid_type", "aws_instance_invalid_ami"]
+}
+
+preset "noisy" {
+	enabled = false
+	rules = ["aws_instance_invalid_type"]
+}`,
+			},
+			want: &Config{
+				CallModuleType: terraform.CallLocalModule,
+				IgnoreModules:  map[string]bool{},
+				Varfiles:       []string{},
+				Variables:      []string{},
+				Rules: map[string]*RuleConfig{
+					"aws_instance_invalid_type": {
+						Name:    "aws_instance_invalid_type",
+						Enabled: false,
+					},
+					"aws_instance_invalid_ami": {
+						Name:    "aws_instance_invalid_ami",
+						Enabled: true,
+					},
+				},
+				Plugins: map[string]*PluginConfig{
+					"terraform": {
+						Name:    "terraform",
+						Enabled: true,
+					},
+				},
+				Presets: map[string]*PresetConfig{
+					"all": {
+						Name:    "all",
+						Enabled: func() *bool { b := true; return &b }(),
+						Rules:   []string{"aws_instance_invalid_type", "aws_instance_invalid_ami"},
+					},
+					"noisy": {
+						Name:    "noisy",
+						Enabled: func() *bool { b := false; return &b }(),
+						Rules:   []string{"aws_instance_invalid_type"},
+					},
+				},
+			},
+			errCheck: neverHappend,
+		},
 		{
 			name: "removed module attribute",
 			file: "config.hcl",
@@ -951,6 +1478,54 @@ func TestMerge(t *testing.T) {
 	}
 }
 
+func TestMerge_presets(t *testing.T) {
+	file, diags := hclsyntax.ParseConfig([]byte(`foo = "bar"`), "test.hcl", hcl.Pos{})
+	if diags.HasErrors() {
+		t.Fatalf("Failed to parse test config: %s", diags)
+	}
+
+	config := EmptyConfig()
+	config.Rules["aws_instance_invalid_type"] = &RuleConfig{Name: "aws_instance_invalid_type", Enabled: false, Body: file.Body}
+	config.Presets = map[string]*PresetConfig{
+		"security": {
+			Name:  "security",
+			Rules: []string{"aws_instance_invalid_type", "aws_instance_invalid_ami", "aws_instance_previous_type"},
+		},
+	}
+
+	cli := EmptyConfig()
+	cli.Rules["aws_instance_previous_type"] = &RuleConfig{Name: "aws_instance_previous_type", Enabled: false}
+	cli.EnabledPresets = []string{"security", "unknown"}
+
+	config.Merge(cli)
+
+	want := map[string]*RuleConfig{
+		"aws_instance_invalid_type": {
+			Name:    "aws_instance_invalid_type",
+			Enabled: true,
+			Body:    file.Body,
+		},
+		"aws_instance_invalid_ami": {
+			Name:    "aws_instance_invalid_ami",
+			Enabled: true,
+		},
+		"aws_instance_previous_type": {
+			Name:    "aws_instance_previous_type",
+			Enabled: false,
+		},
+	}
+	opts := []cmp.Option{
+		cmpopts.IgnoreUnexported(hclsyntax.Body{}),
+		cmpopts.IgnoreFields(hclsyntax.Body{}, "Attributes", "Blocks"),
+	}
+	if diff := cmp.Diff(want, config.Rules, opts...); diff != "" {
+		t.Fatal(diff)
+	}
+	if diff := cmp.Diff([]string{"security", "unknown"}, config.EnabledPresets); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
 func Test_ToPluginConfig(t *testing.T) {
 	src := `
 config {
@@ -1010,6 +1585,176 @@ plugin "bar" {
 	}
 }
 
+func Test_ToPluginConfig_overrides(t *testing.T) {
+	config := EmptyConfig()
+	config.Rules["aws_instance_invalid_type"] = &RuleConfig{Name: "aws_instance_invalid_type", Enabled: false}
+	config.Overrides = []*OverrideConfig{
+		{
+			Pattern: "modules/**",
+			Rules: []*OverrideRuleConfig{
+				{Name: "aws_instance_invalid_type", Enabled: true},
+				{Name: "aws_instance_invalid_ami", Enabled: false},
+			},
+		},
+	}
+
+	got := config.ToPluginConfig()
+	want := &sdk.Config{
+		Rules: map[string]*sdk.RuleConfig{
+			"aws_instance_invalid_type": {
+				Name:    "aws_instance_invalid_type",
+				Enabled: true,
+			},
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestIsRuleEnabledFor(t *testing.T) {
+	config := &Config{
+		Rules: map[string]*RuleConfig{
+			"included": {
+				Name:         "included",
+				Enabled:      true,
+				IncludePaths: []string{"modules/**"},
+				ExcludePaths: []string{"modules/legacy/**"},
+			},
+			"disabled": {
+				Name:    "disabled",
+				Enabled: false,
+			},
+		},
+		Overrides: []*OverrideConfig{
+			{
+				Pattern: "examples/**",
+				Rules: []*OverrideRuleConfig{
+					{Name: "default", Enabled: false},
+					{Name: "disabled", Enabled: true},
+					{Name: "override_only", Enabled: true},
+				},
+			},
+			{
+				Pattern: "examples/legacy/*.tf",
+				Rules: []*OverrideRuleConfig{
+					{Name: "disabled", Enabled: false},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		rule     string
+		filename string
+		want     bool
+	}{
+		{name: "no config", rule: "default", filename: "main.tf", want: true},
+		{name: "disabled by override", rule: "default", filename: "examples/basic/main.tf", want: false},
+		{name: "included", rule: "included", filename: "modules/vpc/main.tf", want: true},
+		{name: "not included", rule: "included", filename: "live/main.tf", want: false},
+		{name: "excluded", rule: "included", filename: "modules/legacy/vpc/main.tf", want: false},
+		{name: "disabled", rule: "disabled", filename: "main.tf", want: false},
+		{name: "enabled by override", rule: "disabled", filename: "examples/basic/main.tf", want: true},
+		{name: "last override wins", rule: "disabled", filename: "examples/legacy/main.tf", want: false},
+		{name: "enabled only by override", rule: "override_only", filename: "examples/main.tf", want: true},
+		// The rule may be enabled by default in the plugin, so it is not disabled outside of override blocks
+		{name: "outside of override", rule: "override_only", filename: "main.tf", want: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := config.IsRuleEnabledFor(test.rule, test.filename)
+			if got != test.want {
+				t.Fatalf("want %t, got %t", test.want, got)
+			}
+		})
+	}
+}
+
+func TestIsRuleEnabledFor_disabledByDefault(t *testing.T) {
+	config := &Config{
+		DisabledByDefault: true,
+		Rules: map[string]*RuleConfig{
+			"enabled": {Name: "enabled", Enabled: true},
+		},
+		Overrides: []*OverrideConfig{
+			{
+				Pattern: "modules/**",
+				Rules: []*OverrideRuleConfig{
+					{Name: "enabled", Enabled: true},
+					{Name: "override_only", Enabled: true},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		rule     string
+		filename string
+		want     bool
+	}{
+		{name: "enabled only by override", rule: "override_only", filename: "modules/vpc/main.tf", want: true},
+		{name: "outside of override", rule: "override_only", filename: "main.tf", want: false},
+		{name: "enabled by rule block", rule: "enabled", filename: "main.tf", want: true},
+		{name: "no config", rule: "default", filename: "main.tf", want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := config.IsRuleEnabledFor(test.rule, test.filename)
+			if got != test.want {
+				t.Fatalf("want %t, got %t", test.want, got)
+			}
+		})
+	}
+}
+
+func TestIsRuleEnabledFor_disableRule(t *testing.T) {
+	config := EmptyConfig()
+	config.Rules["terraform_unused_declarations"] = &RuleConfig{Name: "terraform_unused_declarations", Enabled: true}
+	config.Overrides = []*OverrideConfig{
+		{
+			Pattern: "modules/**",
+			Rules: []*OverrideRuleConfig{
+				{Name: "terraform_unused_declarations", Enabled: true},
+				{Name: "terraform_documented_variables", Enabled: true},
+			},
+		},
+	}
+	// --disable-rule=terraform_unused_declarations --disable-rule=terraform_documented_variables
+	config.Merge(&Config{
+		Rules: map[string]*RuleConfig{
+			"terraform_unused_declarations":  {Name: "terraform_unused_declarations", Enabled: false},
+			"terraform_documented_variables": {Name: "terraform_documented_variables", Enabled: false},
+		},
+	})
+
+	for _, rule := range []string{"terraform_unused_declarations", "terraform_documented_variables"} {
+		for _, filename := range []string{"main.tf", "modules/vpc/main.tf"} {
+			if config.IsRuleEnabledFor(rule, filename) {
+				t.Errorf("%s should be disabled for %s", rule, filename)
+			}
+		}
+		if config.IsRuleEnabled(rule, true) {
+			t.Errorf("%s should be disabled", rule)
+		}
+	}
+
+	got := config.ToPluginConfig()
+	want := &sdk.Config{
+		Rules: map[string]*sdk.RuleConfig{
+			"terraform_unused_declarations":  {Name: "terraform_unused_declarations", Enabled: false},
+			"terraform_documented_variables": {Name: "terraform_documented_variables", Enabled: false},
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
 func TestPluginContent(t *testing.T) {
 	tests := []struct {
 		Name      string
@@ -1211,6 +1956,37 @@ func Test_ValidateRules(t *testing.T) {
 			RuleSets: []RuleSet{&ruleSetB{}},
 			Err:      errors.New("Rule not found: aws_instance_invalid_type"),
 		},
+		{
+			Name: "preset not found",
+			Config: &Config{
+				EnabledPresets: []string{"security"},
+			},
+			RuleSets: []RuleSet{&ruleSetA{}, &ruleSetB{}},
+			Err:      errors.New("Preset not found: security"),
+		},
+		{
+			Name: "not found in preset",
+			Config: &Config{
+				Presets: map[string]*PresetConfig{
+					"security": {Name: "security", Rules: []string{"aws_instance_unknown"}},
+				},
+			},
+			RuleSets: []RuleSet{&ruleSetA{}, &ruleSetB{}},
+			Err:      errors.New(`Rule not found: aws_instance_unknown (in preset "security")`),
+		},
+		{
+			Name: "not found in override",
+			Config: &Config{
+				Overrides: []*OverrideConfig{
+					{
+						Pattern: "examples/**",
+						Rules:   []*OverrideRuleConfig{{Name: "aws_instance_unknown", Enabled: false}},
+					},
+				},
+			},
+			RuleSets: []RuleSet{&ruleSetA{}, &ruleSetB{}},
+			Err:      errors.New("Rule not found: aws_instance_unknown"),
+		},
 	}
 
 	for _, tc := range cases {
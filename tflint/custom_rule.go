@@ -0,0 +1,199 @@
+package tflint
+
+import (
+	"fmt"
+	"log"
+	"slices"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// CustomRuleConfig is a TFLint's custom rule config.
+// Custom rules are simple policies declared in the config file without plugins.
+type CustomRuleConfig struct {
+	Name         string         `hcl:"name,label"`
+	Enabled      bool           `hcl:"enabled,optional"`
+	ResourceType string         `hcl:"resource_type"`
+	Condition    hcl.Expression `hcl:"condition"`
+	Message      hcl.Expression `hcl:"message"`
+	Severity     string         `hcl:"severity,optional"`
+	Link         string         `hcl:"link,optional"`
+}
+
+func (c *CustomRuleConfig) validate() error {
+	if _, err := NewSeverity(c.Severity); err != nil {
+		return fmt.Errorf(`custom_rule "%s": %w`, c.Name, err)
+	}
+	if c.ResourceType == "" {
+		return fmt.Errorf(`custom_rule "%s": "resource_type" must not be empty`, c.Name)
+	}
+	return nil
+}
+
+// customRule is an implementation of Rule for custom rules.
+type customRule struct {
+	config *CustomRuleConfig
+}
+
+var _ Rule = (*customRule)(nil)
+
+func (r *customRule) Name() string {
+	return r.config.Name
+}
+
+func (r *customRule) Severity() Severity {
+	// Severity is validated on loading, so errors are ignored
+	severity, _ := NewSeverity(r.config.Severity)
+	return severity
+}
+
+func (r *customRule) Link() string {
+	return r.config.Link
+}
+
+// RunCustomRules checks the module against custom rules declared in the config file.
+// Issues are emitted to the runner in the same way as plugins.
+func (r *Runner) RunCustomRules() error {
+	for _, config := range r.config.CustomRules {
+		if !r.config.isCustomRuleEnabled(config.Name) {
+			log.Printf("[DEBUG] Custom rule %s is disabled", config.Name)
+			continue
+		}
+		if err := r.checkCustomRule(&customRule{config: config}); err != nil {
+			return fmt.Errorf(`Failed to check custom rule "%s"; %w`, config.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *Runner) checkCustomRule(rule *customRule) error {
+	// For performance, determine in advance whether the target resource exists.
+	if _, exists := r.TFConfig.Module.Resources[rule.config.ResourceType]; !exists {
+		return nil
+	}
+
+	attrNames := selfAttributeNames(rule.config.Condition, rule.config.Message)
+	attrSchema := make([]hclext.AttributeSchema, len(attrNames))
+	for i, name := range attrNames {
+		attrSchema[i] = hclext.AttributeSchema{Name: name}
+	}
+
+	content, diags := r.TFConfig.Module.PartialContent(&hclext.BodySchema{
+		Blocks: []hclext.BlockSchema{
+			{
+				Type:       "resource",
+				LabelNames: []string{"type", "name"},
+				Body:       &hclext.BodySchema{Attributes: attrSchema},
+			},
+		},
+	}, r.Ctx)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	for _, resource := range content.Blocks {
+		if resource.Labels[0] != rule.config.ResourceType {
+			continue
+		}
+
+		// Build the "self" object from the attributes referenced in the rule.
+		// Missing attributes are null as in Terraform.
+		attrs := map[string]cty.Value{}
+		for _, name := range attrNames {
+			attr, exists := resource.Body.Attributes[name]
+			if !exists {
+				attrs[name] = cty.NullVal(cty.DynamicPseudoType)
+				continue
+			}
+			val, diags := r.Ctx.EvaluateExpr(attr.Expr, cty.DynamicPseudoType)
+			if diags.HasErrors() {
+				// Like unknown values, the resource is skipped unless the condition is determined without the attribute
+				log.Printf("[WARN] Failed to evaluate %s of %s.%s for %s. It is treated as unknown; %s", name, resource.Labels[0], resource.Labels[1], rule.Name(), diags)
+				val = cty.DynamicVal
+			}
+			attrs[name] = val
+		}
+		self := cty.ObjectVal(attrs)
+
+		// Conditions that cannot be determined skip the resource rather than aborting the inspection
+		cond, diags := r.Ctx.EvaluateExprWithSelf(rule.config.Condition, self, cty.Bool)
+		if diags.HasErrors() {
+			log.Printf("[DEBUG] Failed to evaluate the condition of %s for %s.%s; %s", rule.Name(), resource.Labels[0], resource.Labels[1], diags)
+			continue
+		}
+		cond, _ = cond.UnmarkDeep()
+		if !cond.IsWhollyKnown() {
+			log.Printf("[DEBUG] The condition of %s for %s.%s is unknown", rule.Name(), resource.Labels[0], resource.Labels[1])
+			continue
+		}
+		if cond.IsNull() {
+			log.Printf("[DEBUG] The condition of %s for %s.%s is null", rule.Name(), resource.Labels[0], resource.Labels[1])
+			continue
+		}
+		if cond.True() {
+			continue
+		}
+
+		msg := fmt.Sprintf("%s.%s violates %s", resource.Labels[0], resource.Labels[1], rule.Name())
+		message, diags := r.Ctx.EvaluateExprWithSelf(rule.config.Message, self, cty.String)
+		if diags.HasErrors() {
+			log.Printf("[DEBUG] Failed to evaluate the message of %s for %s.%s. The default message is used instead; %s", rule.Name(), resource.Labels[0], resource.Labels[1], diags)
+		} else {
+			message, _ = message.UnmarkDeep()
+			if message.IsWhollyKnown() && !message.IsNull() {
+				msg = message.AsString()
+			}
+		}
+
+		// The issue is reported at the first attribute referenced in the rule if it exists.
+		// Otherwise, it is reported at the resource block.
+		var expr hcl.Expression
+		location := resource.DefRange
+		for _, name := range attrNames {
+			if attr, exists := resource.Body.Attributes[name]; exists {
+				expr = attr.Expr
+				location = attr.Expr.Range()
+				break
+			}
+		}
+
+		if expr == nil {
+			r.EmitIssue(rule, msg, location, false)
+			continue
+		}
+		if err := r.WithExpressionContext(expr, func() error {
+			r.EmitIssue(rule, msg, location, false)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// selfAttributeNames returns attribute names referenced as "self.<name>" in the expressions.
+// The names are returned in the order in which they appear.
+func selfAttributeNames(exprs ...hcl.Expression) []string {
+	names := []string{}
+	for _, expr := range exprs {
+		if expr == nil {
+			continue
+		}
+		for _, traversal := range expr.Variables() {
+			if traversal.RootName() != "self" || len(traversal) < 2 {
+				continue
+			}
+			attr, ok := traversal[1].(hcl.TraverseAttr)
+			if !ok {
+				continue
+			}
+			if !slices.Contains(names, attr.Name) {
+				names = append(names, attr.Name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,315 @@
+package tflint
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/spf13/afero"
+)
+
+func TestRunCustomRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		files  map[string]string
+		want   []string
+		ranges []hcl.Range
+	}{
+		{
+			name: "violation",
+			config: `
+custom_rule "aws_s3_bucket_owner_tag" {
+	resource_type = "aws_s3_bucket"
+	condition     = can(self.tags.owner)
+	message       = "${self.bucket} must have an owner tag"
+	severity      = "error"
+}`,
+			files: map[string]string{
+				"main.tf": `
+resource "aws_s3_bucket" "valid" {
+  bucket = "valid"
+  tags = {
+    owner = "platform"
+  }
+}
+
+resource "aws_s3_bucket" "invalid" {
+  bucket = "invalid"
+  tags = {
+    team = "platform"
+  }
+}`,
+			},
+			want: []string{"invalid must have an owner tag"},
+			ranges: []hcl.Range{
+				{
+					Filename: "main.tf",
+					Start:    hcl.Pos{Line: 11, Column: 10, Byte: 163},
+					End:      hcl.Pos{Line: 13, Column: 4, Byte: 190},
+				},
+			},
+		},
+		{
+			name: "missing attribute",
+			config: `
+custom_rule "aws_s3_bucket_owner_tag" {
+	resource_type = "aws_s3_bucket"
+	condition     = self.tags != null
+	message       = "tags must be set"
+}`,
+			files: map[string]string{
+				"main.tf": `
+resource "aws_s3_bucket" "invalid" {
+  bucket = "invalid"
+}`,
+			},
+			want: []string{"tags must be set"},
+			ranges: []hcl.Range{
+				{
+					Filename: "main.tf",
+					Start:    hcl.Pos{Line: 2, Column: 1, Byte: 1},
+					End:      hcl.Pos{Line: 2, Column: 35, Byte: 35},
+				},
+			},
+		},
+		{
+			name: "variables and count",
+			config: `
+custom_rule "aws_instance_type" {
+	resource_type = "aws_instance"
+	condition     = self.instance_type != "t1.micro"
+	message       = "t1.micro is not allowed"
+}`,
+			files: map[string]string{
+				"main.tf": `
+variable "type" {
+  default = "t1.micro"
+}
+
+resource "aws_instance" "main" {
+  count         = 2
+  instance_type = var.type
+}`,
+			},
+			want: []string{"t1.micro is not allowed", "t1.micro is not allowed"},
+		},
+		{
+			name: "unknown value",
+			config: `
+custom_rule "aws_instance_type" {
+	resource_type = "aws_instance"
+	condition     = self.instance_type != "t1.micro"
+	message       = "t1.micro is not allowed"
+}`,
+			files: map[string]string{
+				"main.tf": `
+variable "type" {}
+
+resource "aws_instance" "main" {
+  instance_type = var.type
+}`,
+			},
+			want: []string{},
+		},
+		{
+			name: "evaluation error",
+			config: `
+custom_rule "aws_instance_type" {
+	resource_type = "aws_instance"
+	condition     = self.instance_type != "t1.micro"
+	message       = "t1.micro is not allowed"
+}`,
+			files: map[string]string{
+				"main.tf": `
+resource "aws_instance" "main" {
+  instance_type = file("missing.txt")
+}
+
+resource "aws_instance" "invalid" {
+  instance_type = "t1.micro"
+}`,
+			},
+			want: []string{"t1.micro is not allowed"},
+		},
+		{
+			name: "message evaluation error",
+			config: `
+custom_rule "aws_s3_bucket_owner_tag" {
+	resource_type = "aws_s3_bucket"
+	condition     = can(self.tags.owner)
+	message       = "${self.bucket} must have an owner tag"
+	severity      = "error"
+}`,
+			files: map[string]string{
+				"main.tf": `
+resource "aws_s3_bucket" "invalid" {
+  tags = {
+    team = "platform"
+  }
+}`,
+			},
+			want: []string{"aws_s3_bucket.invalid violates aws_s3_bucket_owner_tag"},
+		},
+		{
+			name: "null condition",
+			config: `
+custom_rule "aws_instance_type" {
+	resource_type = "aws_instance"
+	condition     = self.instance_type == null ? null : self.instance_type != "t1.micro"
+	message       = "t1.micro is not allowed"
+}`,
+			files: map[string]string{
+				"main.tf": `
+resource "aws_instance" "main" {}
+
+resource "aws_instance" "invalid" {
+  instance_type = "t1.micro"
+}`,
+			},
+			want: []string{"t1.micro is not allowed"},
+		},
+		{
+			name: "condition evaluation error",
+			config: `
+custom_rule "aws_instance_type" {
+	resource_type = "aws_instance"
+	condition     = self.instance_type != "t1.micro" && self.tags.owner != ""
+	message       = "t1.micro is not allowed"
+}`,
+			files: map[string]string{
+				"main.tf": `
+resource "aws_instance" "main" {
+  instance_type = "t2.micro"
+}
+
+resource "aws_instance" "invalid" {
+  instance_type = "t1.micro"
+}`,
+			},
+			want: []string{"t1.micro is not allowed"},
+		},
+		{
+			name: "disabled",
+			config: `
+custom_rule "aws_s3_bucket_owner_tag" {
+	enabled       = false
+	resource_type = "aws_s3_bucket"
+	condition     = false
+	message       = "always fail"
+}`,
+			files: map[string]string{
+				"main.tf": `
+resource "aws_s3_bucket" "main" {}`,
+			},
+			want: []string{},
+		},
+		{
+			name: "disabled by rule block",
+			config: `
+custom_rule "aws_s3_bucket_owner_tag" {
+	resource_type = "aws_s3_bucket"
+	condition     = false
+	message       = "always fail"
+}
+
+rule "aws_s3_bucket_owner_tag" {
+	enabled = false
+}`,
+			files: map[string]string{
+				"main.tf": `
+resource "aws_s3_bucket" "main" {}`,
+			},
+			want: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := afero.Afero{Fs: afero.NewMemMapFs()}
+			if err := fs.WriteFile(".tflint.hcl", []byte(test.config), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+			config, err := LoadConfig(fs, ".tflint.hcl")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			runner := TestRunnerWithConfig(t, test.files, config)
+			if err := runner.RunCustomRules(); err != nil {
+				t.Fatal(err)
+			}
+
+			got := []string{}
+			for _, issue := range runner.Issues {
+				got = append(got, issue.Message)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+
+			if test.ranges != nil {
+				gotRanges := []hcl.Range{}
+				for _, issue := range runner.Issues {
+					gotRanges = append(gotRanges, issue.Range)
+				}
+				if diff := cmp.Diff(test.ranges, gotRanges); diff != "" {
+					t.Fatal(diff)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfig_customRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name: "invalid severity",
+			config: `
+custom_rule "foo" {
+	resource_type = "aws_s3_bucket"
+	condition     = true
+	message       = "foo"
+	severity      = "critical"
+}`,
+			wantErr: `custom_rule "foo": critical is not a recognized severity`,
+		},
+		{
+			name: "duplicated",
+			config: `
+custom_rule "foo" {
+	resource_type = "aws_s3_bucket"
+	condition     = true
+	message       = "foo"
+}
+
+custom_rule "foo" {
+	resource_type = "aws_instance"
+	condition     = true
+	message       = "foo"
+}`,
+			wantErr: `custom_rule "foo" is duplicated`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := afero.Afero{Fs: afero.NewMemMapFs()}
+			if err := fs.WriteFile(".tflint.hcl", []byte(test.config), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadConfig(fs, ".tflint.hcl")
+			if err == nil {
+				t.Fatal("an error is expected, but got nil")
+			}
+			if err.Error() != test.wantErr {
+				t.Fatalf("want %q, got %q", test.wantErr, err.Error())
+			}
+		})
+	}
+}
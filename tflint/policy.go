@@ -0,0 +1,650 @@
+package tflint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	hcl "github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	sdk "github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/terraform-linters/tflint/terraform"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// policyPackage is the Rego package in which policy rules are declared.
+const policyPackage = "tflint"
+
+// policyRulePrefixes maps rule name prefixes to severities.
+// Rules without these prefixes are treated as helpers and are not run as rules.
+var policyRulePrefixes = map[string]Severity{
+	"deny_":   sdk.ERROR,
+	"warn_":   sdk.WARNING,
+	"notice_": sdk.NOTICE,
+}
+
+// Meta-arguments are not passed to policies. These are not part of the resource
+// configuration, and some of them cannot be evaluated as values.
+var (
+	policyResourceMetaAttributes = []string{"count", "for_each", "depends_on", "provider"}
+	policyResourceMetaBlocks     = []string{"lifecycle", "provisioner", "connection"}
+	policyModuleMetaAttributes   = []string{"count", "for_each", "depends_on", "providers"}
+)
+
+// Policies is a built-in ruleset that evaluates Rego policies in the policy directory.
+// Each rule in the "tflint" package whose name starts with "deny_", "warn_", or "notice_"
+// is treated as a rule that returns a set of issues.
+type Policies struct {
+	rules []*policyRule
+}
+
+var _ RuleSet = (*Policies)(nil)
+
+// policyRule is an implementation of Rule for Rego policies.
+type policyRule struct {
+	name     string
+	severity Severity
+	query    rego.PreparedEvalQuery
+}
+
+var _ Rule = (*policyRule)(nil)
+
+func (r *policyRule) Name() string {
+	return r.name
+}
+
+func (r *policyRule) Severity() Severity {
+	return r.severity
+}
+
+func (r *policyRule) Link() string {
+	return ""
+}
+
+// LoadPolicies loads and compiles all .rego files in the passed directory.
+// If the directory is empty, it returns nil.
+func LoadPolicies(dir string) (*Policies, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	log.Printf("[INFO] Load policies from %s", dir)
+
+	modules := map[string]*ast.Module{}
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".rego" || strings.HasSuffix(path, "_test.rego") {
+			return nil
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		module, err := ast.ParseModule(path, string(src))
+		if err != nil {
+			return err
+		}
+		modules[path] = module
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load policies; %w", err)
+	}
+
+	compiler := ast.NewCompiler()
+	compiler.Compile(modules)
+	if compiler.Failed() {
+		return nil, fmt.Errorf("Failed to compile policies; %w", compiler.Errors)
+	}
+
+	names := map[string]Severity{}
+	for _, module := range modules {
+		if module.Package.Path.String() != ast.DefaultRootDocument.String()+"."+policyPackage {
+			continue
+		}
+		for _, rule := range module.Rules {
+			name := rule.Head.Name.String()
+			if name == "" {
+				name = rule.Head.Ref().String()
+			}
+			for prefix, severity := range policyRulePrefixes {
+				if strings.HasPrefix(name, prefix) {
+					names[name] = severity
+				}
+			}
+		}
+	}
+
+	policies := &Policies{}
+	for name, severity := range names {
+		query, err := rego.New(
+			rego.Query(fmt.Sprintf("data.%s.%s", policyPackage, name)),
+			rego.Compiler(compiler),
+		).PrepareForEval(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf(`Failed to prepare policy "%s"; %w`, name, err)
+		}
+		policies.rules = append(policies.rules, &policyRule{name: name, severity: severity, query: query})
+	}
+	sort.Slice(policies.rules, func(i, j int) bool {
+		return policies.rules[i].name < policies.rules[j].name
+	})
+	log.Printf("[DEBUG] Policy rules: %d", len(policies.rules))
+
+	return policies, nil
+}
+
+// RuleSetName returns the name used in errors such as duplicate rule names.
+func (p *Policies) RuleSetName() (string, error) {
+	return "policies", nil
+}
+
+// RuleSetVersion returns the TFLint version because policies are built-in.
+func (p *Policies) RuleSetVersion() (string, error) {
+	return Version.String(), nil
+}
+
+// Rules returns all rules in the policies.
+func (p *Policies) Rules() []Rule {
+	ret := make([]Rule, len(p.rules))
+	for i, rule := range p.rules {
+		ret[i] = rule
+	}
+	return ret
+}
+
+// RuleNames returns the names of all rules in the policies.
+func (p *Policies) RuleNames() ([]string, error) {
+	names := make([]string, len(p.rules))
+	for i, rule := range p.rules {
+		names[i] = rule.name
+	}
+	return names, nil
+}
+
+// RunPolicies evaluates policies against the module and emits violations as issues.
+func (r *Runner) RunPolicies(policies *Policies) error {
+	if policies == nil {
+		return nil
+	}
+
+	rules := []*policyRule{}
+	for _, rule := range policies.rules {
+		if !r.config.isBuiltinRuleEnabled(rule.name, true) {
+			log.Printf("[DEBUG] Policy rule %s is disabled", rule.name)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	builder := &policyInputBuilder{runner: r, exprs: map[hcl.Range]hcl.Expression{}}
+	input, err := builder.build()
+	if err != nil {
+		return fmt.Errorf("Failed to build policy input; %w", err)
+	}
+
+	for _, rule := range rules {
+		rs, err := rule.query.Eval(context.Background(), rego.EvalInput(input))
+		if err != nil {
+			return fmt.Errorf(`Failed to evaluate policy "%s"; %w`, rule.name, err)
+		}
+		// If the rule is undefined, there are no violations
+		if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+			continue
+		}
+
+		issues, err := decodePolicyIssues(rs[0].Expressions[0].Value)
+		if err != nil {
+			return fmt.Errorf(`Failed to decode the result of policy "%s"; %w`, rule.name, err)
+		}
+		for _, issue := range issues {
+			location := issue.Range.hclRange()
+			expr, exists := builder.exprs[location]
+			if !exists {
+				r.EmitIssue(rule, issue.Msg, location, false)
+				continue
+			}
+			if err := r.WithExpressionContext(expr, func() error {
+				r.EmitIssue(rule, issue.Msg, location, false)
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// policyIssue is an issue returned by policies.
+type policyIssue struct {
+	Msg   string      `json:"msg"`
+	Range policyRange `json:"range"`
+}
+
+// policyRange is a JSON representation of hcl.Range.
+type policyRange struct {
+	Filename string    `json:"filename"`
+	Start    policyPos `json:"start"`
+	End      policyPos `json:"end"`
+}
+
+type policyPos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Byte   int `json:"byte"`
+}
+
+func newPolicyRange(rng hcl.Range) policyRange {
+	return policyRange{
+		Filename: rng.Filename,
+		Start:    policyPos{Line: rng.Start.Line, Column: rng.Start.Column, Byte: rng.Start.Byte},
+		End:      policyPos{Line: rng.End.Line, Column: rng.End.Column, Byte: rng.End.Byte},
+	}
+}
+
+func (r policyRange) hclRange() hcl.Range {
+	return hcl.Range{
+		Filename: r.Filename,
+		Start:    hcl.Pos{Line: r.Start.Line, Column: r.Start.Column, Byte: r.Start.Byte},
+		End:      hcl.Pos{Line: r.End.Line, Column: r.End.Column, Byte: r.End.Byte},
+	}
+}
+
+// decodePolicyIssues decodes the value of a policy rule into issues.
+// The value must be a set of objects with "msg" and "range".
+func decodePolicyIssues(value any) ([]policyIssue, error) {
+	set, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("the rule must be a set of issues, but got %T", value)
+	}
+
+	issues := make([]policyIssue, len(set))
+	for i, v := range set {
+		if _, ok := v.(map[string]any); !ok {
+			return nil, fmt.Errorf(`an issue must be an object with "msg" and "range", but got %T`, v)
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, &issues[i]); err != nil {
+			return nil, err
+		}
+		if issues[i].Range.Filename == "" {
+			return nil, fmt.Errorf(`an issue must have "range", but got %s`, b)
+		}
+	}
+	return issues, nil
+}
+
+// policyInputBuilder builds the input document of policies from the module.
+// It also records the expressions of attributes so that issues in called
+// modules can be mapped to module arguments.
+type policyInputBuilder struct {
+	runner *Runner
+	exprs  map[hcl.Range]hcl.Expression
+}
+
+func (b *policyInputBuilder) build() (map[string]any, error) {
+	resources, err := b.resources()
+	if err != nil {
+		return nil, err
+	}
+	variables, err := b.variables()
+	if err != nil {
+		return nil, err
+	}
+	locals, err := b.locals()
+	if err != nil {
+		return nil, err
+	}
+	moduleCalls, err := b.moduleCalls()
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"resources":    resources,
+		"variables":    variables,
+		"locals":       locals,
+		"module_calls": moduleCalls,
+	}, nil
+}
+
+func (b *policyInputBuilder) resources() ([]any, error) {
+	schema := b.inferSchema("resource", []string{"type", "name"}, policyResourceMetaAttributes, policyResourceMetaBlocks)
+	content, diags := b.runner.TFConfig.Module.PartialContent(&hclext.BodySchema{
+		Blocks: []hclext.BlockSchema{{Type: "resource", LabelNames: []string{"type", "name"}, Body: schema}},
+	}, b.runner.Ctx)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	ret := []any{}
+	for _, block := range sortBlocks(content.Blocks) {
+		config, err := b.body(block.Body)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, map[string]any{
+			"type":       block.Labels[0],
+			"name":       block.Labels[1],
+			"config":     config,
+			"decl_range": newPolicyRange(block.DefRange),
+		})
+	}
+	return ret, nil
+}
+
+func (b *policyInputBuilder) variables() ([]any, error) {
+	content, diags := b.runner.TFConfig.Module.PartialContent(&hclext.BodySchema{
+		Blocks: []hclext.BlockSchema{
+			{
+				Type:       "variable",
+				LabelNames: []string{"name"},
+				Body: &hclext.BodySchema{
+					Attributes: []hclext.AttributeSchema{
+						{Name: "default"},
+						{Name: "description"},
+						{Name: "sensitive"},
+						{Name: "nullable"},
+						{Name: "ephemeral"},
+					},
+				},
+			},
+		},
+	}, b.runner.Ctx)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	ret := []any{}
+	for _, block := range sortBlocks(content.Blocks) {
+		config, err := b.body(block.Body)
+		if err != nil {
+			return nil, err
+		}
+		// The value is the result of evaluating the variable, including values
+		// passed from tfvars files and module arguments.
+		ref := &hclsyntax.ScopeTraversalExpr{
+			Traversal: hcl.Traversal{
+				hcl.TraverseRoot{Name: "var", SrcRange: block.DefRange},
+				hcl.TraverseAttr{Name: block.Labels[0], SrcRange: block.DefRange},
+			},
+			SrcRange: block.DefRange,
+		}
+		value, err := b.attribute(ref)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, map[string]any{
+			"name":       block.Labels[0],
+			"config":     config,
+			"value":      value,
+			"decl_range": newPolicyRange(block.DefRange),
+		})
+	}
+	return ret, nil
+}
+
+func (b *policyInputBuilder) locals() ([]any, error) {
+	locals := make([]*terraform.Local, 0, len(b.runner.TFConfig.Module.Locals))
+	for _, local := range b.runner.TFConfig.Module.Locals {
+		locals = append(locals, local)
+	}
+	sort.Slice(locals, func(i, j int) bool {
+		return lessRange(locals[i].DeclRange, locals[j].DeclRange)
+	})
+
+	ret := []any{}
+	for _, local := range locals {
+		value, err := b.attribute(local.Expr)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, map[string]any{
+			"name":       local.Name,
+			"value":      value,
+			"decl_range": newPolicyRange(local.DeclRange),
+		})
+	}
+	return ret, nil
+}
+
+func (b *policyInputBuilder) moduleCalls() ([]any, error) {
+	schema := b.inferSchema("module", []string{"name"}, policyModuleMetaAttributes, []string{})
+	content, diags := b.runner.TFConfig.Module.PartialContent(&hclext.BodySchema{
+		Blocks: []hclext.BlockSchema{{Type: "module", LabelNames: []string{"name"}, Body: schema}},
+	}, b.runner.Ctx)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	ret := []any{}
+	for _, block := range sortBlocks(content.Blocks) {
+		config, err := b.body(block.Body)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, map[string]any{
+			"name":       block.Labels[0],
+			"config":     config,
+			"decl_range": newPolicyRange(block.DefRange),
+		})
+	}
+	return ret, nil
+}
+
+// body converts the body content into an object. Attributes are converted
+// by attribute, and nested blocks are converted to a list of objects keyed
+// by the block type.
+func (b *policyInputBuilder) body(body *hclext.BodyContent) (map[string]any, error) {
+	ret := map[string]any{}
+	for name, attr := range body.Attributes {
+		value, err := b.attribute(attr.Expr)
+		if err != nil {
+			return nil, err
+		}
+		ret[name] = value
+	}
+	for _, block := range body.Blocks {
+		config, err := b.body(block.Body)
+		if err != nil {
+			return nil, err
+		}
+		blocks, _ := ret[block.Type].([]any)
+		ret[block.Type] = append(blocks, map[string]any{
+			"labels":     block.Labels,
+			"config":     config,
+			"decl_range": newPolicyRange(block.DefRange),
+		})
+	}
+	return ret, nil
+}
+
+// attribute evaluates the expression and converts it into an object
+// with "value", "unknown", "sensitive", and "range". If the value is
+// unknown or sensitive, the value is omitted. Expressions that fail to
+// evaluate are treated as unknown so that they don't abort the inspection.
+func (b *policyInputBuilder) attribute(expr hcl.Expression) (map[string]any, error) {
+	val, diags := b.runner.Ctx.EvaluateExpr(expr, cty.DynamicPseudoType)
+	if diags.HasErrors() {
+		log.Printf("[WARN] Failed to evaluate the expression at %s for policies. It is treated as unknown; %s", expr.Range(), diags)
+		val = cty.DynamicVal
+	}
+	b.exprs[expr.Range()] = expr
+
+	ret := map[string]any{
+		"unknown":   false,
+		"sensitive": false,
+		"range":     newPolicyRange(expr.Range()),
+	}
+	if val.ContainsMarked() {
+		ret["sensitive"] = true
+		return ret, nil
+	}
+	if !val.IsWhollyKnown() {
+		ret["unknown"] = true
+		return ret, nil
+	}
+
+	src, err := ctyjson.Marshal(val, val.Type())
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(src))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	ret["value"] = value
+
+	return ret, nil
+}
+
+// inferSchema builds a schema of the block bodies of the given type from the syntax
+// in the module files. Since policies are not aware of provider schemas, all attributes
+// and nested blocks written in the files are extracted.
+func (b *policyInputBuilder) inferSchema(blockType string, labelNames []string, excludeAttrs []string, excludeBlocks []string) *hclext.BodySchema {
+	schema := &hclext.BodySchema{}
+
+	for _, file := range b.runner.TFConfig.Module.Files {
+		content, _, _ := file.Body.PartialContent(&hcl.BodySchema{
+			Blocks: []hcl.BlockHeaderSchema{{Type: blockType, LabelNames: labelNames}},
+		})
+		if content == nil {
+			continue
+		}
+		for _, block := range content.Blocks {
+			mergeInferredSchema(schema, block.Body)
+		}
+	}
+
+	attrs := []hclext.AttributeSchema{}
+	for _, attr := range schema.Attributes {
+		if !slices.Contains(excludeAttrs, attr.Name) {
+			attrs = append(attrs, attr)
+		}
+	}
+	schema.Attributes = attrs
+	blocks := []hclext.BlockSchema{}
+	for _, block := range schema.Blocks {
+		if !slices.Contains(excludeBlocks, block.Type) {
+			blocks = append(blocks, block)
+		}
+	}
+	schema.Blocks = blocks
+
+	return schema
+}
+
+// mergeInferredSchema adds attributes and blocks in the body to the schema.
+// For native syntax, nested blocks (including dynamic blocks) are extracted recursively.
+// For JSON syntax, it is not possible to distinguish between attributes and blocks,
+// so all properties are treated as attributes unless they are known as blocks.
+func mergeInferredSchema(schema *hclext.BodySchema, body hcl.Body) {
+	if body == nil {
+		return
+	}
+
+	switch body := body.(type) {
+	case *hclsyntax.Body:
+		for name := range body.Attributes {
+			addInferredAttribute(schema, name)
+		}
+		for _, block := range body.Blocks {
+			blockType := block.Type
+			labelNames := make([]string, len(block.Labels))
+			var blockBody hcl.Body = block.Body
+
+			if block.Type == "dynamic" && len(block.Labels) == 1 {
+				blockType = block.Labels[0]
+				labelNames = []string{}
+				blockBody = nil
+				for _, child := range block.Body.Blocks {
+					if child.Type == "content" {
+						blockBody = child.Body
+					}
+				}
+			}
+			for i := range labelNames {
+				labelNames[i] = fmt.Sprintf("label%d", i)
+			}
+
+			mergeInferredSchema(addInferredBlock(schema, blockType, labelNames), blockBody)
+		}
+	default:
+		attrs, _ := body.JustAttributes()
+		for name := range attrs {
+			addInferredAttribute(schema, name)
+		}
+	}
+}
+
+func addInferredAttribute(schema *hclext.BodySchema, name string) {
+	for _, attr := range schema.Attributes {
+		if attr.Name == name {
+			return
+		}
+	}
+	// If the name is known as a block, treat it as a block
+	for _, block := range schema.Blocks {
+		if block.Type == name {
+			return
+		}
+	}
+	schema.Attributes = append(schema.Attributes, hclext.AttributeSchema{Name: name})
+}
+
+func addInferredBlock(schema *hclext.BodySchema, blockType string, labelNames []string) *hclext.BodySchema {
+	for _, block := range schema.Blocks {
+		if block.Type == blockType {
+			return block.Body
+		}
+	}
+	// If the name is known as an attribute, treat it as a block
+	attrs := []hclext.AttributeSchema{}
+	for _, attr := range schema.Attributes {
+		if attr.Name != blockType {
+			attrs = append(attrs, attr)
+		}
+	}
+	schema.Attributes = attrs
+
+	body := &hclext.BodySchema{}
+	schema.Blocks = append(schema.Blocks, hclext.BlockSchema{Type: blockType, LabelNames: labelNames, Body: body})
+	return body
+}
+
+// sortBlocks sorts blocks by their positions so that the input is deterministic.
+func sortBlocks(blocks hclext.Blocks) hclext.Blocks {
+	sort.SliceStable(blocks, func(i, j int) bool {
+		return lessRange(blocks[i].DefRange, blocks[j].DefRange)
+	})
+	return blocks
+}
+
+func lessRange(a, b hcl.Range) bool {
+	if a.Filename != b.Filename {
+		return a.Filename < b.Filename
+	}
+	return a.Start.Byte < b.Start.Byte
+}
@@ -0,0 +1,428 @@
+package tflint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	hcl "github.com/hashicorp/hcl/v2"
+	sdk "github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+func TestLoadPolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies map[string]string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name: "rules",
+			policies: map[string]string{
+				"main.rego": `
+package tflint
+
+deny_instance_type contains issue if {
+	false
+	issue := {}
+}
+
+warn_tags contains issue if {
+	false
+	issue := {}
+}
+
+helper := true
+`,
+				"nested/notice.rego": `
+package tflint
+
+notice_names contains issue if {
+	false
+	issue := {}
+}
+`,
+				"other.rego": `
+package other
+
+deny_other contains issue if {
+	false
+	issue := {}
+}
+`,
+				"main_test.rego": `
+package tflint
+
+test_deny if {
+	true
+}
+`,
+			},
+			want: []string{"deny_instance_type", "notice_names", "warn_tags"},
+		},
+		{
+			name: "syntax error",
+			policies: map[string]string{
+				"main.rego": `package tflint
+
+deny_foo contains`,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, src := range test.policies {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			policies, err := LoadPolicies(dir)
+			if err != nil {
+				if !test.wantErr {
+					t.Fatal(err)
+				}
+				return
+			}
+			if test.wantErr {
+				t.Fatal("an error is expected, but got nil")
+			}
+
+			got, err := policies.RuleNames()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestLoadPolicies_empty(t *testing.T) {
+	policies, err := LoadPolicies("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policies != nil {
+		t.Fatalf("policies should be nil, but got %#v", policies)
+	}
+}
+
+func TestRunPolicies(t *testing.T) {
+	type issue struct {
+		Rule     string
+		Severity Severity
+		Message  string
+		Range    hcl.Range
+	}
+
+	tests := []struct {
+		name    string
+		policy  string
+		files   map[string]string
+		config  *Config
+		want    []issue
+		wantErr bool
+	}{
+		{
+			name: "resource attribute",
+			policy: `
+package tflint
+
+deny_instance_type contains issue if {
+	some r in input.resources
+	r.type == "aws_instance"
+	r.config.instance_type.value == "t1.micro"
+	issue := {"msg": sprintf("%s uses t1.micro", [r.name]), "range": r.config.instance_type.range}
+}
+`,
+			files: map[string]string{
+				"main.tf": `
+variable "type" {
+  default = "t1.micro"
+}
+
+resource "aws_instance" "main" {
+  instance_type = var.type
+}
+
+resource "aws_instance" "valid" {
+  instance_type = "t2.micro"
+}`,
+			},
+			want: []issue{
+				{
+					Rule:     "deny_instance_type",
+					Severity: sdk.ERROR,
+					Message:  "main uses t1.micro",
+					Range: hcl.Range{
+						Filename: "main.tf",
+						Start:    hcl.Pos{Line: 7, Column: 19, Byte: 96},
+						End:      hcl.Pos{Line: 7, Column: 27, Byte: 104},
+					},
+				},
+			},
+		},
+		{
+			name: "missing nested block",
+			policy: `
+package tflint
+
+warn_missing_ebs contains issue if {
+	some r in input.resources
+	r.type == "aws_instance"
+	not r.config.ebs_block_device
+	issue := {"msg": "ebs_block_device is required", "range": r.decl_range}
+}
+`,
+			files: map[string]string{
+				"main.tf": `
+resource "aws_instance" "main" {
+  ebs_block_device {
+    volume_size = 10
+  }
+}
+
+resource "aws_instance" "invalid" {
+  dynamic "root_block_device" {
+    for_each = []
+    content {
+      volume_size = 10
+    }
+  }
+}`,
+			},
+			want: []issue{
+				{
+					Rule:     "warn_missing_ebs",
+					Severity: sdk.WARNING,
+					Message:  "ebs_block_device is required",
+					Range: hcl.Range{
+						Filename: "main.tf",
+						Start:    hcl.Pos{Line: 8, Column: 1, Byte: 83},
+						End:      hcl.Pos{Line: 8, Column: 34, Byte: 116},
+					},
+				},
+			},
+		},
+		{
+			name: "unknown value",
+			policy: `
+package tflint
+
+deny_instance_type contains issue if {
+	some r in input.resources
+	r.config.instance_type.unknown
+	issue := {"msg": "unknown", "range": r.config.instance_type.range}
+}
+`,
+			files: map[string]string{
+				"main.tf": `
+variable "type" {}
+
+resource "aws_instance" "main" {
+  instance_type = var.type
+}`,
+			},
+			want: []issue{
+				{
+					Rule:     "deny_instance_type",
+					Severity: sdk.ERROR,
+					Message:  "unknown",
+					Range: hcl.Range{
+						Filename: "main.tf",
+						Start:    hcl.Pos{Line: 5, Column: 19, Byte: 72},
+						End:      hcl.Pos{Line: 5, Column: 27, Byte: 80},
+					},
+				},
+			},
+		},
+		{
+			name: "variables, locals, and module calls",
+			policy: `
+package tflint
+
+notice_variables contains issue if {
+	some v in input.variables
+	not v.config.description
+	issue := {"msg": sprintf("variable %s (%v)", [v.name, v.value.value]), "range": v.decl_range}
+}
+
+notice_locals contains issue if {
+	some l in input.locals
+	issue := {"msg": sprintf("local %s (%v)", [l.name, l.value.value]), "range": l.value.range}
+}
+
+notice_module_calls contains issue if {
+	some m in input.module_calls
+	issue := {"msg": sprintf("module %s (%s)", [m.name, m.config.source.value]), "range": m.config.source.range}
+}
+`,
+			files: map[string]string{
+				"main.tf": `
+variable "foo" {
+  default = "bar"
+}
+
+locals {
+  baz = 1
+}
+
+module "qux" {
+  source = "terraform-aws-modules/vpc/aws"
+}`,
+			},
+			want: []issue{
+				{
+					Rule:     "notice_locals",
+					Severity: sdk.NOTICE,
+					Message:  "local baz (1)",
+					Range: hcl.Range{
+						Filename: "main.tf",
+						Start:    hcl.Pos{Line: 7, Column: 9, Byte: 56},
+						End:      hcl.Pos{Line: 7, Column: 10, Byte: 57},
+					},
+				},
+				{
+					Rule:     "notice_module_calls",
+					Severity: sdk.NOTICE,
+					Message:  "module qux (terraform-aws-modules/vpc/aws)",
+					Range: hcl.Range{
+						Filename: "main.tf",
+						Start:    hcl.Pos{Line: 11, Column: 12, Byte: 87},
+						End:      hcl.Pos{Line: 11, Column: 43, Byte: 118},
+					},
+				},
+				{
+					Rule:     "notice_variables",
+					Severity: sdk.NOTICE,
+					Message:  "variable foo (bar)",
+					Range: hcl.Range{
+						Filename: "main.tf",
+						Start:    hcl.Pos{Line: 2, Column: 1, Byte: 1},
+						End:      hcl.Pos{Line: 2, Column: 15, Byte: 15},
+					},
+				},
+			},
+		},
+		{
+			name: "disabled by rule block",
+			policy: `
+package tflint
+
+deny_all contains issue if {
+	some r in input.resources
+	issue := {"msg": "denied", "range": r.decl_range}
+}
+`,
+			files: map[string]string{
+				"main.tf": `
+resource "aws_instance" "main" {}`,
+			},
+			config: &Config{
+				Rules: map[string]*RuleConfig{
+					"deny_all": {Name: "deny_all", Enabled: false},
+				},
+			},
+			want: []issue{},
+		},
+		{
+			name: "evaluation error",
+			policy: `
+package tflint
+
+deny_unknown contains issue if {
+	some r in input.resources
+	r.config.instance_type.unknown
+	issue := {"msg": "unknown", "range": r.config.instance_type.range}
+}
+`,
+			files: map[string]string{
+				"main.tf": `
+resource "aws_instance" "main" {
+  instance_type = file("missing.txt")
+}`,
+			},
+			want: []issue{
+				{
+					Rule:     "deny_unknown",
+					Severity: sdk.ERROR,
+					Message:  "unknown",
+					Range: hcl.Range{
+						Filename: "main.tf",
+						Start:    hcl.Pos{Line: 3, Column: 19, Byte: 52},
+						End:      hcl.Pos{Line: 3, Column: 38, Byte: 71},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid issue",
+			policy: `
+package tflint
+
+deny_all contains issue if {
+	some r in input.resources
+	issue := "denied"
+}
+`,
+			files: map[string]string{
+				"main.tf": `
+resource "aws_instance" "main" {}`,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "main.rego"), []byte(test.policy), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			policies, err := LoadPolicies(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config := test.config
+			if config == nil {
+				config = EmptyConfig()
+			}
+			runner := TestRunnerWithConfig(t, test.files, config)
+
+			err = runner.RunPolicies(policies)
+			if err != nil {
+				if !test.wantErr {
+					t.Fatal(err)
+				}
+				return
+			}
+			if test.wantErr {
+				t.Fatal("an error is expected, but got nil")
+			}
+
+			got := []issue{}
+			for _, i := range runner.Issues {
+				got = append(got, issue{
+					Rule:     i.Rule.Name(),
+					Severity: i.Rule.Severity(),
+					Message:  i.Message,
+					Range:    i.Range,
+				})
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
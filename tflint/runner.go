@@ -27,6 +27,8 @@ type Runner struct {
 	currentExpr hcl.Expression
 	modVars     map[string]*moduleVariable
 	changes     map[string][]byte
+	issueFilter func(*Issue) bool
+	rules       map[string]Rule
 }
 
 // Rule is interface for building the issue
@@ -68,6 +70,7 @@ func NewRunner(originalWorkingDir string, c *Config, ants map[string]Annotations
 		annotations: ants,
 		config:      c,
 		changes:     map[string][]byte{},
+		rules:       map[string]Rule{},
 	}
 
 	return runner, nil
@@ -158,6 +161,8 @@ func NewModuleRunners(parent *Runner) ([]*Runner, error) {
 			if err != nil {
 				return runners, err
 			}
+			// Module runners share the metadata such as the workspace with the root runner
+			runner.Ctx.Meta = parent.Ctx.Meta
 			runner.modVars = modVars
 			runners = append(runners, runner)
 			moduleRunners, err := NewModuleRunners(runner)
@@ -292,7 +297,28 @@ func (r *Runner) ClearChanges() {
 	r.changes = map[string][]byte{}
 }
 
+// LookupRules returns the rules that emitted issues, keyed by name.
+// Rules whose issues were ignored by the config, annotations, or the filter are also included.
+// Plugins send the metadata of rules such as severity only with issues, so this is
+// the only way to know it.
+func (r *Runner) LookupRules() map[string]Rule {
+	return r.rules
+}
+
+// SetIssueFilter sets a function to narrow down the issues to be emitted.
+// Issues for which the function returns false are ignored in the same way
+// as annotations, so autofixes are not applied to them either.
+func (r *Runner) SetIssueFilter(filter func(*Issue) bool) {
+	r.issueFilter = filter
+}
+
 func (r *Runner) emitIssue(issue *Issue) bool {
+	r.rules[issue.Rule.Name()] = issue.Rule
+
+	if !r.config.IsRuleEnabledFor(issue.Rule.Name(), issue.Range.Filename) {
+		log.Printf("[INFO] %s (%s) is ignored by the rule config for the path", issue.Range.String(), issue.Rule.Name())
+		return false
+	}
 	if annotations, ok := r.annotations[issue.Range.Filename]; ok {
 		for _, annotation := range annotations {
 			if annotation.IsAffected(issue) {
@@ -301,6 +327,10 @@ func (r *Runner) emitIssue(issue *Issue) bool {
 			}
 		}
 	}
+	if r.issueFilter != nil && !r.issueFilter(issue) {
+		log.Printf("[DEBUG] %s (%s) is ignored by the issue filter", issue.Range.String(), issue.Rule.Name())
+		return false
+	}
 	r.Issues = append(r.Issues, issue)
 	return true
 }
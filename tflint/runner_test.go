@@ -431,6 +431,25 @@ func TestLookupChanges(t *testing.T) {
 	}
 }
 
+func TestLookupRules(t *testing.T) {
+	runner := TestRunner(t, map[string]string{"test.tf": "foo = 1"})
+	runner.config = &Config{
+		Rules: map[string]*RuleConfig{
+			"test_rule": {Name: "test_rule", Enabled: false},
+		},
+	}
+
+	applied := runner.EmitIssue(&testRule{}, "This is test message", hcl.Range{Filename: "test.tf"}, false)
+	if applied {
+		t.Fatal("expected the issue to be ignored")
+	}
+
+	want := map[string]Rule{"test_rule": &testRule{}}
+	if diff := cmp.Diff(want, runner.LookupRules()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
 type testRule struct{}
 
 func (r *testRule) Name() string {
@@ -468,7 +487,9 @@ func Test_EmitIssue(t *testing.T) {
 		Message     string
 		Location    hcl.Range
 		Fixable     bool
+		Config      *Config
 		Annotations map[string]Annotations
+		Filter      func(*Issue) bool
 		Module      *moduleConfig
 		Expected    Issues
 		Applied     bool
@@ -544,6 +565,83 @@ func Test_EmitIssue(t *testing.T) {
 			Expected: Issues{},
 			Applied:  false,
 		},
+		{
+			Name:    "ignore by path",
+			Rule:    &testRule{},
+			Message: "This is test message",
+			Location: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1},
+			},
+			Config: &Config{
+				Rules: map[string]*RuleConfig{
+					"test_rule": {Name: "test_rule", Enabled: true, ExcludePaths: []string{"*.tf"}},
+				},
+			},
+			Annotations: map[string]Annotations{},
+			Expected:    Issues{},
+			Applied:     false,
+		},
+		{
+			Name:    "ignore by filter",
+			Rule:    &testRule{},
+			Message: "This is test message",
+			Location: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1},
+			},
+			Annotations: map[string]Annotations{},
+			Filter: func(issue *Issue) bool {
+				return issue.Range.Start.Line == 2
+			},
+			Expected: Issues{},
+			Applied:  false,
+		},
+		{
+			Name:    "enabled by default outside of override",
+			Rule:    &testRule{},
+			Message: "This is test message",
+			Location: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1},
+			},
+			Config: &Config{
+				Overrides: []*OverrideConfig{
+					{Pattern: "modules/**", Rules: []*OverrideRuleConfig{{Name: "test_rule", Enabled: true}}},
+				},
+			},
+			Annotations: map[string]Annotations{},
+			Expected: Issues{
+				{
+					Rule:    &testRule{},
+					Message: "This is test message",
+					Range: hcl.Range{
+						Filename: "test.tf",
+						Start:    hcl.Pos{Line: 1},
+					},
+					Source: []byte("foo = 1"),
+				},
+			},
+			Applied: true,
+		},
+		{
+			Name:    "disabled by default outside of override",
+			Rule:    &testRule{},
+			Message: "This is test message",
+			Location: hcl.Range{
+				Filename: "test.tf",
+				Start:    hcl.Pos{Line: 1},
+			},
+			Config: &Config{
+				DisabledByDefault: true,
+				Overrides: []*OverrideConfig{
+					{Pattern: "modules/**", Rules: []*OverrideRuleConfig{{Name: "test_rule", Enabled: true}}},
+				},
+			},
+			Annotations: map[string]Annotations{},
+			Expected:    Issues{},
+			Applied:     false,
+		},
 		{
 			Name:    "module",
 			Rule:    &testRule{},
@@ -719,6 +817,10 @@ func Test_EmitIssue(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.Name, func(t *testing.T) {
 			runner := testRunnerWithAnnotations(t, sources, tc.Annotations)
+			if tc.Config != nil {
+				runner.config = tc.Config
+			}
+			runner.SetIssueFilter(tc.Filter)
 			if tc.Module != nil {
 				runner.TFConfig.Path = []string{"module", "module1"}
 				runner.currentExpr = tc.Module.currentExpr